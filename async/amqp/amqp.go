@@ -5,12 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding"
 	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
 	"github.com/beatlabs/patron/trace"
 	"github.com/google/uuid"
@@ -18,6 +22,11 @@ import (
 	"github.com/streadway/amqp"
 )
 
+const (
+	amqpAttributeExchange    = "exchange"
+	amqpAttributeRedelivered = "redelivered"
+)
+
 var (
 	defaultCfg = amqp.Config{
 		Dial: func(network, addr string) (net.Conn, error) {
@@ -27,6 +36,7 @@ var (
 )
 
 type message struct {
+	queue   string
 	span    opentracing.Span
 	ctx     context.Context
 	del     *amqp.Delivery
@@ -54,6 +64,27 @@ func (m *message) Nack() error {
 	return err
 }
 
+// Metadata returns the queue, message ID, routing key, timestamp, headers along with the content type and body of the delivery.
+// The exchange and the redelivered flag are returned as attributes.
+func (m *message) Metadata() async.Metadata {
+	hh := mapHeader(m.del.Headers)
+	if m.del.ContentType != "" {
+		hh[encoding.ContentTypeHeader] = m.del.ContentType
+	}
+	return async.Metadata{
+		Source:    m.queue,
+		ID:        m.del.MessageId,
+		Key:       m.del.RoutingKey,
+		Timestamp: m.del.Timestamp,
+		Headers:   hh,
+		Attributes: map[string]string{
+			amqpAttributeExchange:    m.del.Exchange,
+			amqpAttributeRedelivered: strconv.FormatBool(m.del.Redelivered),
+		},
+		Payload: m.del.Body,
+	}
+}
+
 // Exchange represents an AMQP exchange.
 type Exchange struct {
 	name string
@@ -108,6 +139,45 @@ func New(url, queue string, exchange Exchange, oo ...OptionFunc) (*Factory, erro
 	return &Factory{url: url, queue: queue, exchange: exchange, bindings: []string{""}, oo: oo}, nil
 }
 
+// Info returns information about the consumer configuration.
+// The password of the url, if any, is masked.
+func (f *Factory) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"type":          "amqp",
+		"url":           maskURL(f.url),
+		"queue":         f.queue,
+		"exchange":      f.exchange.name,
+		"exchange_type": f.exchange.kind,
+	}
+}
+
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
+// HealthCheck returns a health check of the connection to the broker, which succeeds when the url can be dialed.
+func (f *Factory) HealthCheck() health.Check {
+	return func(_ context.Context) error {
+		cns, err := f.Create()
+		if err != nil {
+			return err
+		}
+		c := cns.(*consumer)
+		conn, err := amqp.DialConfig(c.url, c.cfg)
+		if err != nil {
+			return fmt.Errorf("failed to dial @ %s: %w", maskURL(c.url), err)
+		}
+		return conn.Close()
+	}
+}
+
 // Create a new consumer.
 func (f *Factory) Create() (async.Consumer, error) {
 
@@ -119,6 +189,7 @@ func (f *Factory) Create() (async.Consumer, error) {
 		cfg:      defaultCfg,
 		buffer:   1000,
 		traceTag: opentracing.Tag{Key: "queue", Value: f.queue},
+		resumed:  make(chan struct{}, 1),
 	}
 
 	for _, o := range f.oo {
@@ -143,14 +214,67 @@ type consumer struct {
 	cfg      amqp.Config
 	ch       *amqp.Channel
 	conn     *amqp.Connection
+	// mu guards the tag and the deliveries, which are nil while the consumer is paused.
+	mu         sync.Mutex
+	deliveries <-chan amqp.Delivery
+	resumed    chan struct{}
+}
+
+// Pause cancels the consumer of the channel, so that the broker stops delivering messages,
+// while the messages which have been delivered can still be acked or nacked.
+func (c *consumer) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deliveries == nil {
+		return nil
+	}
+	err := c.ch.Cancel(c.tag, false)
+	if err != nil {
+		return fmt.Errorf("failed to cancel channel of consumer %s: %w", c.tag, err)
+	}
+	c.deliveries = nil
+	log.Infof("paused consuming messages for tag %s", c.tag)
+	return nil
+}
+
+// Resume consumes the queue again with a new tag.
+func (c *consumer) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deliveries != nil || c.ch == nil {
+		return nil
+	}
+	tag := uuid.New().String()
+	deliveries, err := c.ch.Consume(c.queue, tag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to resume consumer: %w", err)
+	}
+	c.tag = tag
+	c.deliveries = deliveries
+	log.Infof("resumed consuming messages for tag %s", c.tag)
+	select {
+	case c.resumed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (c *consumer) currentDeliveries() <-chan amqp.Delivery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deliveries
 }
 
 // Consume starts of consuming a AMQP queue.
+// The deliveries are switched to the ones of the new consumer of the channel every time the consumer is resumed.
 func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan error, error) {
 	deliveries, err := c.consume()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed initialize consumer: %w", err)
 	}
+	c.mu.Lock()
+	c.deliveries = deliveries
+	c.mu.Unlock()
 
 	chMsg := make(chan async.Message, c.buffer)
 	chErr := make(chan error, c.buffer)
@@ -161,7 +285,19 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 			case <-ctx.Done():
 				log.Info("canceling consuming messages requested")
 				return
-			case d := <-deliveries:
+			case <-c.resumed:
+				deliveries = c.currentDeliveries()
+			case d, ok := <-deliveries:
+				if !ok {
+					// the deliveries are closed when the consumer is paused, resumed meanwhile, or the channel is closed
+					current := c.currentDeliveries()
+					if current == nil || current != deliveries {
+						deliveries = current
+						continue
+					}
+					chErr <- errors.New("deliveries channel closed")
+					return
+				}
 				log.Debugf("processing message %d", d.DeliveryTag)
 				corID := getCorrelationID(d.Headers)
 
@@ -183,6 +319,7 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 				ctxCh = log.WithContext(ctxCh, log.Sub(map[string]interface{}{"correlationID": corID}))
 
 				chMsg <- &message{
+					queue:   c.queue,
 					ctx:     ctxCh,
 					dec:     dec,
 					del:     &d,
@@ -201,7 +338,9 @@ func (c *consumer) Close() error {
 	var errChan error
 	var errConn error
 
-	if c.ch != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ch != nil && c.deliveries != nil {
 		err := c.ch.Cancel(c.tag, true)
 		if err != nil {
 			errChan = fmt.Errorf("failed to cancel channel of consumer %s: %w", c.tag, err)
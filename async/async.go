@@ -2,11 +2,11 @@ package async
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/beatlabs/patron/encoding"
-	"github.com/beatlabs/patron/encoding/json"
-	"github.com/beatlabs/patron/encoding/protobuf"
 )
 
 // FailStrategy type definition.
@@ -19,17 +19,76 @@ const (
 	NackStrategy
 	// AckStrategy acknowledges message and continues.
 	AckStrategy
+	// DeadLetterStrategy publishes the message to a dead-letter destination, acknowledges it and continues.
+	DeadLetterStrategy
 )
 
 // ProcessorFunc definition of a async processor.
 type ProcessorFunc func(Message) error
 
+// BatchProcessorFunc definition of a async processor of batches of messages.
+type BatchProcessorFunc func(*Batch) error
+
+// Batch of messages which are processed together.
+// When the processor succeeds the messages are acked, except the ones marked as failed, for which the failure strategy is executed.
+// When the processor returns an error the failure strategy is executed for every message of the batch.
+type Batch struct {
+	messages []Message
+	failures map[int]error
+}
+
+// Messages returns the messages of the batch, in the order they were consumed.
+func (b *Batch) Messages() []Message {
+	return b.messages
+}
+
+// Fail marks the message at index i of the batch as failed with the provided error.
+func (b *Batch) Fail(i int, err error) {
+	if i < 0 || i >= len(b.messages) {
+		return
+	}
+	if err == nil {
+		err = errors.New("message marked as failed")
+	}
+	b.failures[i] = err
+}
+
+// Metadata of a message as provided by its consumer, which can be used for routing, deduplication and auditing.
+// The fields which are not supported by a consumer are left empty.
+type Metadata struct {
+	// Source is the topic or the queue the message was consumed from.
+	Source string
+	// ID is the identifier of the message, e.g. the SQS or the AMQP message ID.
+	ID string
+	// Key is the Kafka message key or the AMQP routing key.
+	Key string
+	// Partition of a Kafka message.
+	Partition int32
+	// Offset of a Kafka message.
+	Offset int64
+	// Timestamp is the time the message was produced or sent.
+	Timestamp time.Time
+	// Headers of the message, i.e. the Kafka headers, the AMQP headers along with the content type or the SQS string message attributes.
+	Headers map[string]string
+	// Attributes of the message which are specific to the broker, e.g. the SQS system attributes or the AMQP exchange.
+	Attributes map[string]string
+	// DeliveryCount is the number of times the message has been delivered, i.e. the SQS approximate receive count, or zero if unknown.
+	DeliveryCount int
+	// Payload is the raw body of the message.
+	Payload []byte
+}
+
+// KeyFunc definition of a function which extracts the ordering key of a message, e.g. the Kafka message key or the SQS message group.
+// Messages with the same key are processed in order, while messages with an empty key can be processed in any order.
+type KeyFunc func(Message) string
+
 // Message interface for defining messages that are handled by the async component.
 type Message interface {
 	Context() context.Context
 	Decode(v interface{}) error
 	Ack() error
 	Nack() error
+	Metadata() Metadata
 }
 
 // ConsumerFactory interface for creating consumers.
@@ -43,13 +102,11 @@ type Consumer interface {
 	Close() error
 }
 
-// DetermineDecoder determines the decoder based on the content type.
+// DetermineDecoder determines the decoder based on the content type, from the codecs registered in the encoding package.
 func DetermineDecoder(contentType string) (encoding.DecodeRawFunc, error) {
-	switch contentType {
-	case json.Type, json.TypeCharset:
-		return json.DecodeRaw, nil
-	case protobuf.Type, protobuf.TypeGoogle:
-		return protobuf.DecodeRaw, nil
+	c, ok := encoding.Lookup(contentType)
+	if !ok {
+		return nil, fmt.Errorf("content header %s is unsupported", contentType)
 	}
-	return nil, fmt.Errorf("content header %s is unsupported", contentType)
+	return c.DecodeRaw, nil
 }
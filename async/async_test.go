@@ -18,6 +18,7 @@ func TestDetermineDecoder(t *testing.T) {
 		wantErr bool
 	}{
 		{"success json", args{contentType: json.Type}, false},
+		{"success json with charset", args{contentType: json.TypeCharset}, false},
 		{"success protobuf", args{contentType: protobuf.Type}, false},
 		{"failure", args{contentType: "XXX"}, true},
 	}
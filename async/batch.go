@@ -0,0 +1,127 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/log"
+)
+
+// batching consumes messages and processes them in batches until the context is cancelled or an error occurs.
+// A batch is processed when it is full or when the batch timeout has passed since its first message.
+// On cancellation or a consumer error the messages of the incomplete batch are nacked, in order to be redelivered.
+func (c *Component) batching(ctx context.Context, cns Consumer, stopFetching context.CancelFunc,
+	chMsg <-chan Message, chErr <-chan error, cm *componentMetrics) error {
+	msgs := make([]Message, 0, c.batchSize)
+	timer := time.NewTimer(c.batchTimeout)
+	stopTimer(timer)
+	defer timer.Stop()
+
+	flush := func() error {
+		stopTimer(timer)
+		batch := msgs
+		msgs = make([]Message, 0, c.batchSize)
+		return c.processBatch(batch, cm)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			nackMessages(msgs)
+			return shutdown(cns, stopFetching, chMsg)
+		case <-timer.C:
+			log.Debugf("batch timeout expired with %d messages", len(msgs))
+			err := flush()
+			if err != nil {
+				closeConsumer(cns)
+				return err
+			}
+		case msg := <-chMsg:
+			if c.admit(ctx, cm) != nil {
+				nackMessages(append(msgs, msg))
+				return shutdown(cns, stopFetching, chMsg)
+			}
+			log.Debug("New message from consumer arrived")
+			msgs = append(msgs, msg)
+			if len(msgs) == 1 {
+				timer.Reset(c.batchTimeout)
+			}
+			if len(msgs) < c.batchSize {
+				continue
+			}
+			err := flush()
+			if err != nil {
+				closeConsumer(cns)
+				return err
+			}
+		case errMsg := <-chErr:
+			nackMessages(msgs)
+			closeConsumer(cns)
+			return fmt.Errorf("an error occurred during message consumption: %w", errMsg)
+		}
+	}
+}
+
+// processBatch processes the batch and acks its messages, except the failed ones for which the failure strategy is executed.
+// With the NackExitStrategy the failed messages are nacked and the first failure is returned, in order for the component to exit.
+func (c *Component) processBatch(msgs []Message, cm *componentMetrics) error {
+	atomic.AddInt64(&c.inFlight, int64(len(msgs)))
+	defer func() {
+		atomic.AddInt64(&c.inFlight, -int64(len(msgs)))
+		c.markProgress()
+	}()
+	b := &Batch{messages: msgs, failures: make(map[int]error)}
+	err := c.batchProc(b)
+	if err != nil {
+		log.Errorf("failed to process batch of %d messages: %v", len(msgs), err)
+		for i := range msgs {
+			b.failures[i] = err
+		}
+	}
+
+	var exitErr error
+	var ee []error
+	for i, msg := range msgs {
+		failure, failed := b.failures[i]
+		c.observe(failure, cm)
+		switch {
+		case !failed:
+			err = msg.Ack()
+		case c.failStrategy == NackExitStrategy:
+			if exitErr == nil {
+				exitErr = failure
+			}
+			err = msg.Nack()
+			if err != nil {
+				err = fmt.Errorf("failed to NACK message: %w", err)
+			}
+		default:
+			err = c.executeFailureStrategy(msg, failure, 0)
+		}
+		if err != nil {
+			ee = append(ee, err)
+		}
+	}
+	if exitErr != nil && len(ee) == 0 {
+		return exitErr
+	}
+	return patronErrors.Aggregate(append([]error{exitErr}, ee...)...)
+}
+
+func nackMessages(msgs []Message) {
+	for _, msg := range msgs {
+		nackMessage(msg)
+	}
+}
+
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
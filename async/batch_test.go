@@ -0,0 +1,230 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_WithBatching(t *testing.T) {
+	proc := mockProcessor{}
+	bp := func(*Batch) error { return nil }
+	rp, err := NewRetryPolicy(3)
+	require.NoError(t, err)
+	kf := func(Message) string { return "" }
+	tests := map[string]struct {
+		proc    ProcessorFunc
+		bp      BatchProcessorFunc
+		size    uint
+		timeout time.Duration
+		workers uint
+		rp      *RetryPolicy
+		kf      KeyFunc
+		wantErr string
+	}{
+		"success":                        {bp: bp, size: 10, timeout: time.Second, workers: 1},
+		"failure, nil batch processor":   {size: 10, timeout: time.Second, workers: 1, wantErr: "nil batch processor provided\nwork processor is required\n"},
+		"failure, invalid size":          {bp: bp, timeout: time.Second, workers: 1, wantErr: "invalid batch size provided\nwork processor is required\n"},
+		"failure, invalid timeout":       {bp: bp, size: 10, workers: 1, wantErr: "invalid batch timeout provided\nwork processor is required\n"},
+		"failure, with processor":        {proc: proc.Process, bp: bp, size: 10, timeout: time.Second, workers: 1, wantErr: "work processor and batch processor are mutually exclusive\n"},
+		"failure, with concurrency":      {bp: bp, size: 10, timeout: time.Second, workers: 2, wantErr: "batching cannot be combined with concurrency\n"},
+		"failure, with retry policy":     {bp: bp, size: 10, timeout: time.Second, workers: 1, rp: rp, wantErr: "batching cannot be combined with a retry policy\n"},
+		"failure, with key func":         {bp: bp, size: 10, timeout: time.Second, workers: 1, kf: kf, wantErr: "batching cannot be combined with a key func\n"},
+		"failure, without any processor": {workers: 1, wantErr: "work processor is required\n"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := New("name", &mockConsumerFactory{}, tt.proc).WithConcurrency(tt.workers)
+			if tt.bp != nil || tt.size > 0 || tt.timeout > 0 {
+				b.WithBatching(tt.bp, tt.size, tt.timeout)
+			}
+			if tt.rp != nil {
+				b.WithRetryPolicy(tt.rp)
+			}
+			if tt.kf != nil {
+				b.WithKeyFunc(tt.kf)
+			}
+			got, err := b.Create()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, 10, got.Info()["batch_size"])
+				assert.Equal(t, "1s", got.Info()["batch_timeout"])
+			}
+		})
+	}
+}
+
+func TestBatch_Fail(t *testing.T) {
+	b := &Batch{messages: []Message{&mockMessage{}, &mockMessage{}}, failures: make(map[int]error)}
+	b.Fail(-1, errProcess)
+	b.Fail(2, errProcess)
+	assert.Empty(t, b.failures)
+	b.Fail(0, errProcess)
+	b.Fail(1, nil)
+	assert.Equal(t, errProcess, b.failures[0])
+	assert.EqualError(t, b.failures[1], "message marked as failed")
+	assert.Len(t, b.Messages(), 2)
+}
+
+func TestRun_Batch_Size(t *testing.T) {
+	msgs := []*mockMessage{newBatchMessage(), newBatchMessage(), newBatchMessage(), newBatchMessage()}
+	chMsg := make(chan Message, len(msgs))
+	for _, m := range msgs {
+		chMsg <- m
+	}
+	chBatch := make(chan int, 2)
+	bp := func(b *Batch) error {
+		chBatch <- len(b.Messages())
+		return nil
+	}
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runBatching(ctx, t, chMsg, bp, NackExitStrategy, 2, time.Minute)
+	assert.Equal(t, 2, <-chBatch)
+	assert.Equal(t, 2, <-chBatch)
+	cnl()
+	assert.NoError(t, <-chDone)
+	for _, m := range msgs {
+		assert.True(t, m.acked)
+	}
+}
+
+func TestRun_Batch_Timeout(t *testing.T) {
+	msgs := []*mockMessage{newBatchMessage(), newBatchMessage()}
+	chMsg := make(chan Message, len(msgs))
+	for _, m := range msgs {
+		chMsg <- m
+	}
+	chBatch := make(chan int, 1)
+	bp := func(b *Batch) error {
+		chBatch <- len(b.Messages())
+		return nil
+	}
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runBatching(ctx, t, chMsg, bp, NackExitStrategy, 10, 20*time.Millisecond)
+	select {
+	case size := <-chBatch:
+		assert.Equal(t, 2, size)
+	case <-time.After(time.Second):
+		assert.Fail(t, "batch was not processed after the timeout")
+	}
+	cnl()
+	assert.NoError(t, <-chDone)
+	for _, m := range msgs {
+		assert.True(t, m.acked)
+	}
+}
+
+func TestRun_Batch_Failures(t *testing.T) {
+	tests := map[string]struct {
+		fs         FailStrategy
+		bp         BatchProcessorFunc
+		wantErr    error
+		wantAcked  []bool
+		wantNacked []bool
+	}{
+		"individual failure, nack strategy": {
+			fs:         NackStrategy,
+			bp:         func(b *Batch) error { b.Fail(1, errProcess); return nil },
+			wantAcked:  []bool{true, false, true},
+			wantNacked: []bool{false, true, false},
+		},
+		"individual failure, nack exit strategy": {
+			fs:         NackExitStrategy,
+			bp:         func(b *Batch) error { b.Fail(1, errProcess); return nil },
+			wantErr:    errProcess,
+			wantAcked:  []bool{true, false, true},
+			wantNacked: []bool{false, true, false},
+		},
+		"batch failure, ack strategy": {
+			fs:         AckStrategy,
+			bp:         func(b *Batch) error { return errProcess },
+			wantAcked:  []bool{true, true, true},
+			wantNacked: []bool{false, false, false},
+		},
+		"batch failure, nack exit strategy": {
+			fs:         NackExitStrategy,
+			bp:         func(b *Batch) error { return errProcess },
+			wantErr:    errProcess,
+			wantAcked:  []bool{false, false, false},
+			wantNacked: []bool{true, true, true},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			msgs := []*mockMessage{newBatchMessage(), newBatchMessage(), newBatchMessage()}
+			chMsg := make(chan Message, len(msgs))
+			for _, m := range msgs {
+				chMsg <- m
+			}
+			ctx, cnl := context.WithCancel(context.Background())
+			chDone := runBatching(ctx, t, chMsg, tt.bp, tt.fs, 3, time.Minute)
+			if tt.wantErr == nil {
+				time.Sleep(20 * time.Millisecond)
+				cnl()
+			}
+			err := <-chDone
+			cnl()
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+			for i, m := range msgs {
+				assert.Equal(t, tt.wantAcked[i], m.acked, "message %d", i)
+				assert.Equal(t, tt.wantNacked[i], m.nacked, "message %d", i)
+			}
+		})
+	}
+}
+
+// TestRun_Batch_Shutdown_NacksIncomplete verifies that on shutdown the messages of the incomplete batch are nacked
+func TestRun_Batch_Shutdown_NacksIncomplete(t *testing.T) {
+	msgs := []*mockMessage{newBatchMessage(), newBatchMessage()}
+	chMsg := make(chan Message, len(msgs))
+	for _, m := range msgs {
+		chMsg <- m
+	}
+	bp := func(b *Batch) error {
+		assert.Fail(t, "incomplete batch should not be processed")
+		return nil
+	}
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runBatching(ctx, t, chMsg, bp, NackExitStrategy, 10, time.Minute)
+	time.Sleep(20 * time.Millisecond)
+	cnl()
+	assert.NoError(t, <-chDone)
+	for _, m := range msgs {
+		assert.True(t, m.nacked)
+		assert.False(t, m.acked)
+	}
+}
+
+func runBatching(ctx context.Context, t *testing.T, chMsg chan Message, bp BatchProcessorFunc, fs FailStrategy,
+	size uint, timeout time.Duration) <-chan error {
+	cf := &mockConsumerFactory{c: &mockConsumer{chMsg: chMsg, chErr: make(chan error)}}
+	cmp, err := New("test", cf, nil).
+		WithFailureStrategy(fs).
+		WithBatching(bp, size, timeout).
+		Create()
+	require.NoError(t, err)
+
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	return chDone
+}
+
+func newBatchMessage() *mockMessage {
+	return &mockMessage{ctx: context.Background()}
+}
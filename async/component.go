@@ -4,42 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	patronErrors "github.com/beatlabs/patron/errors"
 	"github.com/beatlabs/patron/log"
-	"github.com/prometheus/client_golang/prometheus"
+	"github.com/beatlabs/patron/metrics"
 )
 
 const propSetMSG = "property '%s' set for '%s'"
 
-var consumerErrors *prometheus.CounterVec
-
-func init() {
-	consumerErrors = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: "component",
-			Subsystem: "async",
-			Name:      "consumer_errors",
-			Help:      "Consumer errors, classified by name and type",
-		},
-		[]string{"name"},
-	)
-	prometheus.MustRegister(consumerErrors)
-}
-
-func consumerErrorsInc(name string) {
-	consumerErrors.WithLabelValues(name).Inc()
-}
-
 // Component implementation of a async component.
 type Component struct {
+	inFlight     int64
+	progress     int64
 	name         string
 	proc         ProcessorFunc
 	failStrategy FailStrategy
 	cf           ConsumerFactory
 	retries      int
 	retryWait    time.Duration
+	workers      int
+	keyFunc      KeyFunc
+	batchProc    BatchProcessorFunc
+	batchSize    int
+	batchTimeout time.Duration
+	dlp          DeadLetterPublisher
+	retryPolicy  *RetryPolicy
+	rateLimit    *RateLimit
+	mr           *metrics.Registry
+	gate         PauseGate
+	cnsMu        sync.Mutex
+	cns          Consumer
+	startOnce    sync.Once
+	started      chan struct{}
 }
 
 // Builder gathers all required properties in order to construct a component
@@ -51,10 +50,21 @@ type Builder struct {
 	cf           ConsumerFactory
 	retries      uint
 	retryWait    time.Duration
+	workers      uint
+	keyFunc      KeyFunc
+	batchProc    BatchProcessorFunc
+	batchSize    uint
+	batchTimeout time.Duration
+	dlp          DeadLetterPublisher
+	retryPolicy  *RetryPolicy
+	rateLimit    *RateLimit
+	middlewares  []MiddlewareFunc
+	mr           *metrics.Registry
 }
 
 // New initializes a new builder for a component with the given name
 // by default the failStrategy will be NackExitStrategy.
+// The processor can be nil when a batch processor is provided with WithBatching.
 func New(name string, cf ConsumerFactory, proc ProcessorFunc) *Builder {
 	var errs []error
 	if name == "" {
@@ -63,14 +73,12 @@ func New(name string, cf ConsumerFactory, proc ProcessorFunc) *Builder {
 	if cf == nil {
 		errs = append(errs, errors.New("consumer is required"))
 	}
-	if proc == nil {
-		errs = append(errs, errors.New("work processor is required"))
-	}
 	return &Builder{
-		name:   name,
-		cf:     cf,
-		proc:   proc,
-		errors: errs,
+		name:    name,
+		cf:      cf,
+		proc:    proc,
+		workers: 1,
+		errors:  errs,
 	}
 }
 
@@ -78,7 +86,7 @@ func New(name string, cf ConsumerFactory, proc ProcessorFunc) *Builder {
 // default value is NackExitStrategy
 // it will append an error to the builder if the strategy is not one of the pre-defined ones.
 func (cb *Builder) WithFailureStrategy(fs FailStrategy) *Builder {
-	if fs > AckStrategy || fs < NackExitStrategy {
+	if fs > DeadLetterStrategy || fs < NackExitStrategy {
 		cb.errors = append(cb.errors, errors.New("invalid strategy provided"))
 	} else {
 		log.Infof(propSetMSG, "failure strategy", cb.name)
@@ -87,7 +95,33 @@ func (cb *Builder) WithFailureStrategy(fs FailStrategy) *Builder {
 	return cb
 }
 
-// WithRetries specifies the retry events number for the component
+// WithRetryPolicy specifies the policy of retrying the processing of a message before the failure strategy is executed
+// default is none, which executes the failure strategy on the first failure
+// it will append an error to the builder if the policy is nil.
+func (cb *Builder) WithRetryPolicy(rp *RetryPolicy) *Builder {
+	if rp == nil {
+		cb.errors = append(cb.errors, errors.New("nil retry policy provided"))
+	} else {
+		log.Infof(propSetMSG, "retry policy", cb.name)
+		cb.retryPolicy = rp
+	}
+	return cb
+}
+
+// WithRateLimit specifies the rate limit of the consumption of messages, which are held until the rate limit allows them to be processed
+// default is none, which processes the messages as fast as they are consumed
+// it will append an error to the builder if the rate limit is nil.
+func (cb *Builder) WithRateLimit(rl *RateLimit) *Builder {
+	if rl == nil {
+		cb.errors = append(cb.errors, errors.New("nil rate limit provided"))
+	} else {
+		log.Infof(propSetMSG, "rate limit", cb.name)
+		cb.rateLimit = rl
+	}
+	return cb
+}
+
+// WithRetries specifies the retry events number for the component, which retry re-creating the consumer after it fails
 // default value is '0'.
 func (cb *Builder) WithRetries(retries uint) *Builder {
 	log.Infof(propSetMSG, "retries", cb.name)
@@ -108,39 +142,283 @@ func (cb *Builder) WithRetryWait(retryWait time.Duration) *Builder {
 	return cb
 }
 
+// WithConcurrency specifies the number of workers which process messages concurrently
+// default value is '1', which processes the messages one at a time in the order they are consumed
+// it will append an error to the builder if the value is '0'.
+func (cb *Builder) WithConcurrency(workers uint) *Builder {
+	if workers == 0 {
+		cb.errors = append(cb.errors, errors.New("invalid concurrency provided"))
+	} else {
+		log.Infof(propSetMSG, "concurrency", cb.name)
+		cb.workers = workers
+	}
+	return cb
+}
+
+// WithKeyFunc specifies the function which extracts the ordering key of the messages, which are processed concurrently
+// messages with the same key are processed in order by the same worker, while messages with different keys are processed in parallel
+// default is none, which distributes the messages to the workers in turn without any ordering guarantee
+// it will append an error to the builder if the func is nil.
+func (cb *Builder) WithKeyFunc(kf KeyFunc) *Builder {
+	if kf == nil {
+		cb.errors = append(cb.errors, errors.New("nil key func provided"))
+	} else {
+		log.Infof(propSetMSG, "key func", cb.name)
+		cb.keyFunc = kf
+	}
+	return cb
+}
+
+// WithBatching specifies the processor of batches of messages, which replaces the processor of single messages
+// a batch is processed when it reaches the size or when the timeout has passed since its first message has been consumed
+// batching cannot be combined with concurrency, middlewares, a retry policy or a key func, which apply to single messages
+// it will append an error to the builder if the processor is nil, the size is '0' or the timeout is not positive.
+func (cb *Builder) WithBatching(bp BatchProcessorFunc, size uint, timeout time.Duration) *Builder {
+	if bp == nil {
+		cb.errors = append(cb.errors, errors.New("nil batch processor provided"))
+	} else if size == 0 {
+		cb.errors = append(cb.errors, errors.New("invalid batch size provided"))
+	} else if timeout <= 0 {
+		cb.errors = append(cb.errors, errors.New("invalid batch timeout provided"))
+	} else {
+		log.Infof(propSetMSG, "batching", cb.name)
+		cb.batchProc = bp
+		cb.batchSize = size
+		cb.batchTimeout = timeout
+	}
+	return cb
+}
+
+// WithDeadLetter specifies the publisher of the failed messages, which is required by the DeadLetterStrategy
+// it will append an error to the builder if the publisher is nil.
+func (cb *Builder) WithDeadLetter(p DeadLetterPublisher) *Builder {
+	if p == nil {
+		cb.errors = append(cb.errors, errors.New("nil dead letter publisher provided"))
+	} else {
+		log.Infof(propSetMSG, "dead letter publisher", cb.name)
+		cb.dlp = p
+	}
+	return cb
+}
+
+// WithMiddlewares specifies the middlewares which wrap the processor, in the order they are provided
+// a panic of the processor is always recovered and returned as an error, in order for the failure strategy to be executed
+// it will append an error to the builder if any middleware is nil.
+func (cb *Builder) WithMiddlewares(mm ...MiddlewareFunc) *Builder {
+	for _, m := range mm {
+		if m == nil {
+			cb.errors = append(cb.errors, errors.New("nil middleware provided"))
+			return cb
+		}
+	}
+	log.Infof(propSetMSG, "middlewares", cb.name)
+	cb.middlewares = append(cb.middlewares, mm...)
+	return cb
+}
+
+// WithMetrics specifies the registry of the metrics of the component
+// default value is the default registry of the metrics package at the time the component runs
+// it will append an error to the builder if the registry is nil.
+func (cb *Builder) WithMetrics(mr *metrics.Registry) *Builder {
+	if mr == nil {
+		cb.errors = append(cb.errors, errors.New("nil metrics registry provided"))
+	} else {
+		log.Infof(propSetMSG, "metrics registry", cb.name)
+		cb.mr = mr
+	}
+	return cb
+}
+
 // Create constructs the Component applying
 func (cb *Builder) Create() (*Component, error) {
 
-	if len(cb.errors) > 0 {
-		return nil, patronErrors.Aggregate(cb.errors...)
+	ee := cb.errors
+	switch {
+	case cb.proc == nil && cb.batchProc == nil:
+		ee = append(ee, errors.New("work processor is required"))
+	case cb.proc != nil && cb.batchProc != nil:
+		ee = append(ee, errors.New("work processor and batch processor are mutually exclusive"))
+	case cb.batchProc != nil && cb.workers > 1:
+		ee = append(ee, errors.New("batching cannot be combined with concurrency"))
+	case cb.batchProc != nil && len(cb.middlewares) > 0:
+		ee = append(ee, errors.New("batching cannot be combined with middlewares"))
+	case cb.batchProc != nil && cb.retryPolicy != nil:
+		ee = append(ee, errors.New("batching cannot be combined with a retry policy"))
+	case cb.batchProc != nil && cb.keyFunc != nil:
+		ee = append(ee, errors.New("batching cannot be combined with a key func"))
+	}
+	if cb.failStrategy == DeadLetterStrategy && cb.dlp == nil {
+		ee = append(ee, errors.New("dead letter publisher is required by the dead letter strategy"))
+	}
+
+	if len(ee) > 0 {
+		return nil, patronErrors.Aggregate(ee...)
+	}
+
+	var proc ProcessorFunc
+	if cb.proc != nil {
+		mm := append(append([]MiddlewareFunc{}, cb.middlewares...), NewRecoveryMiddleware())
+		proc = MiddlewareChain(cb.proc, mm...)
+	}
+	var batchProc BatchProcessorFunc
+	if cb.batchProc != nil {
+		batchProc = recoverBatch(cb.batchProc)
 	}
 
 	c := &Component{
 		name:         cb.name,
-		proc:         cb.proc,
+		proc:         proc,
 		cf:           cb.cf,
 		failStrategy: cb.failStrategy,
 		retries:      int(cb.retries),
 		retryWait:    cb.retryWait,
+		workers:      int(cb.workers),
+		keyFunc:      cb.keyFunc,
+		batchProc:    batchProc,
+		batchSize:    int(cb.batchSize),
+		batchTimeout: cb.batchTimeout,
+		dlp:          cb.dlp,
+		retryPolicy:  cb.retryPolicy,
+		rateLimit:    cb.rateLimit,
+		mr:           cb.mr,
+		started:      make(chan struct{}),
 	}
 
 	return c, nil
 }
 
+var failStrategyNames = map[FailStrategy]string{
+	NackExitStrategy:   "nack-exit",
+	NackStrategy:       "nack",
+	AckStrategy:        "ack",
+	DeadLetterStrategy: "dead-letter",
+}
+
+// Info returns information about the component.
+// The consumer configuration is included when the consumer factory provides an Info method.
+func (c *Component) Info() map[string]interface{} {
+	info := map[string]interface{}{
+		"type":          "async",
+		"name":          c.name,
+		"fail_strategy": failStrategyNames[c.failStrategy],
+		"retries":       c.retries,
+		"retry_wait":    c.retryWait.String(),
+		"concurrency":   c.workers,
+	}
+	if c.retryPolicy != nil {
+		info["message_attempts"] = c.retryPolicy.attempts
+	}
+	if c.rateLimit != nil {
+		info["rate_limit"] = c.rateLimit.rate
+		info["rate_limit_burst"] = c.rateLimit.burst
+	}
+	if c.batchProc != nil {
+		info["batch_size"] = c.batchSize
+		info["batch_timeout"] = c.batchTimeout.String()
+	}
+	if d, ok := c.cf.(interface{ Info() map[string]interface{} }); ok {
+		info["consumer"] = d.Info()
+	}
+	return info
+}
+
+// Name returns the name of the component.
+func (c *Component) Name() string {
+	return c.name
+}
+
+// Pause pauses the processing of messages, after the in-flight messages are completed, and stops the consumer
+// from fetching messages if it implements the PausableConsumer interface. The component stays paused when its consumer is re-created.
+func (c *Component) Pause() error {
+	c.cnsMu.Lock()
+	defer c.cnsMu.Unlock()
+	if !c.gate.Pause() {
+		return nil
+	}
+	log.Infof("pausing async component %s", c.name)
+	if p, ok := c.cns.(PausableConsumer); ok {
+		err := p.Pause()
+		if err != nil {
+			return fmt.Errorf("failed to pause consumer: %w", err)
+		}
+	}
+	return nil
+}
+
+// Resume resumes the processing of messages and the consumer of a paused component.
+func (c *Component) Resume() error {
+	c.cnsMu.Lock()
+	defer c.cnsMu.Unlock()
+	if !c.gate.Resume() {
+		return nil
+	}
+	log.Infof("resuming async component %s", c.name)
+	c.markProgress()
+	if p, ok := c.cns.(PausableConsumer); ok {
+		err := p.Resume()
+		if err != nil {
+			return fmt.Errorf("failed to resume consumer: %w", err)
+		}
+	}
+	return nil
+}
+
+// Started returns a channel which is closed once the consumer of the component has started consuming,
+// in order for the service to start the next phase only after the component is operational.
+func (c *Component) Started() <-chan struct{} {
+	return c.started
+}
+
+// Paused returns true if the component is paused.
+func (c *Component) Paused() bool {
+	return c.gate.Paused()
+}
+
+// InFlight returns the number of messages which are being processed.
+func (c *Component) InFlight() int {
+	return int(atomic.LoadInt64(&c.inFlight))
+}
+
+// setConsumer keeps the consumer which is consuming, in order to be paused and resumed, and pauses it if the component is paused.
+func (c *Component) setConsumer(cns Consumer) error {
+	c.cnsMu.Lock()
+	defer c.cnsMu.Unlock()
+	c.cns = cns
+	if cns != nil {
+		c.markProgress()
+	}
+	if p, ok := cns.(PausableConsumer); ok && c.gate.Paused() {
+		err := p.Pause()
+		if err != nil {
+			return fmt.Errorf("failed to pause consumer: %w", err)
+		}
+	}
+	return nil
+}
+
 // Run starts the consumer processing loop messages.
 func (c *Component) Run(ctx context.Context) error {
-
-	var err error
+	mr := c.mr
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	cm, err := newComponentMetrics(mr)
+	if err != nil {
+		return err
+	}
+	if c.rateLimit != nil {
+		cm.rateLimitRate.WithLabelValues(c.name).Set(c.rateLimit.Rate())
+	}
 
 	for i := 0; i <= c.retries; i++ {
-		err = c.processing(ctx)
+		err = c.processing(ctx, cm)
 		if err == nil {
 			return nil
 		}
 		if ctx.Err() == context.Canceled {
 			break
 		}
-		consumerErrorsInc(c.name)
+		cm.consumerErrors.WithLabelValues(c.name).Inc()
 		if c.retries > 0 {
 			log.Errorf("failed run, retry %d/%d with %v wait: %v", i, c.retries, c.retryWait, err)
 			time.Sleep(c.retryWait)
@@ -150,61 +428,155 @@ func (c *Component) Run(ctx context.Context) error {
 	return err
 }
 
-func (c *Component) processing(ctx context.Context) error {
+// processing consumes and processes messages until the context is cancelled or an error occurs.
+// On cancellation the consumer stops fetching, the in-flight message is completed, the messages already fetched
+// are nacked, in order to be redelivered, and the consumer is closed.
+// With a concurrency greater than one, the messages are dispatched to a pool of workers,
+// while with batching, the messages are processed in batches.
+// While the component is paused, the consumed message is held until it is resumed or the context is cancelled,
+// and then until the rate limit, if any, allows it to be processed.
+func (c *Component) processing(ctx context.Context, cm *componentMetrics) error {
 
 	cns, err := c.cf.Create()
 	if err != nil {
 		return fmt.Errorf("failed to create consumer: %w", err)
 	}
-	defer func() {
-		err = cns.Close()
-		if err != nil {
-			log.Warnf("failed to close consumer: %v", err)
-		}
-	}()
 
-	chMsg, chErr, err := cns.Consume(ctx)
+	// The consumer context is not derived from ctx, so that the cancellation does not abort in-flight messages.
+	cctx, cnl := context.WithCancel(context.Background())
+	defer cnl()
+
+	chMsg, chErr, err := cns.Consume(cctx)
 	if err != nil {
+		closeConsumer(cns)
 		return fmt.Errorf("failed to get consumer channels: %w", err)
 	}
+	defer func() { _ = c.setConsumer(nil) }()
+	err = c.setConsumer(cns)
+	if err != nil {
+		closeConsumer(cns)
+		return err
+	}
+	c.startOnce.Do(func() { close(c.started) })
+
+	if c.batchProc != nil {
+		return c.batching(ctx, cns, cnl, chMsg, chErr, cm)
+	}
 
-	failCh := make(chan error)
-
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				log.Info("closing consumer")
-				failCh <- cns.Close()
-			case msg := <-chMsg:
-				log.Debug("New message from consumer arrived")
-				c.processMessage(msg, failCh)
-			case errMsg := <-chErr:
-				failCh <- fmt.Errorf("an error occurred during message consumption: %w", errMsg)
-				return
+	if c.workers > 1 {
+		return c.dispatching(ctx, cns, cnl, chMsg, chErr, cm)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return shutdown(cns, cnl, chMsg)
+		case msg := <-chMsg:
+			if c.admit(ctx, cm) != nil {
+				nackMessage(msg)
+				return shutdown(cns, cnl, chMsg)
+			}
+			log.Debug("New message from consumer arrived")
+			err = c.processMessage(ctx, msg, cm)
+			if err != nil {
+				closeConsumer(cns)
+				return err
 			}
+		case errMsg := <-chErr:
+			closeConsumer(cns)
+			return fmt.Errorf("an error occurred during message consumption: %w", errMsg)
 		}
-	}()
-	return <-failCh
+	}
 }
 
-func (c *Component) processMessage(msg Message, ch chan error) {
-	err := c.proc(msg)
-	if err != nil {
-		err := c.executeFailureStrategy(msg, err)
+// processMessage processes the message, retrying it according to the retry policy, and acks it
+// or executes the failure strategy if it is still failing.
+// The message is nacked, in order to be redelivered, when the context is cancelled while waiting to retry it.
+func (c *Component) processMessage(ctx context.Context, msg Message, cm *componentMetrics) error {
+	atomic.AddInt64(&c.inFlight, 1)
+	defer func() {
+		atomic.AddInt64(&c.inFlight, -1)
+		c.markProgress()
+	}()
+	retries, err := c.retryPolicy.process(ctx, msg, c.proc)
+	if err != errRetryAborted {
+		c.observe(err, cm)
+	}
+	if retries > 0 {
+		outcome := "success"
 		if err != nil {
-			ch <- err
+			outcome = "failure"
 		}
+		cm.messageRetries.WithLabelValues(c.name, outcome).Add(float64(retries))
+	}
+	if err == errRetryAborted {
+		nackMessage(msg)
+		return nil
+	}
+	if err != nil {
+		return c.executeFailureStrategy(msg, err, retries)
+	}
+	return msg.Ack()
+}
+
+// admit holds the consumed message while the component is paused, and then until the rate limit allows it to be processed.
+func (c *Component) admit(ctx context.Context, cm *componentMetrics) error {
+	err := c.gate.Wait(ctx)
+	if err != nil || c.rateLimit == nil {
+		return err
+	}
+	wait, err := c.rateLimit.wait(ctx)
+	if wait > 0 {
+		cm.rateLimitThrottled.WithLabelValues(c.name).Inc()
+		cm.rateLimitWait.WithLabelValues(c.name).Observe(wait.Seconds())
+	}
+	return err
+}
+
+// observe records the outcome of the processing of a message to the rate limit, which may adapt its rate.
+func (c *Component) observe(err error, cm *componentMetrics) {
+	if c.rateLimit == nil {
 		return
 	}
-	if err := msg.Ack(); err != nil {
-		ch <- err
+	c.rateLimit.observe(err)
+	cm.rateLimitRate.WithLabelValues(c.name).Set(c.rateLimit.Rate())
+}
+
+// shutdown stops the consumer from fetching, nacks the messages which have been fetched
+// but not processed yet, in order to be redelivered, and closes the consumer.
+func shutdown(cns Consumer, stopFetching context.CancelFunc, chMsg <-chan Message) error {
+	log.Info("closing consumer")
+	stopFetching()
+	for {
+		select {
+		case msg, ok := <-chMsg:
+			if !ok {
+				return cns.Close()
+			}
+			nackMessage(msg)
+		default:
+			return cns.Close()
+		}
+	}
+}
+
+func nackMessage(msg Message) {
+	err := msg.Nack()
+	if err != nil {
+		log.FromContext(msg.Context()).Warnf("failed to nack pending message: %v", err)
+	}
+}
+
+func closeConsumer(cns Consumer) {
+	err := cns.Close()
+	if err != nil {
+		log.Warnf("failed to close consumer: %v", err)
 	}
 }
 
 var errInvalidFS = errors.New("invalid failure strategy")
 
-func (c *Component) executeFailureStrategy(msg Message, err error) error {
+func (c *Component) executeFailureStrategy(msg Message, err error, retries int) error {
 	log.FromContext(msg.Context()).Errorf("failed to process message, failure strategy executed: %v", err)
 	switch c.failStrategy {
 	case NackExitStrategy:
@@ -223,6 +595,11 @@ func (c *Component) executeFailureStrategy(msg Message, err error) error {
 		if err != nil {
 			return fmt.Errorf("ack failed when executing failure strategy: %w", err)
 		}
+	case DeadLetterStrategy:
+		err := c.deadLetter(msg, err, retries)
+		if err != nil {
+			return fmt.Errorf("dead letter failed when executing failure strategy: %w", err)
+		}
 	default:
 		return errInvalidFS
 	}
@@ -19,6 +19,8 @@ func TestNew(t *testing.T) {
 		fs        FailStrategy
 		retries   uint
 		retryWait time.Duration
+		workers   uint
+		kf        KeyFunc
 	}
 	tests := []struct {
 		name    string
@@ -27,32 +29,47 @@ func TestNew(t *testing.T) {
 	}{
 		{
 			name:    "success",
-			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, fs: NackExitStrategy},
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, fs: NackExitStrategy, workers: 1, kf: messageKey},
+			wantErr: false,
+		},
+		{
+			name:    "success, with concurrency and key func",
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, workers: 4, kf: messageKey},
 			wantErr: false,
 		},
 		{
 			name:    "failed, missing name",
-			args:    args{name: "", p: proc.Process, cf: &mockConsumerFactory{}, fs: NackExitStrategy},
+			args:    args{name: "", p: proc.Process, cf: &mockConsumerFactory{}, fs: NackExitStrategy, workers: 1, kf: messageKey},
 			wantErr: true,
 		},
 		{
 			name:    "failed, missing processor func",
-			args:    args{name: "name", p: nil, cf: &mockConsumerFactory{}, fs: NackExitStrategy},
+			args:    args{name: "name", p: nil, cf: &mockConsumerFactory{}, fs: NackExitStrategy, workers: 1, kf: messageKey},
 			wantErr: true,
 		},
 		{
 			name:    "failed, missing consumer",
-			args:    args{name: "name", p: proc.Process, cf: nil, fs: NackExitStrategy},
+			args:    args{name: "name", p: proc.Process, cf: nil, fs: NackExitStrategy, workers: 1, kf: messageKey},
 			wantErr: true,
 		},
 		{
 			name:    "failed, invalid fail strategy",
-			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, fs: 3},
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, fs: 3, workers: 1, kf: messageKey},
 			wantErr: true,
 		},
 		{
 			name:    "failed, invalid retry retry timeout",
-			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, retryWait: -2},
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, retryWait: -2, workers: 1, kf: messageKey},
+			wantErr: true,
+		},
+		{
+			name:    "failed, invalid concurrency",
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, workers: 0, kf: messageKey},
+			wantErr: true,
+		},
+		{
+			name:    "failed, missing key func",
+			args:    args{name: "name", p: proc.Process, cf: &mockConsumerFactory{}, workers: 1, kf: nil},
 			wantErr: true,
 		},
 	}
@@ -62,6 +79,8 @@ func TestNew(t *testing.T) {
 				WithFailureStrategy(tt.args.fs).
 				WithRetries(tt.args.retries).
 				WithRetryWait(tt.args.retryWait).
+				WithConcurrency(tt.args.workers).
+				WithKeyFunc(tt.args.kf).
 				Create()
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -74,6 +93,36 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestComponent_Info(t *testing.T) {
+	proc := mockProcessor{}
+	cmp, err := New("name", &mockConsumerFactory{}, proc.Process).
+		WithFailureStrategy(NackStrategy).
+		WithRetries(3).
+		WithRetryWait(time.Second).
+		Create()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type":          "async",
+		"name":          "name",
+		"fail_strategy": "nack",
+		"retries":       3,
+		"retry_wait":    "1s",
+		"concurrency":   1,
+	}, cmp.Info())
+
+	cmp, err = New("name", &infoConsumerFactory{}, proc.Process).Create()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"queue": "test"}, cmp.Info()["consumer"])
+}
+
+type infoConsumerFactory struct {
+	mockConsumerFactory
+}
+
+func (icf *infoConsumerFactory) Info() map[string]interface{} {
+	return map[string]interface{}{"queue": "test"}
+}
+
 type proxyBuilder struct {
 	proc      mockProcessor
 	cnr       mockConsumer
@@ -112,6 +161,30 @@ func TestRun_ReturnsError(t *testing.T) {
 
 }
 
+func TestComponent_Started(t *testing.T) {
+	proc := mockProcessor{}
+	failing, err := New("failing", &mockConsumerFactory{c: &mockConsumer{consumeError: true}}, proc.Process).Create()
+	assert.NoError(t, err)
+	assert.Error(t, failing.Run(context.Background()))
+	select {
+	case <-failing.Started():
+		assert.Fail(t, "component started although the consumer failed to consume")
+	default:
+	}
+
+	cnr := mockConsumer{chMsg: make(chan Message), chErr: make(chan error)}
+	cmp, err := New("test", &mockConsumerFactory{c: &cnr}, proc.Process).Create()
+	assert.NoError(t, err)
+	ctx, cnl := context.WithCancel(context.Background())
+	chErr := make(chan error)
+	go func() {
+		chErr <- cmp.Run(ctx)
+	}()
+	<-cmp.Started()
+	cnl()
+	assert.NoError(t, <-chErr)
+}
+
 // TestRun_WithCancel_CloseError expects a consumer closing error
 func TestRun_WithCancel_CloseError(t *testing.T) {
 
@@ -391,6 +464,39 @@ func TestRun_Process_Shutdown(t *testing.T) {
 
 }
 
+// TestRun_Process_Shutdown_NacksPending verifies that on shutdown the in-flight message is completed
+// and the messages which have been fetched but not processed are nacked
+func TestRun_Process_Shutdown_NacksPending(t *testing.T) {
+
+	ctx, cnl := context.WithCancel(context.Background())
+	builder := proxyBuilder{
+		proc: mockProcessor{onProcess: cnl},
+		cnr: mockConsumer{
+			chMsg: make(chan Message, 10),
+			chErr: make(chan error, 10),
+		},
+	}
+
+	inFlight := &mockMessage{ctx: context.Background()}
+	pending := []*mockMessage{{ctx: context.Background()}, {ctx: context.Background()}}
+	builder.cnr.chMsg <- inFlight
+	for _, m := range pending {
+		builder.cnr.chMsg <- m
+	}
+
+	err := run(ctx, t, &builder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, builder.proc.execs)
+	assert.True(t, inFlight.acked)
+	for _, m := range pending {
+		assert.True(t, m.nacked)
+		assert.False(t, m.acked)
+	}
+	assert.True(t, builder.cnr.consumeCtx.Err() != nil)
+
+}
+
 // TestRun_Process_Error_InvalidStrategy expects a invalid failure strategy error
 // NOTE : we injected the failure strategy after the construction,
 // in order to avoid the failure strategy check
@@ -417,6 +523,8 @@ type mockMessage struct {
 	ctx       context.Context
 	ackError  bool
 	nackError bool
+	acked     bool
+	nacked    bool
 }
 
 func (mm *mockMessage) Context() context.Context {
@@ -431,6 +539,7 @@ func (mm *mockMessage) Decode(v interface{}) error {
 var errAck = errors.New("MESSAGE ACK ERROR")
 
 func (mm *mockMessage) Ack() error {
+	mm.acked = true
 	if mm.ackError {
 		return errAck
 	}
@@ -440,21 +549,30 @@ func (mm *mockMessage) Ack() error {
 var errNack = errors.New("MESSAGE NACK ERROR")
 
 func (mm *mockMessage) Nack() error {
+	mm.nacked = true
 	if mm.nackError {
 		return errNack
 	}
 	return nil
 }
 
+func (mm *mockMessage) Metadata() Metadata {
+	return Metadata{}
+}
+
 type mockProcessor struct {
 	errReturn bool
 	execs     int
+	onProcess func()
 }
 
 var errProcess = errors.New("PROC ERROR")
 
 func (mp *mockProcessor) Process(msg Message) error {
 	mp.execs++
+	if mp.onProcess != nil {
+		mp.onProcess()
+	}
 	if mp.errReturn {
 		return errProcess
 	}
@@ -482,6 +600,7 @@ type mockConsumer struct {
 	clsError     bool
 	chMsg        chan Message
 	chErr        chan error
+	consumeCtx   context.Context
 }
 
 func (mc *mockConsumer) SetTimeout(timeout time.Duration) {
@@ -489,7 +608,8 @@ func (mc *mockConsumer) SetTimeout(timeout time.Duration) {
 
 var errConsumer = errors.New("CONSUMER ERROR")
 
-func (mc *mockConsumer) Consume(context.Context) (<-chan Message, <-chan error, error) {
+func (mc *mockConsumer) Consume(ctx context.Context) (<-chan Message, <-chan error, error) {
+	mc.consumeCtx = ctx
 	if mc.consumeError {
 		return nil, nil, errConsumer
 	}
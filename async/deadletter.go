@@ -0,0 +1,80 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// DeadLetterErrorHeader is the header of a dead letter with the error which failed the message.
+	DeadLetterErrorHeader = "X-Dead-Letter-Error"
+	// DeadLetterRetriesHeader is the header of a dead letter with the processing retries of the message.
+	DeadLetterRetriesHeader = "X-Dead-Letter-Retries"
+	// DeadLetterSourceHeader is the header of a dead letter with the source of the message, e.g. the topic or queue.
+	DeadLetterSourceHeader = "X-Dead-Letter-Source"
+	// DeadLetterTimestampHeader is the header of a dead letter with the time it failed, in RFC3339 format.
+	DeadLetterTimestampHeader = "X-Dead-Letter-Timestamp"
+)
+
+// DeadLetter is a message which failed to be processed, along with the reason of the failure.
+type DeadLetter struct {
+	Payload   []byte
+	Headers   map[string]string
+	Error     string
+	Retries   int
+	Source    string
+	Timestamp time.Time
+}
+
+// Attributes returns the original headers of the message along with the dead letter headers.
+func (dl *DeadLetter) Attributes() map[string]string {
+	attrs := make(map[string]string, len(dl.Headers)+4)
+	for k, v := range dl.Headers {
+		attrs[k] = v
+	}
+	attrs[DeadLetterErrorHeader] = dl.Error
+	attrs[DeadLetterRetriesHeader] = strconv.Itoa(dl.Retries)
+	attrs[DeadLetterSourceHeader] = dl.Source
+	attrs[DeadLetterTimestampHeader] = dl.Timestamp.Format(time.RFC3339)
+	return attrs
+}
+
+// DeadLetterPublisher publishes dead letters to a destination, e.g. a topic or a queue.
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, dl *DeadLetter) error
+}
+
+// DeadLetterPublisherFunc is an adapter which allows the use of ordinary functions as dead letter publishers.
+type DeadLetterPublisherFunc func(ctx context.Context, dl *DeadLetter) error
+
+// Publish calls f(ctx, dl).
+func (f DeadLetterPublisherFunc) Publish(ctx context.Context, dl *DeadLetter) error {
+	return f(ctx, dl)
+}
+
+// deadLetter publishes the failed message to the dead letter destination and acks it.
+// The message is nacked if it cannot be published, in order to be redelivered.
+func (c *Component) deadLetter(msg Message, cause error, retries int) error {
+	md := msg.Metadata()
+	if md.Payload == nil {
+		nackMessage(msg)
+		return errors.New("message does not expose its payload")
+	}
+	dl := &DeadLetter{
+		Payload:   md.Payload,
+		Headers:   md.Headers,
+		Error:     cause.Error(),
+		Retries:   retries,
+		Source:    md.Source,
+		Timestamp: time.Now().UTC(),
+	}
+	err := c.dlp.Publish(msg.Context(), dl)
+	if err != nil {
+		nackMessage(msg)
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+	return msg.Ack()
+}
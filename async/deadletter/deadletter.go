@@ -0,0 +1,139 @@
+// Package deadletter provides publishers of dead letters to Kafka topics, AMQP exchanges, SQS queues and SNS topics,
+// which are used by the dead letter failure strategy of the async component.
+// The dead letters keep the original payload and headers of the failed messages and add the dead letter headers,
+// with the error, the retries, the source and the timestamp of the failure.
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/encoding"
+	"github.com/beatlabs/patron/trace/amqp"
+	"github.com/beatlabs/patron/trace/kafka"
+	"github.com/beatlabs/patron/trace/sns"
+)
+
+// HeadersAttribute is the message attribute of the dead letters sent to SQS and SNS, with the original headers of the message
+// encoded as a JSON object.
+const HeadersAttribute = "X-Dead-Letter-Headers"
+
+// Kafka returns a publisher of dead letters to a Kafka topic.
+func Kafka(p kafka.Producer, topic string) (async.DeadLetterPublisher, error) {
+	if p == nil {
+		return nil, errors.New("producer is required")
+	}
+	if topic == "" {
+		return nil, errors.New("topic is required")
+	}
+	return async.DeadLetterPublisherFunc(func(ctx context.Context, dl *async.DeadLetter) error {
+		msg := kafka.NewRawMessage(topic, dl.Payload)
+		for k, v := range dl.Attributes() {
+			msg.WithHeader(k, v)
+		}
+		return p.Send(ctx, msg)
+	}), nil
+}
+
+// AMQP returns a publisher of dead letters to the exchange of an AMQP publisher.
+// The content type of the dead letter is the one of the original message.
+func AMQP(p amqp.Publisher) (async.DeadLetterPublisher, error) {
+	if p == nil {
+		return nil, errors.New("publisher is required")
+	}
+	return async.DeadLetterPublisherFunc(func(ctx context.Context, dl *async.DeadLetter) error {
+		attrs := dl.Attributes()
+		msg := amqp.NewMessage(attrs[encoding.ContentTypeHeader], dl.Payload)
+		delete(attrs, encoding.ContentTypeHeader)
+		for k, v := range attrs {
+			msg.WithHeader(k, v)
+		}
+		return p.Publish(ctx, msg)
+	}), nil
+}
+
+// SQS returns a publisher of dead letters to a SQS queue.
+// The dead letter headers and the content type are sent as string message attributes, while the original headers
+// are sent encoded in the HeadersAttribute, in order to stay within the limit of 10 message attributes of SQS.
+func SQS(api sqsiface.SQSAPI, queueURL string) (async.DeadLetterPublisher, error) {
+	if api == nil {
+		return nil, errors.New("SQS API is required")
+	}
+	if queueURL == "" {
+		return nil, errors.New("queue URL is required")
+	}
+	return async.DeadLetterPublisherFunc(func(ctx context.Context, dl *async.DeadLetter) error {
+		aa, err := messageAttributes(dl)
+		if err != nil {
+			return err
+		}
+		attrs := make(map[string]*sqs.MessageAttributeValue, len(aa))
+		for k, v := range aa {
+			attrs[k] = &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+		}
+		_, err = api.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+			QueueUrl:          aws.String(queueURL),
+			MessageBody:       aws.String(string(dl.Payload)),
+			MessageAttributes: attrs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send dead letter to queue %s: %w", queueURL, err)
+		}
+		return nil
+	}), nil
+}
+
+// SNS returns a publisher of dead letters to a SNS topic.
+// The dead letter headers and the content type are sent as string message attributes, while the original headers
+// are sent encoded in the HeadersAttribute, in order to stay within the limit of 10 message attributes of SNS.
+func SNS(p sns.Publisher, topicArn string) (async.DeadLetterPublisher, error) {
+	if p == nil {
+		return nil, errors.New("publisher is required")
+	}
+	if topicArn == "" {
+		return nil, errors.New("topic ARN is required")
+	}
+	return async.DeadLetterPublisherFunc(func(ctx context.Context, dl *async.DeadLetter) error {
+		attrs, err := messageAttributes(dl)
+		if err != nil {
+			return err
+		}
+		b := sns.NewMessageBuilder().Message(string(dl.Payload)).TopicArn(topicArn)
+		for k, v := range attrs {
+			b.WithStringAttribute(k, v)
+		}
+		msg, err := b.Build()
+		if err != nil {
+			return fmt.Errorf("failed to build dead letter: %w", err)
+		}
+		_, err = p.Publish(ctx, *msg)
+		return err
+	}), nil
+}
+
+// messageAttributes returns the non empty dead letter headers and content type of the dead letter,
+// along with its original headers encoded in the HeadersAttribute, since SQS and SNS allow only 10 attributes per message.
+func messageAttributes(dl *async.DeadLetter) (map[string]string, error) {
+	all := dl.Attributes()
+	attrs := make(map[string]string, 6)
+	for _, k := range []string{encoding.ContentTypeHeader, async.DeadLetterErrorHeader, async.DeadLetterRetriesHeader,
+		async.DeadLetterSourceHeader, async.DeadLetterTimestampHeader} {
+		if v := all[k]; v != "" {
+			attrs[k] = v
+		}
+	}
+	if len(dl.Headers) > 0 {
+		b, err := json.Marshal(dl.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the headers of the dead letter: %w", err)
+		}
+		attrs[HeadersAttribute] = string(b)
+	}
+	return attrs, nil
+}
@@ -0,0 +1,187 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/trace/amqp"
+	"github.com/beatlabs/patron/trace/kafka"
+	"github.com/beatlabs/patron/trace/sns"
+	"github.com/stretchr/testify/assert"
+)
+
+var errPublish = errors.New("PUBLISH ERROR")
+
+func deadLetter() *async.DeadLetter {
+	return &async.DeadLetter{
+		Payload:   []byte(`{"key":"value"}`),
+		Headers:   map[string]string{"Content-Type": "application/json", "empty": ""},
+		Error:     "PROC ERROR",
+		Retries:   1,
+		Source:    "orders",
+		Timestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestKafka(t *testing.T) {
+	_, err := Kafka(nil, "topic")
+	assert.EqualError(t, err, "producer is required")
+	_, err = Kafka(&stubProducer{}, "")
+	assert.EqualError(t, err, "topic is required")
+
+	p := &stubProducer{}
+	dlp, err := Kafka(p, "topic")
+	assert.NoError(t, err)
+	assert.NoError(t, dlp.Publish(context.Background(), deadLetter()))
+	assert.Equal(t, 1, p.sent)
+
+	dlp, err = Kafka(&stubProducer{err: errPublish}, "topic")
+	assert.NoError(t, err)
+	assert.Equal(t, errPublish, dlp.Publish(context.Background(), deadLetter()))
+}
+
+func TestAMQP(t *testing.T) {
+	_, err := AMQP(nil)
+	assert.EqualError(t, err, "publisher is required")
+
+	p := &stubPublisher{}
+	dlp, err := AMQP(p)
+	assert.NoError(t, err)
+	assert.NoError(t, dlp.Publish(context.Background(), deadLetter()))
+	assert.Equal(t, 1, p.published)
+
+	dlp, err = AMQP(&stubPublisher{err: errPublish})
+	assert.NoError(t, err)
+	assert.Equal(t, errPublish, dlp.Publish(context.Background(), deadLetter()))
+}
+
+func TestSQS(t *testing.T) {
+	_, err := SQS(nil, "url")
+	assert.EqualError(t, err, "SQS API is required")
+	_, err = SQS(&stubSQS{}, "")
+	assert.EqualError(t, err, "queue URL is required")
+
+	api := &stubSQS{}
+	dlp, err := SQS(api, "url")
+	assert.NoError(t, err)
+	assert.NoError(t, dlp.Publish(context.Background(), deadLetter()))
+	assert.Equal(t, "url", aws.StringValue(api.input.QueueUrl))
+	assert.Equal(t, `{"key":"value"}`, aws.StringValue(api.input.MessageBody))
+	attrs := make(map[string]string)
+	for k, v := range api.input.MessageAttributes {
+		assert.Equal(t, "String", aws.StringValue(v.DataType))
+		attrs[k] = aws.StringValue(v.StringValue)
+	}
+	assert.Equal(t, map[string]string{
+		"Content-Type":                  "application/json",
+		async.DeadLetterErrorHeader:     "PROC ERROR",
+		async.DeadLetterRetriesHeader:   "1",
+		async.DeadLetterSourceHeader:    "orders",
+		async.DeadLetterTimestampHeader: "2020-01-02T03:04:05Z",
+		HeadersAttribute:                `{"Content-Type":"application/json","empty":""}`,
+	}, attrs)
+
+	dlp, err = SQS(&stubSQS{err: errPublish}, "url")
+	assert.NoError(t, err)
+	err = dlp.Publish(context.Background(), deadLetter())
+	assert.EqualError(t, err, "failed to send dead letter to queue url: PUBLISH ERROR")
+	assert.True(t, errors.Is(err, errPublish))
+}
+
+func TestSQS_ManyHeaders(t *testing.T) {
+	dl := deadLetter()
+	for i := 0; i < 20; i++ {
+		dl.Headers[fmt.Sprintf("header-%d", i)] = strconv.Itoa(i)
+	}
+	api := &stubSQS{}
+	dlp, err := SQS(api, "url")
+	assert.NoError(t, err)
+	assert.NoError(t, dlp.Publish(context.Background(), dl))
+
+	// the original headers are encoded in a single attribute, in order to stay within the limit of 10 attributes
+	assert.Len(t, api.input.MessageAttributes, 6)
+	hdr := make(map[string]string)
+	assert.NoError(t, json.Unmarshal([]byte(aws.StringValue(api.input.MessageAttributes[HeadersAttribute].StringValue)), &hdr))
+	assert.Equal(t, dl.Headers, hdr)
+}
+
+func TestSNS(t *testing.T) {
+	_, err := SNS(nil, "arn")
+	assert.EqualError(t, err, "publisher is required")
+	_, err = SNS(&stubSNS{}, "")
+	assert.EqualError(t, err, "topic ARN is required")
+
+	p := &stubSNS{}
+	dlp, err := SNS(p, "arn")
+	assert.NoError(t, err)
+	assert.NoError(t, dlp.Publish(context.Background(), deadLetter()))
+	assert.Equal(t, 1, p.published)
+
+	dl := deadLetter()
+	for i := 0; i < 20; i++ {
+		dl.Headers[fmt.Sprintf("header-%d", i)] = strconv.Itoa(i)
+	}
+	assert.NoError(t, dlp.Publish(context.Background(), dl))
+	assert.Equal(t, 2, p.published)
+
+	dlp, err = SNS(&stubSNS{err: errPublish}, "arn")
+	assert.NoError(t, err)
+	assert.Equal(t, errPublish, dlp.Publish(context.Background(), deadLetter()))
+}
+
+type stubProducer struct {
+	kafka.Producer
+	sent int
+	err  error
+}
+
+func (sp *stubProducer) Send(_ context.Context, _ *kafka.Message) error {
+	sp.sent++
+	return sp.err
+}
+
+type stubPublisher struct {
+	amqp.Publisher
+	published int
+	err       error
+}
+
+func (sp *stubPublisher) Publish(_ context.Context, _ *amqp.Message) error {
+	sp.published++
+	return sp.err
+}
+
+type stubSQS struct {
+	sqsiface.SQSAPI
+	input *sqs.SendMessageInput
+	err   error
+}
+
+func (s *stubSQS) SendMessageWithContext(_ aws.Context, input *sqs.SendMessageInput, _ ...request.Option) (*sqs.SendMessageOutput, error) {
+	s.input = input
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &sqs.SendMessageOutput{}, nil
+}
+
+type stubSNS struct {
+	sns.Publisher
+	published int
+	err       error
+}
+
+func (s *stubSNS) Publish(_ context.Context, _ sns.Message) (string, error) {
+	s.published++
+	return "id", s.err
+}
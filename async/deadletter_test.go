@@ -0,0 +1,134 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetter_Attributes(t *testing.T) {
+	dl := &DeadLetter{
+		Payload:   []byte("payload"),
+		Headers:   map[string]string{"Content-Type": "application/json"},
+		Error:     "PROC ERROR",
+		Retries:   2,
+		Source:    "orders",
+		Timestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	assert.Equal(t, map[string]string{
+		"Content-Type":            "application/json",
+		DeadLetterErrorHeader:     "PROC ERROR",
+		DeadLetterRetriesHeader:   "2",
+		DeadLetterSourceHeader:    "orders",
+		DeadLetterTimestampHeader: "2020-01-02T03:04:05Z",
+	}, dl.Attributes())
+	assert.Len(t, dl.Headers, 1)
+}
+
+func TestBuilder_DeadLetterStrategy(t *testing.T) {
+	proc := mockProcessor{}
+	dlp := DeadLetterPublisherFunc(func(context.Context, *DeadLetter) error { return nil })
+
+	cmp, err := New("name", &mockConsumerFactory{}, proc.Process).WithFailureStrategy(DeadLetterStrategy).WithDeadLetter(dlp).Create()
+	assert.NoError(t, err)
+	assert.Equal(t, "dead-letter", cmp.Info()["fail_strategy"])
+
+	cmp, err = New("name", &mockConsumerFactory{}, proc.Process).WithFailureStrategy(DeadLetterStrategy).Create()
+	assert.EqualError(t, err, "dead letter publisher is required by the dead letter strategy\n")
+	assert.Nil(t, cmp)
+
+	cmp, err = New("name", &mockConsumerFactory{}, proc.Process).WithDeadLetter(nil).Create()
+	assert.EqualError(t, err, "nil dead letter publisher provided\n")
+	assert.Nil(t, cmp)
+}
+
+func TestRun_Process_Error_DeadLetterStrategy(t *testing.T) {
+	errPublish := errors.New("PUBLISH ERROR")
+	tests := map[string]struct {
+		msg        Message
+		publishErr error
+		wantErr    string
+		wantAcked  bool
+		wantNacked bool
+	}{
+		"success": {
+			msg:       &rawMessage{mockMessage: mockMessage{ctx: context.Background()}},
+			wantAcked: true,
+		},
+		"failure, publish error": {
+			msg:        &rawMessage{mockMessage: mockMessage{ctx: context.Background()}},
+			publishErr: errPublish,
+			wantErr:    "dead letter failed when executing failure strategy: failed to publish dead letter: PUBLISH ERROR",
+			wantNacked: true,
+		},
+		"failure, message without payload": {
+			msg:        &mockMessage{ctx: context.Background()},
+			wantErr:    "dead letter failed when executing failure strategy: message does not expose its payload",
+			wantNacked: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var published *DeadLetter
+			dlp := DeadLetterPublisherFunc(func(_ context.Context, dl *DeadLetter) error {
+				published = dl
+				return tt.publishErr
+			})
+			cnr := mockConsumer{chMsg: make(chan Message, 1), chErr: make(chan error)}
+			proc := mockProcessor{errReturn: true}
+			cmp, err := New("test", &mockConsumerFactory{c: &cnr}, proc.Process).
+				WithFailureStrategy(DeadLetterStrategy).
+				WithDeadLetter(dlp).
+				Create()
+			assert.NoError(t, err)
+
+			cnr.chMsg <- tt.msg
+			ctx, cnl := context.WithCancel(context.Background())
+			chDone := make(chan error, 1)
+			go func() {
+				chDone <- cmp.Run(ctx)
+			}()
+			if tt.wantErr == "" {
+				time.Sleep(20 * time.Millisecond)
+				cnl()
+			}
+			err = <-chDone
+			cnl()
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("payload"), published.Payload)
+				assert.Equal(t, map[string]string{"key": "value"}, published.Headers)
+				assert.Equal(t, errProcess.Error(), published.Error)
+				assert.Equal(t, "orders", published.Source)
+				assert.False(t, published.Timestamp.IsZero())
+			}
+			var m *mockMessage
+			switch msg := tt.msg.(type) {
+			case *rawMessage:
+				m = &msg.mockMessage
+			case *mockMessage:
+				m = msg
+			}
+			assert.Equal(t, tt.wantAcked, m.acked)
+			assert.Equal(t, tt.wantNacked, m.nacked)
+		})
+	}
+}
+
+type rawMessage struct {
+	mockMessage
+}
+
+func (rm *rawMessage) Metadata() Metadata {
+	return Metadata{
+		Source:  "orders",
+		Headers: map[string]string{"key": "value"},
+		Payload: []byte("payload"),
+	}
+}
@@ -0,0 +1,68 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/beatlabs/patron/health"
+)
+
+// ConsumerHealth reported by a consumer which implements the HealthReporter interface.
+type ConsumerHealth struct {
+	// Assigned is false while the consumer has no partitions assigned, e.g. during a rebalance of a Kafka group.
+	Assigned bool
+	// Lag is the number of messages which are waiting to be consumed, e.g. the Kafka offset difference or the SQS queue size.
+	Lag int64
+}
+
+// HealthReporter can be optionally implemented by a consumer in order to report its health, which is part of the health check of the component.
+type HealthReporter interface {
+	Health() ConsumerHealth
+}
+
+// HealthCheck returns a health check of the component, which fails when
+//
+// - the consumer is not consuming, e.g. before the component runs or while a failed consumer is re-created
+// - the consumer reports that it has no partitions assigned
+// - the progress has stalled, i.e. no message has been processed for the stall timeout, while messages are in flight
+// or waiting to be consumed according to the lag reported by the consumer
+//
+// A paused component does not stall. A non-positive stall timeout disables the check of the progress.
+func (c *Component) HealthCheck(stallTimeout time.Duration) health.Check {
+	return func(_ context.Context) error {
+		c.cnsMu.Lock()
+		cns := c.cns
+		c.cnsMu.Unlock()
+		if cns == nil {
+			return errors.New("consumer is not consuming")
+		}
+		var lag int64
+		if hr, ok := cns.(HealthReporter); ok {
+			h := hr.Health()
+			if !h.Assigned {
+				return errors.New("consumer has no partitions assigned")
+			}
+			lag = h.Lag
+		}
+		if stallTimeout <= 0 || c.Paused() {
+			return nil
+		}
+		inFlight := c.InFlight()
+		if inFlight == 0 && lag <= 0 {
+			return nil
+		}
+		idle := time.Since(time.Unix(0, atomic.LoadInt64(&c.progress)))
+		if idle > stallTimeout {
+			return fmt.Errorf("no progress for %v with %d messages in flight and a lag of %d", idle.Truncate(time.Millisecond), inFlight, lag)
+		}
+		return nil
+	}
+}
+
+// markProgress records the time a message has been processed, or the consumer has started consuming.
+func (c *Component) markProgress() {
+	atomic.StoreInt64(&c.progress, time.Now().UnixNano())
+}
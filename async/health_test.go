@@ -0,0 +1,84 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportingConsumer struct {
+	mockConsumer
+	health ConsumerHealth
+}
+
+func (rc *reportingConsumer) Health() ConsumerHealth {
+	return rc.health
+}
+
+func TestComponent_HealthCheck(t *testing.T) {
+	tests := map[string]struct {
+		cns      Consumer
+		paused   bool
+		inFlight int64
+		idle     time.Duration
+		timeout  time.Duration
+		// wantErr is a regular expression, since the idle time of the error varies
+		wantErr string
+	}{
+		"success": {
+			cns: &mockConsumer{}, timeout: time.Second,
+		},
+		"success, idle without messages": {
+			cns: &reportingConsumer{health: ConsumerHealth{Assigned: true}}, idle: time.Minute, timeout: time.Second,
+		},
+		"success, in flight with progress": {
+			cns: &mockConsumer{}, inFlight: 1, idle: 10 * time.Millisecond, timeout: time.Second,
+		},
+		"success, paused with lag": {
+			cns: &reportingConsumer{health: ConsumerHealth{Assigned: true, Lag: 10}}, paused: true, idle: time.Minute, timeout: time.Second,
+		},
+		"success, stall check disabled": {
+			cns: &mockConsumer{}, inFlight: 1, idle: time.Minute,
+		},
+		"failure, not consuming": {
+			timeout: time.Second, wantErr: "consumer is not consuming",
+		},
+		"failure, no partitions assigned": {
+			cns: &reportingConsumer{}, timeout: time.Second, wantErr: "consumer has no partitions assigned",
+		},
+		"failure, stalled in flight": {
+			cns: &mockConsumer{}, inFlight: 1, idle: time.Minute, timeout: time.Second,
+			wantErr: `^no progress for 1m0(\.\d+)?s with 1 messages in flight and a lag of 0$`,
+		},
+		"failure, stalled with lag": {
+			cns: &reportingConsumer{health: ConsumerHealth{Assigned: true, Lag: 10}}, idle: time.Minute, timeout: time.Second,
+			wantErr: `^no progress for 1m0(\.\d+)?s with 0 messages in flight and a lag of 10$`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmp, err := New("test", &mockConsumerFactory{}, func(Message) error { return nil }).Create()
+			require.NoError(t, err)
+			if tt.paused {
+				require.NoError(t, cmp.Pause())
+			}
+			if tt.cns != nil {
+				require.NoError(t, cmp.setConsumer(tt.cns))
+			}
+			atomic.StoreInt64(&cmp.inFlight, tt.inFlight)
+			atomic.StoreInt64(&cmp.progress, time.Now().Add(-tt.idle).UnixNano())
+
+			err = cmp.HealthCheck(tt.timeout)(context.Background())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Regexp(t, tt.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
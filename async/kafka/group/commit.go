@@ -0,0 +1,124 @@
+package group
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/beatlabs/patron/log"
+)
+
+// trackingSession wraps a group session in order to mark the offset of a partition only up to which all of its messages are acked,
+// which is how the offsets are committed with the CommitAfterAck and CommitOnRebalance modes.
+type trackingSession struct {
+	sarama.ConsumerGroupSession
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+	inFlight   int
+	// settled is closed when no message is in flight anymore, and is nil until a message is tracked.
+	settled chan struct{}
+}
+
+// partitionOffsets of the messages of a partition which have been claimed but not marked yet, in the order they are claimed.
+type partitionOffsets struct {
+	offsets []int64
+	acked   map[int64]struct{}
+	nacked  bool
+}
+
+func newTrackingSession(sess sarama.ConsumerGroupSession) *trackingSession {
+	return &trackingSession{ConsumerGroupSession: sess, partitions: make(map[topicPartition]*partitionOffsets)}
+}
+
+// track a claimed message, which is in flight until it is acked or nacked.
+func (s *trackingSession) track(msg *sarama.ConsumerMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight == 0 {
+		s.settled = make(chan struct{})
+	}
+	s.inFlight++
+	p := s.partition(msg)
+	if p.nacked {
+		return
+	}
+	p.offsets = append(p.offsets, msg.Offset)
+}
+
+// MarkMessage acks the message and marks the offset after the last message of the partition, up to which all messages are acked.
+func (s *trackingSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle()
+	p := s.partition(msg)
+	if p.nacked {
+		return
+	}
+	p.acked[msg.Offset] = struct{}{}
+	marked := int64(-1)
+	for len(p.offsets) > 0 {
+		if _, ok := p.acked[p.offsets[0]]; !ok {
+			break
+		}
+		marked = p.offsets[0]
+		delete(p.acked, marked)
+		p.offsets = p.offsets[1:]
+	}
+	if marked >= 0 {
+		s.ConsumerGroupSession.MarkOffset(msg.Topic, msg.Partition, marked+1, metadata)
+	}
+}
+
+// NackMessage stops marking the offsets of the partition of the message, in order for it to be consumed again after the session.
+// The messages of the partition which are acked afterwards in the same session are not marked either.
+func (s *trackingSession) NackMessage(msg *sarama.ConsumerMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settle()
+	p := s.partition(msg)
+	if !p.nacked {
+		log.Warnf("message with offset %d of partition %d of topic %s is nacked, the offsets of the partition are not committed any further until the next session",
+			msg.Offset, msg.Partition, msg.Topic)
+	}
+	p.nacked = true
+	p.offsets = nil
+	p.acked = nil
+}
+
+// wait for the messages in flight to be acked or nacked, returning false if the timeout expires first.
+func (s *trackingSession) wait(timeout time.Duration) bool {
+	s.mu.Lock()
+	settled := s.settled
+	s.mu.Unlock()
+	if settled == nil {
+		return true
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-settled:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (s *trackingSession) settle() {
+	if s.inFlight == 0 {
+		return
+	}
+	s.inFlight--
+	if s.inFlight == 0 {
+		close(s.settled)
+	}
+}
+
+func (s *trackingSession) partition(msg *sarama.ConsumerMessage) *partitionOffsets {
+	tp := topicPartition{topic: msg.Topic, partition: msg.Partition}
+	p, ok := s.partitions[tp]
+	if !ok {
+		p = &partitionOffsets{acked: make(map[int64]struct{})}
+		s.partitions[tp] = p
+	}
+	return p
+}
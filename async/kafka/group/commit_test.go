@@ -0,0 +1,154 @@
+package group
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/async/kafka"
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type markingSession struct {
+	mockConsumerSession
+	marks map[topicPartition][]int64
+}
+
+func newMarkingSession() *markingSession {
+	return &markingSession{marks: make(map[topicPartition][]int64)}
+}
+
+func (m *markingSession) MarkOffset(topic string, partition int32, offset int64, _ string) {
+	tp := topicPartition{topic: topic, partition: partition}
+	m.marks[tp] = append(m.marks[tp], offset)
+}
+
+func consumerMessage(partition int32, offset int64) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{Topic: "TOPIC", Partition: partition, Offset: offset}
+}
+
+func TestTrackingSession_MarkMessage(t *testing.T) {
+	sess := newMarkingSession()
+	ts := newTrackingSession(sess)
+	for _, o := range []int64{3, 4, 6, 7} {
+		ts.track(consumerMessage(0, o))
+	}
+	ts.track(consumerMessage(1, 10))
+
+	ts.MarkMessage(consumerMessage(0, 4), "")
+	assert.Empty(t, sess.marks)
+	ts.MarkMessage(consumerMessage(0, 3), "")
+	ts.MarkMessage(consumerMessage(1, 10), "")
+	ts.MarkMessage(consumerMessage(0, 7), "")
+	ts.MarkMessage(consumerMessage(0, 6), "")
+	assert.Equal(t, map[topicPartition][]int64{
+		{topic: "TOPIC", partition: 0}: {5, 8},
+		{topic: "TOPIC", partition: 1}: {11},
+	}, sess.marks)
+}
+
+func TestTrackingSession_NackMessage(t *testing.T) {
+	sess := newMarkingSession()
+	ts := newTrackingSession(sess)
+	for _, o := range []int64{0, 1, 2} {
+		ts.track(consumerMessage(0, o))
+	}
+	ts.track(consumerMessage(1, 0))
+
+	ts.MarkMessage(consumerMessage(0, 0), "")
+	ts.NackMessage(consumerMessage(0, 1))
+	ts.MarkMessage(consumerMessage(0, 2), "")
+	ts.track(consumerMessage(0, 3))
+	ts.MarkMessage(consumerMessage(0, 3), "")
+	ts.MarkMessage(consumerMessage(1, 0), "")
+
+	// the partition of the nacked message is not marked past it
+	assert.Equal(t, map[topicPartition][]int64{
+		{topic: "TOPIC", partition: 0}: {1},
+		{topic: "TOPIC", partition: 1}: {1},
+	}, sess.marks)
+}
+
+func TestTrackingSession_AcksAfterNack(t *testing.T) {
+	sess := newMarkingSession()
+	ts := newTrackingSession(sess)
+	for _, o := range []int64{0, 1, 2, 3} {
+		ts.track(consumerMessage(0, o))
+	}
+
+	ts.NackMessage(consumerMessage(0, 0))
+	ts.MarkMessage(consumerMessage(0, 1), "")
+	ts.NackMessage(consumerMessage(0, 2))
+	ts.MarkMessage(consumerMessage(0, 3), "")
+
+	// the partition is frozen, although its messages after the nacked one are acked, and nothing is in flight anymore
+	assert.Empty(t, sess.marks)
+	assert.True(t, ts.wait(time.Millisecond))
+	assert.True(t, ts.partitions[topicPartition{topic: "TOPIC", partition: 0}].nacked)
+}
+
+func TestTrackingSession_wait(t *testing.T) {
+	ts := newTrackingSession(newMarkingSession())
+	assert.True(t, ts.wait(time.Millisecond))
+
+	ts.track(consumerMessage(0, 0))
+	ts.track(consumerMessage(0, 1))
+	assert.False(t, ts.wait(10*time.Millisecond))
+	ts.MarkMessage(consumerMessage(0, 0), "")
+	assert.False(t, ts.wait(10*time.Millisecond))
+
+	chDone := make(chan bool)
+	go func() {
+		chDone <- ts.wait(time.Second)
+	}()
+	ts.NackMessage(consumerMessage(0, 1))
+	assert.True(t, <-chDone)
+}
+
+func TestHandler_Commit(t *testing.T) {
+	tests := map[string]struct {
+		mode kafka.CommitMode
+		// outcome of the message, which is either acked, nacked or left in flight
+		outcome   string
+		wantMarks []int64
+	}{
+		"auto, nacked":           {mode: kafka.CommitAuto, outcome: "nack"},
+		"after ack, acked":       {mode: kafka.CommitAfterAck, outcome: "ack", wantMarks: []int64{1}},
+		"after ack, nacked":      {mode: kafka.CommitAfterAck, outcome: "nack"},
+		"on rebalance, acked":    {mode: kafka.CommitOnRebalance, outcome: "ack", wantMarks: []int64{1}},
+		"on rebalance, nacked":   {mode: kafka.CommitOnRebalance, outcome: "nack"},
+		"on rebalance, inflight": {mode: kafka.CommitOnRebalance},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			offsetDiff, err := kafka.TopicPartitionOffsetDiffGauge(nil)
+			require.NoError(t, err)
+			cfg := sarama.NewConfig()
+			cfg.Consumer.Group.Rebalance.Timeout = 50 * time.Millisecond
+			cns := &consumer{offsetDiff: offsetDiff, config: kafka.ConsumerConfig{SaramaConfig: cfg, CommitMode: tt.mode}}
+			chMsg := make(chan async.Message, 1)
+			h := handler{messages: chMsg, consumer: cns}
+			sess := newMarkingSession()
+
+			require.NoError(t, h.Setup(sess))
+			require.NoError(t, h.ConsumeClaim(sess, &mockConsumerClaim{saramaConsumerMessages(json.Type)}))
+			msg := <-chMsg
+			chDone := make(chan error)
+			go func() {
+				chDone <- h.Cleanup(sess)
+			}()
+			switch tt.outcome {
+			case "ack":
+				require.NoError(t, msg.Ack())
+			case "nack":
+				require.NoError(t, msg.Nack())
+			}
+			assert.NoError(t, <-chDone)
+			assert.Equal(t, tt.wantMarks, sess.marks[topicPartition{topic: "TEST_TOPIC"}])
+			assert.Nil(t, cns.session())
+		})
+	}
+}
@@ -4,43 +4,123 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"sync"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/async/kafka"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Factory definition of a consumer factory.
 type Factory struct {
 	name    string
 	group   string
-	topic   string
+	topics  []string
+	pattern *regexp.Regexp
+	refresh time.Duration
 	brokers []string
 	oo      []kafka.OptionFunc
 }
 
-// New constructor.
-func New(name, group, topic string, brokers []string, oo ...kafka.OptionFunc) (*Factory, error) {
+// New constructor of a factory of consumers which consume the topics using the group.
+func New(name, group string, topics, brokers []string, oo ...kafka.OptionFunc) (*Factory, error) {
+	err := validate(name, group, brokers)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(topics) == 0 {
+		return nil, errors.New("topics are required")
+	}
+
+	for _, t := range topics {
+		if t == "" {
+			return nil, errors.New("topic is required")
+		}
+	}
+
+	return &Factory{name: name, group: group, topics: topics, brokers: brokers, oo: oo}, nil
+}
+
+// NewWithPattern constructor of a factory of consumers which consume the topics matching the pattern using the group.
+// The topics are resolved against the metadata of the cluster when consuming starts and then every refresh interval,
+// and the group session is restarted whenever they change.
+func NewWithPattern(name, group, pattern string, refresh time.Duration, brokers []string, oo ...kafka.OptionFunc) (*Factory, error) {
+	err := validate(name, group, brokers)
+	if err != nil {
+		return nil, err
+	}
+
+	if pattern == "" {
+		return nil, errors.New("topic pattern is required")
+	}
+
+	rx, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid topic pattern: %w", err)
+	}
+
+	if refresh <= 0 {
+		return nil, errors.New("topic refresh interval must be positive")
+	}
+
+	return &Factory{name: name, group: group, pattern: rx, refresh: refresh, brokers: brokers, oo: oo}, nil
+}
 
+func validate(name, group string, brokers []string) error {
 	if name == "" {
-		return nil, errors.New("name is required")
+		return errors.New("name is required")
 	}
 
 	if group == "" {
-		return nil, errors.New("group is required")
+		return errors.New("group is required")
 	}
 
 	if len(brokers) == 0 {
-		return nil, errors.New("provide at least one broker")
+		return errors.New("provide at least one broker")
 	}
 
-	if topic == "" {
-		return nil, errors.New("topic is required")
+	return nil
+}
+
+// Info returns information about the consumer configuration.
+func (f *Factory) Info() map[string]interface{} {
+	info := map[string]interface{}{
+		"type":    "kafka-group",
+		"group":   f.group,
+		"brokers": f.brokers,
 	}
+	if f.pattern != nil {
+		info["topic_pattern"] = f.pattern.String()
+		info["topic_refresh"] = f.refresh.String()
+	} else {
+		info["topics"] = f.topics
+	}
+	return info
+}
 
-	return &Factory{name: name, group: group, topic: topic, brokers: brokers, oo: oo}, nil
+// HealthCheck returns a health check of the reachability of the brokers.
+func (f *Factory) HealthCheck() health.Check {
+	return func(ctx context.Context) error {
+		config, err := kafka.DefaultSaramaConfig(f.name)
+		if err != nil {
+			return err
+		}
+		cc := kafka.ConsumerConfig{Brokers: f.brokers, SaramaConfig: config}
+		for _, o := range f.oo {
+			err = o(&cc)
+			if err != nil {
+				return fmt.Errorf("could not apply OptionFunc to consumer : %w", err)
+			}
+		}
+		return kafka.BrokersCheck(cc.Brokers, cc.SaramaConfig)(ctx)
+	}
 }
 
 // Create a new consumer.
@@ -59,7 +139,9 @@ func (f *Factory) Create() (async.Consumer, error) {
 	}
 
 	c := &consumer{
-		topic:    f.topic,
+		pattern:  f.pattern,
+		refresh:  f.refresh,
+		sub:      newSubscription(f.topics),
 		group:    f.group,
 		traceTag: opentracing.Tag{Key: "group", Value: f.group},
 		config:   cc,
@@ -72,17 +154,91 @@ func (f *Factory) Create() (async.Consumer, error) {
 		}
 	}
 
+	c.offsetDiff, err = kafka.TopicPartitionOffsetDiffGauge(c.config.Metrics)
+	if err != nil {
+		return nil, err
+	}
+
 	return c, nil
 }
 
 // consumer members can be injected or overwritten with the usage of OptionFunc arguments.
 type consumer struct {
-	topic    string
-	group    string
-	traceTag opentracing.Tag
-	cnl      context.CancelFunc
-	cg       sarama.ConsumerGroup
-	config   kafka.ConsumerConfig
+	pattern    *regexp.Regexp
+	refresh    time.Duration
+	sub        *subscription
+	group      string
+	traceTag   opentracing.Tag
+	cnl        context.CancelFunc
+	cg         sarama.ConsumerGroup
+	config     kafka.ConsumerConfig
+	offsetDiff *prometheus.GaugeVec
+	gate       async.PauseGate
+	lagsMu     sync.Mutex
+	lags       map[topicPartition]int64
+	sessMu     sync.Mutex
+	sess       *trackingSession
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// Health reports whether the consumer has partitions assigned in the current session, and its lag, which is the sum
+// of the offset differences of the partitions.
+func (c *consumer) Health() async.ConsumerHealth {
+	c.lagsMu.Lock()
+	defer c.lagsMu.Unlock()
+	h := async.ConsumerHealth{Assigned: len(c.lags) > 0}
+	for _, lag := range c.lags {
+		h.Lag += lag
+	}
+	return h
+}
+
+// setLag sets the lag of a partition, which is assigned when the session is set up and unassigned when it is cleaned up.
+func (c *consumer) setLag(topic string, partition int32, lag int64) {
+	c.lagsMu.Lock()
+	defer c.lagsMu.Unlock()
+	if c.lags == nil {
+		c.lags = make(map[topicPartition]int64)
+	}
+	c.lags[topicPartition{topic: topic, partition: partition}] = lag
+}
+
+func (c *consumer) resetLags() {
+	c.lagsMu.Lock()
+	defer c.lagsMu.Unlock()
+	c.lags = nil
+}
+
+// setSession sets the tracking session of the commit modes which track the offsets, returning the previous one.
+func (c *consumer) setSession(ts *trackingSession) *trackingSession {
+	c.sessMu.Lock()
+	defer c.sessMu.Unlock()
+	prev := c.sess
+	c.sess = ts
+	return prev
+}
+
+func (c *consumer) session() *trackingSession {
+	c.sessMu.Lock()
+	defer c.sessMu.Unlock()
+	return c.sess
+}
+
+// Pause stops the consumer from draining the claimed partitions, which stops fetching once the buffers of the claims are full,
+// while the session stays alive in order to avoid a rebalance.
+func (c *consumer) Pause() error {
+	c.gate.Pause()
+	return nil
+}
+
+// Resume resumes draining the claimed partitions.
+func (c *consumer) Resume() error {
+	c.gate.Resume()
+	return nil
 }
 
 // Close handles closing consumer.
@@ -99,7 +255,7 @@ func (c *consumer) Close() error {
 	return nil
 }
 
-// Consume starts consuming messages from a Kafka topic.
+// Consume starts consuming messages from the Kafka topics.
 func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan error, error) {
 	ctx, cnl := context.WithCancel(ctx)
 	c.cnl = cnl
@@ -109,7 +265,18 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 		return nil, nil, fmt.Errorf("failed to create consumer: %w", err)
 	}
 	c.cg = cg
-	log.Infof("consuming messages from topic '%s' using group '%s'", c.topic, c.group)
+
+	if c.pattern != nil {
+		err = c.subscribe(ctx)
+		if err != nil {
+			closeConsumer(c.cg)
+			return nil, nil, err
+		}
+		log.Infof("consuming messages from topics matching '%s' using group '%s'", c.pattern, c.group)
+	} else {
+		topics, _ := c.sub.current()
+		log.Infof("consuming messages from topics %v using group '%s'", topics, c.group)
+	}
 
 	chMsg := make(chan async.Message, c.config.Buffer)
 	chErr := make(chan error, c.config.Buffer)
@@ -129,11 +296,32 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 		}
 	}()
 
-	// Iterate over consumer sessions.
+	// Iterate over consumer sessions, which are restarted when the topics change.
 	go func() {
 		hnd := handler{consumer: c, messages: chMsg}
 		for {
-			err := c.cg.Consume(ctx, []string{c.topic}, hnd)
+			topics, changed := c.sub.current()
+			if len(topics) == 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-changed:
+					continue
+				}
+			}
+			sessCtx, sessCnl := context.WithCancel(ctx)
+			go func() {
+				select {
+				case <-changed:
+					sessCnl()
+				case <-sessCtx.Done():
+				}
+			}()
+			err := c.cg.Consume(sessCtx, topics, hnd)
+			sessCnl()
+			if ctx.Err() != nil {
+				return
+			}
 			if err != nil {
 				chErr <- err
 			}
@@ -143,6 +331,22 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 	return chMsg, chErr, nil
 }
 
+// subscribe resolves the topics matching the pattern and keeps refreshing them with a dedicated client,
+// since the client of the consumer group cannot be shared.
+func (c *consumer) subscribe(ctx context.Context) error {
+	client, err := sarama.NewClient(c.config.Brokers, c.config.SaramaConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create metadata client: %w", err)
+	}
+	err = resolveTopics(client, c.pattern, c.sub)
+	if err != nil {
+		_ = client.Close()
+		return err
+	}
+	go refreshTopics(ctx, client, c.pattern, c.refresh, c.sub)
+	return nil
+}
+
 func closeConsumer(cns sarama.ConsumerGroup) {
 	if cns == nil {
 		return
@@ -158,17 +362,58 @@ type handler struct {
 	messages chan async.Message
 }
 
-func (h handler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
-func (h handler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+func (h handler) Setup(sess sarama.ConsumerGroupSession) error {
+	for topic, pp := range sess.Claims() {
+		for _, p := range pp {
+			h.consumer.setLag(topic, p, 0)
+		}
+	}
+	if h.consumer.config.CommitMode != kafka.CommitAuto {
+		h.consumer.setSession(newTrackingSession(sess))
+	}
+	return nil
+}
+
+func (h handler) Cleanup(_ sarama.ConsumerGroupSession) error {
+	h.consumer.resetLags()
+	ts := h.consumer.setSession(nil)
+	if ts != nil && h.consumer.config.CommitMode == kafka.CommitOnRebalance {
+		timeout := h.consumer.config.SaramaConfig.Consumer.Group.Rebalance.Timeout
+		if !ts.wait(timeout) {
+			log.Warnf("messages still in flight after %v, their offsets are not committed", timeout)
+		}
+	}
+	return nil
+}
+
 func (h handler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
 	ctx := sess.Context()
+	ts := h.consumer.session()
+	if ts != nil {
+		sess = ts
+	}
 	for msg := range claim.Messages() {
-		kafka.TopicPartitionOffsetDiffGaugeSet(h.consumer.group, msg.Topic, msg.Partition, claim.HighWaterMarkOffset(), msg.Offset)
+		if h.consumer.gate.Wait(ctx) != nil {
+			return nil
+		}
+		kafka.TopicPartitionOffsetDiffGaugeSet(h.consumer.offsetDiff, h.consumer.group, msg.Topic, msg.Partition, claim.HighWaterMarkOffset(), msg.Offset)
+		h.consumer.setLag(msg.Topic, msg.Partition, claim.HighWaterMarkOffset()-msg.Offset-1)
 		m, err := kafka.ClaimMessage(ctx, msg, h.consumer.config.DecoderFunc, sess)
 		if err != nil {
 			return err
 		}
-		h.messages <- m
+		if ts != nil {
+			ts.track(msg)
+		}
+		select {
+		case h.messages <- m:
+		case <-ctx.Done():
+			// the message is not delivered, so it is no longer in flight and it is consumed again in the next session
+			if ts != nil {
+				ts.NackMessage(msg)
+			}
+			return nil
+		}
 	}
 	return nil
 }
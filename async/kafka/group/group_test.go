@@ -12,6 +12,7 @@ import (
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -19,46 +20,50 @@ func TestNew(t *testing.T) {
 	type args struct {
 		name    string
 		brokers []string
-		topic   string
+		topics  []string
 		group   string
 		options []kafka.OptionFunc
 	}
 	tests := []struct {
 		name    string
 		args    args
-		wantErr bool
+		wantErr string
 	}{
 		{
 			name:    "fails with missing name",
-			args:    args{name: "", brokers: brokers, topic: "topic1", group: "group1"},
-			wantErr: true,
+			args:    args{name: "", brokers: brokers, topics: []string{"topic1"}, group: "group1"},
+			wantErr: "name is required",
 		},
 		{
 			name:    "fails with missing brokers",
-			args:    args{name: "test", brokers: []string{}, topic: "topic1", group: "group1"},
-			wantErr: true,
+			args:    args{name: "test", brokers: []string{}, topics: []string{"topic1"}, group: "group1"},
+			wantErr: "provide at least one broker",
 		},
 		{
 			name:    "fails with missing topics",
-			args:    args{name: "test", brokers: brokers, topic: "", group: "group1"},
-			wantErr: true,
+			args:    args{name: "test", brokers: brokers, topics: nil, group: "group1"},
+			wantErr: "topics are required",
+		},
+		{
+			name:    "fails with empty topic",
+			args:    args{name: "test", brokers: brokers, topics: []string{"topic1", ""}, group: "group1"},
+			wantErr: "topic is required",
 		},
 		{
 			name:    "fails with missing group",
-			args:    args{name: "test", brokers: brokers, topic: "topic1", group: ""},
-			wantErr: true,
+			args:    args{name: "test", brokers: brokers, topics: []string{"topic1"}, group: ""},
+			wantErr: "group is required",
 		},
 		{
-			name:    "success",
-			args:    args{name: "test", brokers: brokers, topic: "topic1", group: "group1"},
-			wantErr: false,
+			name: "success",
+			args: args{name: "test", brokers: brokers, topics: []string{"topic1", "topic2"}, group: "group1"},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := New(tt.args.name, tt.args.topic, tt.args.group, tt.args.brokers, tt.args.options...)
-			if tt.wantErr {
-				assert.Error(t, err)
+			got, err := New(tt.args.name, tt.args.group, tt.args.topics, tt.args.brokers, tt.args.options...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
 				assert.Nil(t, got)
 			} else {
 				assert.NoError(t, err)
@@ -68,10 +73,44 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithPattern(t *testing.T) {
+	brokers := []string{"192.168.1.1"}
+	tests := map[string]struct {
+		group   string
+		pattern string
+		refresh time.Duration
+		wantErr string
+	}{
+		"success":                  {group: "group1", pattern: "^orders-.*", refresh: time.Minute},
+		"fails with missing group": {group: "", pattern: "^orders-.*", refresh: time.Minute, wantErr: "group is required"},
+		"fails with missing pattern": {
+			group: "group1", pattern: "", refresh: time.Minute, wantErr: "topic pattern is required",
+		},
+		"fails with invalid pattern": {
+			group: "group1", pattern: "orders-(", refresh: time.Minute, wantErr: "invalid topic pattern: error parsing regexp: missing closing ): `orders-(`",
+		},
+		"fails with zero refresh": {
+			group: "group1", pattern: "^orders-.*", refresh: 0, wantErr: "topic refresh interval must be positive",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewWithPattern("test", tt.group, tt.pattern, tt.refresh, brokers)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.pattern, got.pattern.String())
+			}
+		})
+	}
+}
+
 func TestFactory_Create(t *testing.T) {
 	type fields struct {
 		clientName string
-		topic      string
+		topics     []string
 		brokers    []string
 		oo         []kafka.OptionFunc
 	}
@@ -82,15 +121,24 @@ func TestFactory_Create(t *testing.T) {
 		"success": {
 			fields: fields{
 				clientName: "clientA",
-				topic:      "topicA",
+				topics:     []string{"topicA"},
+				brokers:    []string{"192.168.1.1"},
+			},
+			wantErr: false,
+		},
+		"success, with SASL/SCRAM": {
+			fields: fields{
+				clientName: "clientA",
+				topics:     []string{"topicA"},
 				brokers:    []string{"192.168.1.1"},
+				oo:         []kafka.OptionFunc{kafka.SASLSCRAM(sarama.SASLTypeSCRAMSHA512, "user", "password")},
 			},
 			wantErr: false,
 		},
 		"failed with invalid option": {
 			fields: fields{
 				clientName: "clientB",
-				topic:      "topicA",
+				topics:     []string{"topicA"},
 				brokers:    []string{"192.168.1.1"},
 				oo:         []kafka.OptionFunc{kafka.Buffer(-100)},
 			},
@@ -101,7 +149,7 @@ func TestFactory_Create(t *testing.T) {
 		t.Run(testName, func(t *testing.T) {
 			f := &Factory{
 				name:    tt.fields.clientName,
-				topic:   tt.fields.topic,
+				topics:  tt.fields.topics,
 				brokers: tt.fields.brokers,
 				oo:      tt.fields.oo,
 			}
@@ -115,7 +163,8 @@ func TestFactory_Create(t *testing.T) {
 				consumer, ok := got.(*consumer)
 				assert.True(t, ok, "consumer is not of type group.consumer")
 				assert.Equal(t, tt.fields.brokers, consumer.config.Brokers)
-				assert.Equal(t, tt.fields.topic, consumer.topic)
+				topics, _ := consumer.sub.current()
+				assert.Equal(t, tt.fields.topics, topics)
 				assert.True(t, strings.HasSuffix(consumer.config.SaramaConfig.ClientID, tt.fields.clientName))
 			}
 		})
@@ -139,9 +188,12 @@ func (m *mockConsumerClaim) Partition() int32           { return 0 }
 func (m *mockConsumerClaim) InitialOffset() int64       { return 0 }
 func (m *mockConsumerClaim) HighWaterMarkOffset() int64 { return 1 }
 
-type mockConsumerSession struct{}
+type mockConsumerSession struct {
+	ctx    context.Context
+	claims map[string][]int32
+}
 
-func (m *mockConsumerSession) Claims() map[string][]int32 { return nil }
+func (m *mockConsumerSession) Claims() map[string][]int32 { return m.claims }
 func (m *mockConsumerSession) MemberID() string           { return "" }
 func (m *mockConsumerSession) GenerationID() int32        { return 0 }
 func (m *mockConsumerSession) MarkOffset(topic string, partition int32, offset int64, metadata string) {
@@ -149,7 +201,50 @@ func (m *mockConsumerSession) MarkOffset(topic string, partition int32, offset i
 func (m *mockConsumerSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {
 }
 func (m *mockConsumerSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {}
-func (m *mockConsumerSession) Context() context.Context                                 { return nil }
+func (m *mockConsumerSession) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+func TestFactory_HealthCheck(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+
+	f, err := New("name", "group", []string{"topic"}, []string{broker.Addr()})
+	assert.NoError(t, err)
+	assert.NoError(t, f.HealthCheck()(context.Background()))
+
+	f, err = New("name", "group", []string{"topic"}, []string{broker.Addr()}, kafka.Version("invalid"))
+	assert.NoError(t, err)
+	assert.Error(t, f.HealthCheck()(context.Background()))
+}
+
+func TestFactory_Info(t *testing.T) {
+	f, err := New("name", "group", []string{"topic"}, []string{"broker"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type":    "kafka-group",
+		"group":   "group",
+		"topics":  []string{"topic"},
+		"brokers": []string{"broker"},
+	}, f.Info())
+
+	f, err = NewWithPattern("name", "group", "^topic-.*", time.Minute, []string{"broker"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type":          "kafka-group",
+		"group":         "group",
+		"topic_pattern": "^topic-.*",
+		"topic_refresh": "1m0s",
+		"brokers":       []string{"broker"},
+	}, f.Info())
+}
 
 func TestHandler_ConsumeClaim(t *testing.T) {
 
@@ -164,10 +259,13 @@ func TestHandler_ConsumeClaim(t *testing.T) {
 		{"failure content", saramaConsumerMessages(""), "failed to determine content type", true},
 	}
 
+	offsetDiff, err := kafka.TopicPartitionOffsetDiffGauge(nil)
+	assert.NoError(t, err)
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			chMsg := make(chan async.Message, 1)
-			h := handler{messages: chMsg, consumer: &consumer{}}
+			h := handler{messages: chMsg, consumer: &consumer{offsetDiff: offsetDiff}}
 
 			err := h.ConsumeClaim(&mockConsumerSession{}, &mockConsumerClaim{tt.msgs})
 
@@ -216,7 +314,7 @@ func versionedConsumerMessage(value string, header *sarama.RecordHeader, version
 }
 
 func TestConsumer_ConsumeFailedBroker(t *testing.T) {
-	f, err := New("name", "topic", "group", []string{"1", "2"})
+	f, err := New("name", "group", []string{"topic"}, []string{"1", "2"})
 	assert.NoError(t, err)
 	c, err := f.Create()
 	assert.NoError(t, err)
@@ -240,7 +338,7 @@ func TestConsumer_ConsumeWithGroup(t *testing.T) {
 			SetHighWaterMark("TOPIC", 0, 14),
 	})
 
-	f, err := New("name", "TOPIC", "group", []string{broker.Addr()})
+	f, err := New("name", "group", []string{"TOPIC"}, []string{broker.Addr()})
 	assert.NoError(t, err)
 	c, err := f.Create()
 	assert.NoError(t, err)
@@ -256,3 +354,66 @@ func TestConsumer_ConsumeWithGroup(t *testing.T) {
 
 	ctx.Done()
 }
+
+func TestHandler_ConsumeClaim_Paused(t *testing.T) {
+	offsetDiff, err := kafka.TopicPartitionOffsetDiffGauge(nil)
+	require.NoError(t, err)
+	chMsg := make(chan async.Message, 1)
+	cns := &consumer{offsetDiff: offsetDiff}
+	require.NoError(t, cns.Pause())
+	h := handler{messages: chMsg, consumer: cns}
+
+	// the claim is not drained while paused and the session ends
+	ctx, cnl := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cnl()
+	assert.NoError(t, h.ConsumeClaim(&mockConsumerSession{ctx: ctx}, &mockConsumerClaim{saramaConsumerMessages(json.Type)}))
+	assert.Empty(t, chMsg)
+
+	chDone := make(chan error)
+	go func() {
+		chDone <- h.ConsumeClaim(&mockConsumerSession{}, &mockConsumerClaim{saramaConsumerMessages(json.Type)})
+	}()
+	require.NoError(t, cns.Resume())
+	assert.NoError(t, <-chDone)
+	assert.NotNil(t, <-chMsg)
+}
+
+func TestHandler_ConsumeClaim_SessionEnded(t *testing.T) {
+	offsetDiff, err := kafka.TopicPartitionOffsetDiffGauge(nil)
+	require.NoError(t, err)
+	cfg := sarama.NewConfig()
+	cfg.Consumer.Group.Rebalance.Timeout = time.Second
+	cns := &consumer{offsetDiff: offsetDiff, config: kafka.ConsumerConfig{SaramaConfig: cfg, CommitMode: kafka.CommitOnRebalance}}
+	// nobody receives the messages, so the session ends while the message is being delivered
+	h := handler{messages: make(chan async.Message), consumer: cns}
+	ctx, cnl := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cnl()
+	sess := &mockConsumerSession{ctx: ctx}
+
+	require.NoError(t, h.Setup(sess))
+	assert.NoError(t, h.ConsumeClaim(sess, &mockConsumerClaim{saramaConsumerMessages(json.Type)}))
+	// the undelivered message is not in flight, so the cleanup does not wait for it
+	assert.True(t, cns.session().wait(time.Millisecond))
+	assert.NoError(t, h.Cleanup(sess))
+}
+
+func TestHandler_Health(t *testing.T) {
+	offsetDiff, err := kafka.TopicPartitionOffsetDiffGauge(nil)
+	require.NoError(t, err)
+	chMsg := make(chan async.Message, 1)
+	cns := &consumer{offsetDiff: offsetDiff}
+	h := handler{messages: chMsg, consumer: cns}
+	assert.Equal(t, async.ConsumerHealth{}, cns.Health())
+
+	sess := &mockConsumerSession{claims: map[string][]int32{"TOPIC": {0, 1}, "OTHER": {2}}}
+	require.NoError(t, h.Setup(sess))
+	assert.Equal(t, async.ConsumerHealth{Assigned: true}, cns.Health())
+
+	cns.setLag("OTHER", 2, 5)
+	assert.NoError(t, h.ConsumeClaim(sess, &mockConsumerClaim{saramaConsumerMessages(json.Type)}))
+	assert.NotNil(t, <-chMsg)
+	assert.Equal(t, async.ConsumerHealth{Assigned: true, Lag: 5}, cns.Health())
+
+	require.NoError(t, h.Cleanup(sess))
+	assert.Equal(t, async.ConsumerHealth{}, cns.Health())
+}
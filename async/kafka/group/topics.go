@@ -0,0 +1,109 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/beatlabs/patron/log"
+)
+
+// subscription to the topics of a consumer, which are either fixed or resolved from a pattern.
+// The changed channel is closed whenever the topics change, in order for the session to be restarted with the new topics.
+type subscription struct {
+	mu      sync.Mutex
+	topics  []string
+	changed chan struct{}
+}
+
+func newSubscription(topics []string) *subscription {
+	return &subscription{topics: topics, changed: make(chan struct{})}
+}
+
+// current returns the topics along with a channel which is closed when they change.
+func (s *subscription) current() ([]string, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.topics, s.changed
+}
+
+// set replaces the topics, returning whether they have changed.
+func (s *subscription) set(topics []string) bool {
+	sort.Strings(topics)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if equalTopics(s.topics, topics) {
+		return false
+	}
+	s.topics = topics
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return true
+}
+
+func equalTopics(tt1, tt2 []string) bool {
+	if len(tt1) != len(tt2) {
+		return false
+	}
+	for i := range tt1 {
+		if tt1[i] != tt2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataClient is the part of the sarama client which lists the topics of the cluster.
+type metadataClient interface {
+	RefreshMetadata(topics ...string) error
+	Topics() ([]string, error)
+	Close() error
+}
+
+// resolveTopics sets the topics of the subscription to the topics of the cluster which match the pattern.
+func resolveTopics(client metadataClient, pattern *regexp.Regexp, sub *subscription) error {
+	err := client.RefreshMetadata()
+	if err != nil {
+		return fmt.Errorf("failed to refresh metadata: %w", err)
+	}
+	tt, err := client.Topics()
+	if err != nil {
+		return fmt.Errorf("failed to get topics: %w", err)
+	}
+	matched := make([]string, 0, len(tt))
+	for _, t := range tt {
+		if pattern.MatchString(t) {
+			matched = append(matched, t)
+		}
+	}
+	if sub.set(matched) {
+		log.Infof("topics matching '%s' changed to %v", pattern, matched)
+	}
+	return nil
+}
+
+// refreshTopics resolves the topics every interval, until the context is done and the client is closed.
+func refreshTopics(ctx context.Context, client metadataClient, pattern *regexp.Regexp, interval time.Duration, sub *subscription) {
+	defer func() {
+		err := client.Close()
+		if err != nil {
+			log.Errorf("failed to close metadata client: %v", err)
+		}
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := resolveTopics(client, pattern, sub)
+			if err != nil {
+				log.Errorf("failed to resolve topics matching '%s': %v", pattern, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscription(t *testing.T) {
+	sub := newSubscription([]string{"a"})
+	topics, changed := sub.current()
+	assert.Equal(t, []string{"a"}, topics)
+
+	assert.False(t, sub.set([]string{"a"}))
+	select {
+	case <-changed:
+		assert.FailNow(t, "topics have not changed")
+	default:
+	}
+
+	assert.True(t, sub.set([]string{"c", "b"}))
+	<-changed
+	topics, changed = sub.current()
+	assert.Equal(t, []string{"b", "c"}, topics)
+	assert.False(t, sub.set([]string{"b", "c"}))
+	assert.True(t, sub.set(nil))
+	<-changed
+}
+
+type mockMetadataClient struct {
+	sync.Mutex
+	topics     []string
+	refreshErr error
+	topicsErr  error
+	closed     bool
+}
+
+func (m *mockMetadataClient) RefreshMetadata(...string) error {
+	return m.refreshErr
+}
+
+func (m *mockMetadataClient) Topics() ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	return m.topics, m.topicsErr
+}
+
+func (m *mockMetadataClient) Close() error {
+	m.Lock()
+	defer m.Unlock()
+	m.closed = true
+	return nil
+}
+
+func (m *mockMetadataClient) setTopics(tt []string) {
+	m.Lock()
+	defer m.Unlock()
+	m.topics = tt
+}
+
+func (m *mockMetadataClient) isClosed() bool {
+	m.Lock()
+	defer m.Unlock()
+	return m.closed
+}
+
+func TestResolveTopics(t *testing.T) {
+	pattern := regexp.MustCompile("^orders-")
+	tests := map[string]struct {
+		client     *mockMetadataClient
+		wantTopics []string
+		wantErr    string
+	}{
+		"success": {
+			client:     &mockMetadataClient{topics: []string{"payments", "orders-eu", "orders-us", "legacy-orders-eu"}},
+			wantTopics: []string{"orders-eu", "orders-us"},
+		},
+		"success, no matching topics": {
+			client: &mockMetadataClient{topics: []string{"payments"}},
+		},
+		"failure refreshing metadata": {
+			client:  &mockMetadataClient{refreshErr: errors.New("ERROR")},
+			wantErr: "failed to refresh metadata: ERROR",
+		},
+		"failure getting topics": {
+			client:  &mockMetadataClient{topicsErr: errors.New("ERROR")},
+			wantErr: "failed to get topics: ERROR",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sub := newSubscription(nil)
+			err := resolveTopics(tt.client, pattern, sub)
+			topics, _ := sub.current()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, topics)
+			} else {
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, tt.wantTopics, topics)
+			}
+		})
+	}
+}
+
+func TestRefreshTopics(t *testing.T) {
+	client := &mockMetadataClient{topics: []string{"orders-eu"}}
+	sub := newSubscription([]string{"orders-eu"})
+	_, changed := sub.current()
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan struct{})
+	go func() {
+		refreshTopics(ctx, client, regexp.MustCompile("^orders-"), 10*time.Millisecond, sub)
+		close(chDone)
+	}()
+
+	client.setTopics([]string{"orders-eu", "orders-us", "payments"})
+	<-changed
+	topics, _ := sub.current()
+	assert.Equal(t, []string{"orders-eu", "orders-us"}, topics)
+
+	cnl()
+	<-chDone
+	assert.True(t, client.isClosed())
+}
+
+func TestConsumer_ConsumeWithPattern(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("orders-eu", 0, broker.BrokerID()).
+			SetLeader("orders-us", 0, broker.BrokerID()).
+			SetLeader("payments", 0, broker.BrokerID()),
+	})
+
+	f, err := NewWithPattern("name", "group", "^orders-", time.Minute, []string{broker.Addr()})
+	require.NoError(t, err)
+	c, err := f.Create()
+	require.NoError(t, err)
+	_, _, err = c.Consume(context.Background())
+	require.NoError(t, err)
+	topics, _ := c.(*consumer).sub.current()
+	assert.Equal(t, []string{"orders-eu", "orders-us"}, topics)
+	assert.NoError(t, c.Close())
+}
@@ -11,31 +11,35 @@ import (
 	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/beatlabs/patron/trace"
 	"github.com/google/uuid"
 	"github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-var topicPartitionOffsetDiff *prometheus.GaugeVec
-
-// TopicPartitionOffsetDiffGaugeSet creates a new Gauge that measures partition offsets.
-func TopicPartitionOffsetDiffGaugeSet(group, topic string, partition int32, high, offset int64) {
-	topicPartitionOffsetDiff.WithLabelValues(group, topic, strconv.FormatInt(int64(partition), 10)).Set(float64(high - offset))
-}
-
-func init() {
-	topicPartitionOffsetDiff = prometheus.NewGaugeVec(
+// TopicPartitionOffsetDiffGauge returns the gauge which measures the offset difference of the partitions with the high watermark.
+// The default registry of the metrics package is used when the registry is nil.
+func TopicPartitionOffsetDiffGauge(mr *metrics.Registry) (*prometheus.GaugeVec, error) {
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	return mr.GaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "component",
 			Subsystem: "kafka_consumer",
 			Name:      "offset_diff",
 			Help:      "Message offset difference with high watermark, classified by topic and partition",
 		},
-		[]string{"group", "topic", "partition"},
+		"group", "topic", "partition",
 	)
-	prometheus.MustRegister(topicPartitionOffsetDiff)
+}
+
+// TopicPartitionOffsetDiffGaugeSet sets the offset difference of a partition with the high watermark.
+func TopicPartitionOffsetDiffGaugeSet(gv *prometheus.GaugeVec, group, topic string, partition int32, high, offset int64) {
+	gv.WithLabelValues(group, topic, strconv.FormatInt(int64(partition), 10)).Set(float64(high - offset))
 }
 
 // ConsumerConfig is the common configuration of patron kafka consumers.
@@ -44,6 +48,33 @@ type ConsumerConfig struct {
 	Buffer       int
 	DecoderFunc  encoding.DecodeRawFunc
 	SaramaConfig *sarama.Config
+	Metrics      *metrics.Registry
+	CommitMode   CommitMode
+}
+
+// CommitMode defines how the group consumer commits the offsets of the consumed messages.
+type CommitMode int
+
+const (
+	// CommitAuto marks the offset of a message as soon as it is acked, and the marked offsets are committed periodically.
+	// When messages are processed concurrently, the offsets of the messages which are nacked or still in flight can be committed past.
+	CommitAuto CommitMode = iota
+	// CommitAfterAck tracks the offsets of every partition and marks only the offset up to which all messages are acked,
+	// so that a nacked or in-flight message is never committed past and a restart resumes at the first unacknowledged offset.
+	// After one of its messages is nacked, a partition is frozen for the rest of the session: its offsets are not marked any further,
+	// even for the messages which are acked afterwards, and a warning is logged. The partition is consumed again from the nacked message
+	// in the next session, e.g. after a restart or a rebalance, so the messages acked after the nack are redelivered.
+	CommitAfterAck
+	// CommitOnRebalance tracks the offsets like CommitAfterAck, and additionally waits, up to the rebalance timeout,
+	// for the messages in flight to be acked or nacked when the session ends, before the offsets are committed synchronously
+	// and the partitions are released.
+	CommitOnRebalance
+)
+
+// messageNacker can be optionally implemented by the session which claims a message, in order to be notified when the message is nacked,
+// e.g. by the session of the group consumer which tracks the offsets of the partitions.
+type messageNacker interface {
+	NackMessage(msg *sarama.ConsumerMessage)
 }
 
 type message struct {
@@ -75,10 +106,36 @@ func (m *message) Ack() error {
 
 // Nack signals the producing side an erroring condition or inconsistency.
 func (m *message) Nack() error {
+	if n, ok := m.sess.(messageNacker); ok {
+		n.NackMessage(m.msg)
+	}
 	trace.SpanError(m.span)
 	return nil
 }
 
+// Metadata returns the topic, key, partition, offset, timestamp, headers and value of the message.
+func (m *message) Metadata() async.Metadata {
+	return async.Metadata{
+		Source:    m.msg.Topic,
+		Key:       string(m.msg.Key),
+		Partition: m.msg.Partition,
+		Offset:    m.msg.Offset,
+		Timestamp: m.msg.Timestamp,
+		Headers:   mapHeader(m.msg.Headers),
+		Payload:   m.msg.Value,
+	}
+}
+
+// MessageKey returns the key of a Kafka message, in order to be used as the ordering key of the async component.
+// An empty key is returned for any other message.
+func MessageKey(msg async.Message) string {
+	m, ok := msg.(*message)
+	if !ok {
+		return ""
+	}
+	return string(m.msg.Key)
+}
+
 // DefaultSaramaConfig function creates a sarama config object with the default configuration set up.
 func DefaultSaramaConfig(name string) (*sarama.Config, error) {
 
@@ -95,6 +152,29 @@ func DefaultSaramaConfig(name string) (*sarama.Config, error) {
 	return config, nil
 }
 
+// BrokersCheck returns a health check which succeeds when the cluster is reachable through the brokers.
+// The check returns when the context is done, without waiting for the brokers to be reached.
+func BrokersCheck(brokers []string, cfg *sarama.Config) health.Check {
+	return func(ctx context.Context) error {
+		chErr := make(chan error, 1)
+		go func() {
+			client, err := sarama.NewClient(brokers, cfg)
+			if err != nil {
+				chErr <- fmt.Errorf("failed to reach brokers: %w", err)
+				return
+			}
+			chErr <- client.Close()
+		}()
+
+		select {
+		case err := <-chErr:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 // ClaimMessage transforms a sarama.ConsumerMessage to an async.Message.
 func ClaimMessage(ctx context.Context, msg *sarama.ConsumerMessage, d encoding.DecodeRawFunc, sess sarama.ConsumerGroupSession) (async.Message, error) {
 	log.Debugf("data received from topic %s", msg.Topic)
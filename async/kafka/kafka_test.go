@@ -16,8 +16,10 @@ import (
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding"
 	patron_json "github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,6 +29,36 @@ func TestDefaultSaramaConfig(t *testing.T) {
 	assert.True(t, strings.HasSuffix(sc.ClientID, fmt.Sprintf("-%s", "name")))
 }
 
+func TestBrokersCheck(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+	addr := broker.Addr()
+
+	cfg := sarama.NewConfig()
+	cfg.Metadata.Retry.Max = 0
+	check := BrokersCheck([]string{addr}, cfg)
+	assert.NoError(t, check(context.Background()))
+
+	broker.Close()
+	assert.Error(t, check(context.Background()))
+}
+
+func TestBrokersCheck_ContextDone(t *testing.T) {
+	cfg := sarama.NewConfig()
+	cfg.Metadata.Retry.Max = 3
+	cfg.Metadata.Retry.Backoff = time.Second
+	check := BrokersCheck([]string{"127.0.0.1:1"}, cfg)
+
+	ctx, cnl := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cnl()
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, check(ctx))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
 func Test_determineContentType(t *testing.T) {
 	type args struct {
 		hdr []*sarama.RecordHeader
@@ -65,8 +97,15 @@ func Test_message(t *testing.T) {
 	opentracing.SetGlobalTracer(mtr)
 	sp := opentracing.StartSpan("test")
 	ctx := context.Background()
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
 	cm := &sarama.ConsumerMessage{
-		Value: []byte(`{"key":"value"}`),
+		Topic:     "topic",
+		Key:       []byte("order-1"),
+		Partition: 2,
+		Offset:    42,
+		Timestamp: ts,
+		Value:     []byte(`{"key":"value"}`),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("key"), Value: []byte("value")}},
 	}
 	msg := message{
 		sess: nil,
@@ -82,6 +121,22 @@ func Test_message(t *testing.T) {
 	m := make(map[string]string)
 	assert.NoError(t, msg.Decode(&m))
 	assert.Equal(t, "value", m["key"])
+	assert.Equal(t, async.Metadata{
+		Source:    "topic",
+		Key:       "order-1",
+		Partition: 2,
+		Offset:    42,
+		Timestamp: ts,
+		Headers:   map[string]string{"key": "value"},
+		Payload:   cm.Value,
+	}, msg.Metadata())
+}
+
+func TestMessageKey(t *testing.T) {
+	msg := &message{msg: &sarama.ConsumerMessage{Key: []byte("order-1")}}
+	assert.Equal(t, "order-1", MessageKey(msg))
+	assert.Equal(t, "", MessageKey(&message{msg: &sarama.ConsumerMessage{}}))
+	assert.Equal(t, "", MessageKey(nil))
 }
 
 func TestMapHeader(t *testing.T) {
@@ -396,3 +451,18 @@ var process = func(counter *eventCounter, data *decodingTestData) func(message a
 		return nil
 	}
 }
+
+func TestTopicPartitionOffsetDiffGauge(t *testing.T) {
+	mr, err := metrics.NewRegistry()
+	assert.NoError(t, err)
+	gv, err := TopicPartitionOffsetDiffGauge(mr)
+	assert.NoError(t, err)
+	TopicPartitionOffsetDiffGaugeSet(gv, "group", "topic", 1, 10, 7)
+	m := &dto.Metric{}
+	assert.NoError(t, gv.WithLabelValues("group", "topic", "1").Write(m))
+	assert.Equal(t, 3.0, m.GetGauge().GetValue())
+
+	same, err := TopicPartitionOffsetDiffGauge(mr)
+	assert.NoError(t, err)
+	assert.Equal(t, gv, same)
+}
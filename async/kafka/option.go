@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/metrics"
 )
 
 // OptionFunc definition for configuring the consumer in a functional way.
@@ -90,3 +92,74 @@ func DecoderJSON() OptionFunc {
 		return nil
 	}
 }
+
+// Metrics option for setting the registry of the metrics of the consumer,
+// instead of the default registry of the metrics package at the time the consumer is created.
+func Metrics(mr *metrics.Registry) OptionFunc {
+	return func(c *ConsumerConfig) error {
+		if mr == nil {
+			return errors.New("metrics registry is required")
+		}
+		c.Metrics = mr
+		return nil
+	}
+}
+
+// Commit option for setting how the group consumer commits the offsets of the consumed messages. The default is CommitAuto.
+// The option is supported only by the group consumer, so the simple consumer fails to be created with any other mode.
+func Commit(mode CommitMode) OptionFunc {
+	return func(c *ConsumerConfig) error {
+		if mode < CommitAuto || mode > CommitOnRebalance {
+			return errors.New("invalid commit mode")
+		}
+		c.CommitMode = mode
+		return nil
+	}
+}
+
+// TLS option for connecting to the brokers over TLS with the configuration, e.g. created with NewTLSConfig.
+func TLS(cfg *tls.Config) OptionFunc {
+	return func(c *ConsumerConfig) error {
+		if cfg == nil {
+			return errors.New("TLS config is required")
+		}
+		c.SaramaConfig.Net.TLS.Enable = true
+		c.SaramaConfig.Net.TLS.Config = cfg
+		return nil
+	}
+}
+
+// SASLPlain option for authenticating to the brokers with SASL/PLAIN, whose password can be read from a file with ReadSecret.
+// Since the credentials are sent in plain text, it should be combined with the TLS option.
+func SASLPlain(user, password string) OptionFunc {
+	return func(c *ConsumerConfig) error {
+		if user == "" || password == "" {
+			return errors.New("SASL user and password are required")
+		}
+		c.SaramaConfig.Net.SASL.Enable = true
+		c.SaramaConfig.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		c.SaramaConfig.Net.SASL.User = user
+		c.SaramaConfig.Net.SASL.Password = password
+		return nil
+	}
+}
+
+// SASLSCRAM option for authenticating to the brokers with SASL/SCRAM, whose password can be read from a file with ReadSecret.
+// The mechanism is either sarama.SASLTypeSCRAMSHA256 or sarama.SASLTypeSCRAMSHA512.
+func SASLSCRAM(mechanism sarama.SASLMechanism, user, password string) OptionFunc {
+	return func(c *ConsumerConfig) error {
+		if user == "" || password == "" {
+			return errors.New("SASL user and password are required")
+		}
+		gen, err := SCRAMClientGenerator(mechanism)
+		if err != nil {
+			return err
+		}
+		c.SaramaConfig.Net.SASL.Enable = true
+		c.SaramaConfig.Net.SASL.Mechanism = mechanism
+		c.SaramaConfig.Net.SASL.User = user
+		c.SaramaConfig.Net.SASL.Password = password
+		c.SaramaConfig.Net.SASL.SCRAMClientGeneratorFunc = gen
+		return nil
+	}
+}
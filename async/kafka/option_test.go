@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"reflect"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -150,3 +152,62 @@ func TestDecoderJSON(t *testing.T) {
 		reflect.ValueOf(c.DecoderFunc).Pointer(),
 	)
 }
+
+func TestMetrics(t *testing.T) {
+	mr, err := metrics.NewRegistry()
+	assert.NoError(t, err)
+	c := ConsumerConfig{}
+	assert.Error(t, Metrics(nil)(&c))
+	assert.NoError(t, Metrics(mr)(&c))
+	assert.Equal(t, mr, c.Metrics)
+}
+
+func TestCommit(t *testing.T) {
+	c := ConsumerConfig{}
+	assert.EqualError(t, Commit(CommitMode(-1))(&c), "invalid commit mode")
+	assert.EqualError(t, Commit(CommitOnRebalance+1)(&c), "invalid commit mode")
+	assert.NoError(t, Commit(CommitAfterAck)(&c))
+	assert.Equal(t, CommitAfterAck, c.CommitMode)
+}
+
+func TestTLS(t *testing.T) {
+	c := ConsumerConfig{SaramaConfig: sarama.NewConfig()}
+	assert.EqualError(t, TLS(nil)(&c), "TLS config is required")
+	assert.False(t, c.SaramaConfig.Net.TLS.Enable)
+	cfg := &tls.Config{}
+	assert.NoError(t, TLS(cfg)(&c))
+	assert.True(t, c.SaramaConfig.Net.TLS.Enable)
+	assert.Equal(t, cfg, c.SaramaConfig.Net.TLS.Config)
+}
+
+func TestSASLPlain(t *testing.T) {
+	c := ConsumerConfig{SaramaConfig: sarama.NewConfig()}
+	assert.EqualError(t, SASLPlain("", "password")(&c), "SASL user and password are required")
+	assert.EqualError(t, SASLPlain("user", "")(&c), "SASL user and password are required")
+	assert.False(t, c.SaramaConfig.Net.SASL.Enable)
+	assert.NoError(t, SASLPlain("user", "password")(&c))
+	assert.True(t, c.SaramaConfig.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), c.SaramaConfig.Net.SASL.Mechanism)
+	assert.Equal(t, "user", c.SaramaConfig.Net.SASL.User)
+	assert.Equal(t, "password", c.SaramaConfig.Net.SASL.Password)
+	assert.NoError(t, c.SaramaConfig.Validate())
+}
+
+func TestSASLSCRAM(t *testing.T) {
+	c := ConsumerConfig{SaramaConfig: sarama.NewConfig()}
+	assert.EqualError(t, SASLSCRAM(sarama.SASLTypeSCRAMSHA256, "", "password")(&c), "SASL user and password are required")
+	assert.EqualError(t, SASLSCRAM(sarama.SASLTypeSCRAMSHA256, "user", "")(&c), "SASL user and password are required")
+	assert.EqualError(t, SASLSCRAM(sarama.SASLTypePlaintext, "user", "password")(&c), `invalid SASL/SCRAM mechanism "PLAIN"`)
+	assert.False(t, c.SaramaConfig.Net.SASL.Enable)
+
+	for _, mechanism := range []sarama.SASLMechanism{sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512} {
+		c := ConsumerConfig{SaramaConfig: sarama.NewConfig()}
+		assert.NoError(t, SASLSCRAM(mechanism, "user", "password")(&c))
+		assert.True(t, c.SaramaConfig.Net.SASL.Enable)
+		assert.Equal(t, mechanism, c.SaramaConfig.Net.SASL.Mechanism)
+		assert.Equal(t, "user", c.SaramaConfig.Net.SASL.User)
+		assert.Equal(t, "password", c.SaramaConfig.Net.SASL.Password)
+		assert.NotNil(t, c.SaramaConfig.Net.SASL.SCRAMClientGeneratorFunc)
+		assert.NoError(t, c.SaramaConfig.Validate())
+	}
+}
@@ -0,0 +1,111 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg/scram"
+)
+
+// NewTLSConfig creates the TLS configuration of the connections to the brokers from PEM encoded files, which is used
+// by the TLS option of the consumers and the WithTLS method of the producer builder.
+// The CA certificate verifies the certificates of the brokers, instead of the system certificates, and the client certificate
+// and key authenticate the client to the brokers. Every file is optional, but the client certificate and key go together.
+// Skipping the verification of the certificates of the brokers should only be used in development.
+func NewTLSConfig(caFile, certFile, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no CA certificate found in %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client certificate and key are required together")
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate and key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// ReadSecret reads a secret from a file, e.g. the SASL password of a mounted Kubernetes secret, trimming the trailing new lines and spaces.
+func ReadSecret(file string) (string, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	secret := strings.TrimRight(string(b), "\r\n ")
+	if secret == "" {
+		return "", fmt.Errorf("secret in %s is empty", file)
+	}
+	return secret, nil
+}
+
+// SCRAMClientGenerator returns the generator of the clients of the SASL/SCRAM authentication to the brokers, which is used
+// by the SASLSCRAM option of the consumers and the WithSASLSCRAM method of the producer builder.
+// The mechanism is either sarama.SASLTypeSCRAMSHA256 or sarama.SASLTypeSCRAMSHA512.
+func SCRAMClientGenerator(mechanism sarama.SASLMechanism) (func() sarama.SCRAMClient, error) {
+	var hgf scram.HashGeneratorFcn
+	switch mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		hgf = func() hash.Hash { return sha256.New() }
+	case sarama.SASLTypeSCRAMSHA512:
+		hgf = func() hash.Hash { return sha512.New() }
+	default:
+		return nil, fmt.Errorf("invalid SASL/SCRAM mechanism %q", mechanism)
+	}
+	return func() sarama.SCRAMClient {
+		return &scramClient{hgf: hgf}
+	}, nil
+}
+
+// scramClient performs the SASL/SCRAM exchange of a connection to a broker.
+type scramClient struct {
+	hgf  scram.HashGeneratorFcn
+	conv *scram.ClientConversation
+}
+
+// Begin prepares the client for the exchange with the credentials.
+func (c *scramClient) Begin(user, password, authzID string) error {
+	client, err := c.hgf.NewClient(user, password, authzID)
+	if err != nil {
+		return fmt.Errorf("failed to create SCRAM client: %w", err)
+	}
+	c.conv = client.NewConversation()
+	return nil
+}
+
+// Step returns the response of the client to the challenge of the broker.
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conv.Step(challenge)
+}
+
+// Done returns true when the exchange is complete.
+func (c *scramClient) Done() bool {
+	return c.conv.Done()
+}
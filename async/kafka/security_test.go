@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"hash"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xdg/scram"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := tempDir(t)
+	certFile, keyFile := writeCertificate(t, dir)
+	invalidFile := writeFile(t, dir, "invalid.pem", "INVALID")
+	missingFile := filepath.Join(dir, "missing.pem")
+
+	tests := map[string]struct {
+		caFile, certFile, keyFile string
+		skipVerify                bool
+		wantErr                   string
+	}{
+		"success, no files":                   {},
+		"success, skip verify":                {skipVerify: true},
+		"success, CA":                         {caFile: certFile},
+		"success, CA and client certificate":  {caFile: certFile, certFile: certFile, keyFile: keyFile},
+		"failure, missing CA":                 {caFile: missingFile, wantErr: "failed to read CA certificate"},
+		"failure, invalid CA":                 {caFile: invalidFile, wantErr: "no CA certificate found in " + invalidFile},
+		"failure, client certificate only":    {certFile: certFile, wantErr: "client certificate and key are required together"},
+		"failure, client key only":            {keyFile: keyFile, wantErr: "client certificate and key are required together"},
+		"failure, invalid client certificate": {certFile: invalidFile, keyFile: keyFile, wantErr: "failed to load client certificate and key"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewTLSConfig(tt.caFile, tt.certFile, tt.keyFile, tt.skipVerify)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uint16(tls.VersionTLS12), got.MinVersion)
+			assert.Equal(t, tt.skipVerify, got.InsecureSkipVerify)
+			assert.Equal(t, tt.caFile != "", got.RootCAs != nil)
+			if tt.certFile != "" {
+				assert.Len(t, got.Certificates, 1)
+			} else {
+				assert.Empty(t, got.Certificates)
+			}
+		})
+	}
+}
+
+func TestReadSecret(t *testing.T) {
+	dir := tempDir(t)
+	secretFile := writeFile(t, dir, "password", "s3cr3t \n")
+	emptyFile := writeFile(t, dir, "empty", "\n")
+
+	got, err := ReadSecret(secretFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", got)
+
+	_, err = ReadSecret(emptyFile)
+	assert.EqualError(t, err, "secret in "+emptyFile+" is empty")
+
+	_, err = ReadSecret(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read secret")
+}
+
+func TestSCRAMClientGenerator(t *testing.T) {
+	tests := map[string]struct {
+		mechanism sarama.SASLMechanism
+		hgf       scram.HashGeneratorFcn
+		wantErr   string
+	}{
+		"success, SHA-256":   {mechanism: sarama.SASLTypeSCRAMSHA256, hgf: scram.SHA256},
+		"success, SHA-512":   {mechanism: sarama.SASLTypeSCRAMSHA512, hgf: func() hash.Hash { return sha512.New() }},
+		"failure, mechanism": {mechanism: sarama.SASLTypeOAuth, wantErr: `invalid SASL/SCRAM mechanism "OAUTHBEARER"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gen, err := SCRAMClientGenerator(tt.mechanism)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, gen)
+				return
+			}
+			require.NoError(t, err)
+
+			// the exchange of the client with a server, which knows the credentials of the user
+			cl, err := tt.hgf.NewClient("user", "password", "")
+			require.NoError(t, err)
+			creds := cl.GetStoredCredentials(scram.KeyFactors{Salt: "salt", Iters: 4096})
+			srv, err := tt.hgf.NewServer(func(string) (scram.StoredCredentials, error) { return creds, nil })
+			require.NoError(t, err)
+			srvConv := srv.NewConversation()
+
+			c := gen()
+			require.NoError(t, c.Begin("user", "password", ""))
+			msg, err := c.Step("")
+			require.NoError(t, err)
+			for !c.Done() {
+				challenge, err := srvConv.Step(msg)
+				require.NoError(t, err)
+				msg, err = c.Step(challenge)
+				require.NoError(t, err)
+			}
+			assert.True(t, srvConv.Valid())
+		})
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "kafka")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, data string) string {
+	file := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(file, []byte(data), 0600))
+	return file
+}
+
+// writeCertificate writes a self-signed certificate, which can be used both as a CA and as a client certificate, along with its key.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "patron"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := writeFile(t, dir, "cert.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	keyFile := writeFile(t, dir, "key.pem", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})))
+	return certFile, keyFile
+}
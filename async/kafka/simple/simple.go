@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/Shopify/sarama"
 	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/async/kafka"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Factory definition of a consumer factory.
@@ -37,6 +40,33 @@ func New(name, topic string, brokers []string, oo ...kafka.OptionFunc) (*Factory
 	return &Factory{name: name, topic: topic, brokers: brokers, oo: oo}, nil
 }
 
+// Info returns information about the consumer configuration.
+func (f *Factory) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"type":    "kafka-simple",
+		"topic":   f.topic,
+		"brokers": f.brokers,
+	}
+}
+
+// HealthCheck returns a health check of the reachability of the brokers.
+func (f *Factory) HealthCheck() health.Check {
+	return func(ctx context.Context) error {
+		config, err := kafka.DefaultSaramaConfig(f.name)
+		if err != nil {
+			return err
+		}
+		cc := kafka.ConsumerConfig{Brokers: f.brokers, SaramaConfig: config}
+		for _, o := range f.oo {
+			err = o(&cc)
+			if err != nil {
+				return fmt.Errorf("could not apply OptionFunc to consumer : %w", err)
+			}
+		}
+		return kafka.BrokersCheck(cc.Brokers, cc.SaramaConfig)(ctx)
+	}
+}
+
 // Create a new consumer.
 func (f *Factory) Create() (async.Consumer, error) {
 
@@ -62,16 +92,61 @@ func (f *Factory) Create() (async.Consumer, error) {
 			return nil, err
 		}
 	}
+	if c.config.CommitMode != kafka.CommitAuto {
+		return nil, errors.New("commit mode is supported only by the group consumer, since the simple consumer does not commit offsets")
+	}
+
+	c.offsetDiff, err = kafka.TopicPartitionOffsetDiffGauge(c.config.Metrics)
+	if err != nil {
+		return nil, err
+	}
 
 	return c, nil
 }
 
 // consumer members can be injected or overwritten with the usage of OptionFunc arguments.
 type consumer struct {
-	topic  string
-	cnl    context.CancelFunc
-	ms     sarama.Consumer
-	config kafka.ConsumerConfig
+	topic      string
+	cnl        context.CancelFunc
+	ms         sarama.Consumer
+	config     kafka.ConsumerConfig
+	offsetDiff *prometheus.GaugeVec
+	gate       async.PauseGate
+	lagsMu     sync.Mutex
+	lags       map[int32]int64
+}
+
+// Health reports whether the consumer consumes any partition, and its lag, which is the sum of the offset differences
+// of the partitions.
+func (c *consumer) Health() async.ConsumerHealth {
+	c.lagsMu.Lock()
+	defer c.lagsMu.Unlock()
+	h := async.ConsumerHealth{Assigned: len(c.lags) > 0}
+	for _, lag := range c.lags {
+		h.Lag += lag
+	}
+	return h
+}
+
+func (c *consumer) setLag(partition int32, lag int64) {
+	c.lagsMu.Lock()
+	defer c.lagsMu.Unlock()
+	if c.lags == nil {
+		c.lags = make(map[int32]int64)
+	}
+	c.lags[partition] = lag
+}
+
+// Pause stops the consumer from draining the partitions, which stops fetching once the buffers of the partitions are full.
+func (c *consumer) Pause() error {
+	c.gate.Pause()
+	return nil
+}
+
+// Resume resumes draining the partitions.
+func (c *consumer) Resume() error {
+	c.gate.Resume()
+	return nil
 }
 
 // Close handles closing consumer.
@@ -104,6 +179,11 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 	for _, pc := range pcs {
 		go func(consumer sarama.PartitionConsumer) {
 			for {
+				if c.gate.Wait(ctx) != nil {
+					log.Info("canceling consuming messages requested")
+					closePartitionConsumer(consumer)
+					return
+				}
 				select {
 				case <-ctx.Done():
 					log.Info("canceling consuming messages requested")
@@ -114,7 +194,8 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 					chErr <- consumerError
 					return
 				case m := <-consumer.Messages():
-					kafka.TopicPartitionOffsetDiffGaugeSet("", m.Topic, m.Partition, consumer.HighWaterMarkOffset(), m.Offset)
+					kafka.TopicPartitionOffsetDiffGaugeSet(c.offsetDiff, "", m.Topic, m.Partition, consumer.HighWaterMarkOffset(), m.Offset)
+					c.setLag(m.Partition, consumer.HighWaterMarkOffset()-m.Offset-1)
 
 					go func(message *sarama.ConsumerMessage) {
 						msg, err := kafka.ClaimMessage(ctx, message, c.config.DecoderFunc, nil)
@@ -154,6 +235,7 @@ func (c *consumer) partitions() ([]sarama.PartitionConsumer, error) {
 			return nil, fmt.Errorf("failed to get partition consumer: %w", err)
 		}
 		pcs[i] = pc
+		c.setLag(partition, 0)
 	}
 
 	return pcs, nil
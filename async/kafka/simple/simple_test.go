@@ -3,11 +3,13 @@ package simple
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/async/kafka"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const fooTopic = "foo_topic"
@@ -70,7 +72,10 @@ func TestFactory_Create(t *testing.T) {
 		wantErr bool
 	}{
 		{name: "success", wantErr: false},
+		{name: "success, with SASL/SCRAM", fields: fields{oo: []kafka.OptionFunc{kafka.SASLSCRAM(sarama.SASLTypeSCRAMSHA256, "user", "password")}}, wantErr: false},
 		{name: "failed with invalid option", fields: fields{oo: []kafka.OptionFunc{kafka.Buffer(-100)}}, wantErr: true},
+		{name: "failed with commit mode", fields: fields{oo: []kafka.OptionFunc{kafka.Commit(kafka.CommitAfterAck)}}, wantErr: true},
+		{name: "failed with invalid SASL/SCRAM mechanism", fields: fields{oo: []kafka.OptionFunc{kafka.SASLSCRAM(sarama.SASLTypePlaintext, "user", "password")}}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -122,6 +127,33 @@ func consume(t *testing.T, f *Factory) (context.Context, async.Consumer, <-chan
 	return ctx, c, chMsg, chErr
 }
 
+func TestFactory_HealthCheck(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 0)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()),
+	})
+
+	f, err := New("name", "topic", []string{broker.Addr()})
+	assert.NoError(t, err)
+	assert.NoError(t, f.HealthCheck()(context.Background()))
+
+	f, err = New("name", "topic", []string{broker.Addr()}, kafka.Version("invalid"))
+	assert.NoError(t, err)
+	assert.Error(t, f.HealthCheck()(context.Background()))
+}
+
+func TestFactory_Info(t *testing.T) {
+	f, err := New("name", "topic", []string{"broker"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type":    "kafka-simple",
+		"topic":   "topic",
+		"brokers": []string{"broker"},
+	}, f.Info())
+}
+
 func TestConsumer_ConsumeFromOldest(t *testing.T) {
 	broker := newBroker(t, fooTopic)
 
@@ -147,6 +179,39 @@ func TestConsumer_ConsumeFromOldest(t *testing.T) {
 	ctx.Done()
 }
 
+func TestConsumer_PauseResume(t *testing.T) {
+	broker := newBroker(t, fooTopic)
+	defer broker.Close()
+
+	f, err := New("name", fooTopic, []string{broker.Addr()}, kafka.DecoderJSON(), kafka.Version(sarama.V2_1_0_0.String()), kafka.StartFromNewest())
+	require.NoError(t, err)
+	c, err := f.Create()
+	require.NoError(t, err)
+	p, ok := c.(async.PausableConsumer)
+	require.True(t, ok)
+	hr, ok := c.(async.HealthReporter)
+	require.True(t, ok)
+	assert.False(t, hr.Health().Assigned)
+	require.NoError(t, p.Pause())
+	chMsg, _, err := c.Consume(context.Background())
+	require.NoError(t, err)
+	assert.True(t, hr.Health().Assigned)
+
+	select {
+	case <-chMsg:
+		assert.FailNow(t, "message consumed while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, p.Resume())
+	select {
+	case msg := <-chMsg:
+		assert.NotNil(t, msg)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "message not consumed after resuming")
+	}
+	assert.NoError(t, c.Close())
+}
+
 func TestConsumer_ClaimMessageError(t *testing.T) {
 	broker := newBroker(t, fooTopic)
 
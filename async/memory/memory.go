@@ -0,0 +1,364 @@
+// Package memory provides an in-memory consumer, which is used for testing async components
+// by pushing messages to it and asserting which of them were acked or nacked.
+package memory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/correlation"
+	"github.com/beatlabs/patron/encoding"
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/trace"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Outcome of a consumed message.
+type Outcome string
+
+const (
+	// Acked outcome of a message which has been acknowledged.
+	Acked Outcome = "ack"
+	// Nacked outcome of a message which has not been acknowledged.
+	Nacked Outcome = "nack"
+)
+
+// Result of a consumed message, which is recorded every time the message is acked or nacked.
+type Result struct {
+	ID      string
+	Outcome Outcome
+}
+
+// Message which is pushed to the consumer.
+type Message struct {
+	id      string
+	key     string
+	payload []byte
+	headers map[string]string
+}
+
+// NewMessage creates a new message with a payload, which is decoded as JSON unless a content type is provided.
+func NewMessage(payload []byte) *Message {
+	return &Message{payload: payload, headers: make(map[string]string)}
+}
+
+// WithID sets the ID of the message, instead of the sequence number of the message in the consumer.
+func (m *Message) WithID(id string) *Message {
+	m.id = id
+	return m
+}
+
+// WithKey sets the key of the message.
+func (m *Message) WithKey(key string) *Message {
+	m.key = key
+	return m
+}
+
+// WithHeader sets a header of the message.
+func (m *Message) WithHeader(key, value string) *Message {
+	m.headers[key] = value
+	return m
+}
+
+// WithContentType sets the content type header of the message, which determines its decoder.
+func (m *Message) WithContentType(ct string) *Message {
+	return m.WithHeader(encoding.ContentTypeHeader, ct)
+}
+
+type item struct {
+	msg       *Message
+	err       error
+	timestamp time.Time
+}
+
+// Factory of in-memory consumers, which share the queue of the pushed messages and the results,
+// so that they survive the consumer being re-created by the component.
+type Factory struct {
+	sync.Mutex
+	source  string
+	seq     int
+	queue   []item
+	notify  chan struct{}
+	results []Result
+	changed chan struct{}
+}
+
+// New creates a new in-memory consumer factory.
+func New(oo ...OptionFunc) (*Factory, error) {
+	f := &Factory{
+		source:  "memory",
+		notify:  make(chan struct{}, 1),
+		changed: make(chan struct{}),
+	}
+	for _, o := range oo {
+		err := o(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Create a new consumer.
+func (f *Factory) Create() (async.Consumer, error) {
+	return &consumer{f: f}, nil
+}
+
+// Push queues the messages to be consumed in order, assigning their sequence number as ID when it is not set.
+func (f *Factory) Push(mm ...*Message) error {
+	for _, m := range mm {
+		if m == nil {
+			return errors.New("message is nil")
+		}
+	}
+	f.Lock()
+	for _, m := range mm {
+		f.seq++
+		if m.id == "" {
+			m.id = strconv.Itoa(f.seq)
+		}
+		f.queue = append(f.queue, item{msg: m, timestamp: time.Now().UTC()})
+	}
+	f.Unlock()
+	f.signal()
+	return nil
+}
+
+// PushError queues an error, which is returned by the consumer when it is reached, in order to simulate a consumer failure.
+func (f *Factory) PushError(err error) error {
+	if err == nil {
+		return errors.New("error is nil")
+	}
+	f.Lock()
+	f.queue = append(f.queue, item{err: err})
+	f.Unlock()
+	f.signal()
+	return nil
+}
+
+// Pending returns the number of queued messages and errors which have not been consumed yet.
+func (f *Factory) Pending() int {
+	f.Lock()
+	defer f.Unlock()
+	return len(f.queue)
+}
+
+// Results returns the results of the consumed messages, in the order they were acked or nacked.
+func (f *Factory) Results() []Result {
+	f.Lock()
+	defer f.Unlock()
+	rr := make([]Result, len(f.results))
+	copy(rr, f.results)
+	return rr
+}
+
+// Acked returns the IDs of the acked messages, in the order they were acked.
+func (f *Factory) Acked() []string {
+	return f.ids(Acked)
+}
+
+// Nacked returns the IDs of the nacked messages, in the order they were nacked.
+func (f *Factory) Nacked() []string {
+	return f.ids(Nacked)
+}
+
+// Wait blocks until at least n results have been recorded or the context is done.
+func (f *Factory) Wait(ctx context.Context, n int) error {
+	for {
+		f.Lock()
+		count := len(f.results)
+		changed := f.changed
+		f.Unlock()
+		if count >= n {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (f *Factory) ids(o Outcome) []string {
+	f.Lock()
+	defer f.Unlock()
+	var ids []string
+	for _, r := range f.results {
+		if r.Outcome == o {
+			ids = append(ids, r.ID)
+		}
+	}
+	return ids
+}
+
+func (f *Factory) record(id string, o Outcome) {
+	f.Lock()
+	defer f.Unlock()
+	f.results = append(f.results, Result{ID: id, Outcome: o})
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *Factory) signal() {
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (f *Factory) pop() (item, bool) {
+	f.Lock()
+	defer f.Unlock()
+	if len(f.queue) == 0 {
+		return item{}, false
+	}
+	it := f.queue[0]
+	f.queue = f.queue[1:]
+	return it, true
+}
+
+// unpop puts back an item which could not be delivered, in order to be consumed first.
+func (f *Factory) unpop(it item) {
+	f.Lock()
+	f.queue = append([]item{it}, f.queue...)
+	f.Unlock()
+	f.signal()
+}
+
+type consumer struct {
+	f *Factory
+}
+
+// Consume delivers the queued messages one at a time, so that a message which has not been delivered when the context is cancelled
+// remains queued for the next consumer.
+func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan error, error) {
+	chMsg := make(chan async.Message)
+	chErr := make(chan error)
+
+	go func() {
+		for {
+			it, ok := c.f.pop()
+			if !ok {
+				select {
+				case <-ctx.Done():
+					log.Info("canceling consuming messages requested")
+					return
+				case <-c.f.notify:
+					continue
+				}
+			}
+
+			if it.err != nil {
+				select {
+				case chErr <- it.err:
+				case <-ctx.Done():
+					c.f.unpop(it)
+				}
+				return
+			}
+
+			msg, err := c.message(ctx, it)
+			if err != nil {
+				select {
+				case chErr <- err:
+				case <-ctx.Done():
+				}
+				return
+			}
+			select {
+			case chMsg <- msg:
+			case <-ctx.Done():
+				msg.span.Finish()
+				c.f.unpop(it)
+				return
+			}
+		}
+	}()
+
+	return chMsg, chErr, nil
+}
+
+// message creates the consumed message, which is nacked if its decoder cannot be determined.
+func (c *consumer) message(ctx context.Context, it item) (*message, error) {
+	corID := it.msg.headers[correlation.HeaderID]
+	if corID == "" {
+		corID = uuid.New().String()
+	}
+	sp, ctxCh := trace.ConsumerSpan(ctx, trace.ComponentOpName(trace.MemoryConsumerComponent, c.f.source),
+		trace.MemoryConsumerComponent, corID, it.msg.headers)
+	ctxCh = correlation.ContextWithID(ctxCh, corID)
+	ctxCh = log.WithContext(ctxCh, log.Sub(map[string]interface{}{"correlationID": corID}))
+
+	msg := &message{f: c.f, it: it, ctx: ctxCh, span: sp}
+	ct, ok := it.msg.headers[encoding.ContentTypeHeader]
+	if !ok {
+		ct = json.Type
+	}
+	dec, err := async.DetermineDecoder(ct)
+	if err != nil {
+		_ = msg.Nack()
+		return nil, err
+	}
+	msg.dec = dec
+	return msg, nil
+}
+
+// Close the consumer.
+func (c *consumer) Close() error {
+	return nil
+}
+
+type message struct {
+	f    *Factory
+	it   item
+	ctx  context.Context
+	span opentracing.Span
+	dec  encoding.DecodeRawFunc
+}
+
+// Context of the message.
+func (m *message) Context() context.Context {
+	return m.ctx
+}
+
+// Decode the payload of the message to the provided argument.
+func (m *message) Decode(v interface{}) error {
+	return m.dec(m.it.msg.payload, v)
+}
+
+// Ack records the message as acked.
+func (m *message) Ack() error {
+	m.f.record(m.it.msg.id, Acked)
+	trace.SpanSuccess(m.span)
+	return nil
+}
+
+// Nack records the message as nacked.
+func (m *message) Nack() error {
+	m.f.record(m.it.msg.id, Nacked)
+	trace.SpanError(m.span)
+	return nil
+}
+
+// Metadata returns the source, ID, key, push timestamp, headers and payload of the message.
+func (m *message) Metadata() async.Metadata {
+	hh := make(map[string]string, len(m.it.msg.headers))
+	for k, v := range m.it.msg.headers {
+		hh[k] = v
+	}
+	return async.Metadata{
+		Source:    m.f.source,
+		ID:        m.it.msg.id,
+		Key:       m.it.msg.key,
+		Timestamp: m.it.timestamp,
+		Headers:   hh,
+		Payload:   m.it.msg.payload,
+	}
+}
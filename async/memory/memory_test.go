@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/correlation"
+	"github.com/beatlabs/patron/trace"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	f, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, "memory", f.source)
+	f, err = New(Source("orders"))
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", f.source)
+	f, err = New(Source(""))
+	assert.EqualError(t, err, "source is required")
+	assert.Nil(t, f)
+}
+
+func TestFactory_Push(t *testing.T) {
+	f, err := New()
+	require.NoError(t, err)
+	assert.EqualError(t, f.Push(NewMessage(nil), nil), "message is nil")
+	assert.EqualError(t, f.PushError(nil), "error is nil")
+	assert.Equal(t, 0, f.Pending())
+
+	assert.NoError(t, f.Push(NewMessage(nil), NewMessage(nil).WithID("custom"), NewMessage(nil)))
+	assert.NoError(t, f.PushError(errors.New("CONSUMER ERROR")))
+	assert.Equal(t, 4, f.Pending())
+	assert.Equal(t, "1", f.queue[0].msg.id)
+	assert.Equal(t, "custom", f.queue[1].msg.id)
+	assert.Equal(t, "3", f.queue[2].msg.id)
+}
+
+func TestConsumer_Consume(t *testing.T) {
+	mtr := mocktracer.New()
+	opentracing.SetGlobalTracer(mtr)
+	defer opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+
+	f, err := New(Source("orders"))
+	require.NoError(t, err)
+	require.NoError(t, f.Push(
+		NewMessage([]byte(`{"key":"value"}`)).WithKey("order-1").WithHeader(correlation.HeaderID, "123"),
+		NewMessage([]byte(`{}`)),
+		NewMessage([]byte(`{}`)),
+	))
+	cns, err := f.Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chMsg, chErr, err := cns.Consume(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, chErr)
+
+	msg := <-chMsg
+	var got map[string]string
+	assert.NoError(t, msg.Decode(&got))
+	assert.Equal(t, map[string]string{"key": "value"}, got)
+	assert.Equal(t, "123", correlation.IDFromContext(msg.Context()))
+	md := msg.Metadata()
+	assert.Equal(t, "orders", md.Source)
+	assert.Equal(t, "1", md.ID)
+	assert.Equal(t, "order-1", md.Key)
+	assert.Equal(t, map[string]string{correlation.HeaderID: "123"}, md.Headers)
+	assert.Equal(t, []byte(`{"key":"value"}`), md.Payload)
+	assert.False(t, md.Timestamp.IsZero())
+	assert.NoError(t, msg.Ack())
+
+	sp := mtr.FinishedSpans()
+	require.Len(t, sp, 1)
+	assert.Equal(t, "memory-consumer orders", sp[0].OperationName)
+	assert.Equal(t, trace.MemoryConsumerComponent, sp[0].Tag(string(ext.Component)))
+
+	// the next message is waiting to be delivered and is queued back on cancellation
+	cnl()
+	waitPending(t, f, 2)
+	assert.NoError(t, cns.Close())
+	assert.Equal(t, []Result{{ID: "1", Outcome: Acked}}, f.Results())
+}
+
+func TestConsumer_Consume_UnsupportedContentType(t *testing.T) {
+	f, err := New()
+	require.NoError(t, err)
+	require.NoError(t, f.Push(NewMessage([]byte("<xml/>")).WithContentType("application/xml")))
+	cns, err := f.Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	defer cnl()
+	_, chErr, err := cns.Consume(ctx)
+	require.NoError(t, err)
+	assert.EqualError(t, <-chErr, "content header application/xml is unsupported")
+	assert.Equal(t, []string{"1"}, f.Nacked())
+}
+
+func TestComponent(t *testing.T) {
+	errProcess := errors.New("PROC ERROR")
+	f, err := New()
+	require.NoError(t, err)
+	require.NoError(t, f.Push(
+		NewMessage([]byte(`"first"`)),
+		NewMessage([]byte(`"fail"`)),
+		NewMessage([]byte(`"third"`)).WithContentType("application/json; charset=utf-8"),
+	))
+
+	var processed []string
+	proc := func(msg async.Message) error {
+		var s string
+		if err := msg.Decode(&s); err != nil {
+			return err
+		}
+		processed = append(processed, s)
+		if s == "fail" {
+			return errProcess
+		}
+		return nil
+	}
+	cmp, err := async.New("test", f, proc).WithFailureStrategy(async.NackStrategy).Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+
+	wctx, wcnl := context.WithTimeout(context.Background(), time.Second)
+	defer wcnl()
+	assert.NoError(t, f.Wait(wctx, 3))
+	cnl()
+	assert.NoError(t, <-chDone)
+
+	assert.Equal(t, []string{"first", "fail", "third"}, processed)
+	assert.Equal(t, []Result{{ID: "1", Outcome: Acked}, {ID: "2", Outcome: Nacked}, {ID: "3", Outcome: Acked}}, f.Results())
+	assert.Equal(t, []string{"1", "3"}, f.Acked())
+	assert.Equal(t, []string{"2"}, f.Nacked())
+}
+
+func TestComponent_ConsumerError(t *testing.T) {
+	f, err := New()
+	require.NoError(t, err)
+	require.NoError(t, f.Push(NewMessage([]byte(`{}`))))
+	require.NoError(t, f.PushError(errors.New("CONSUMER ERROR")))
+	require.NoError(t, f.Push(NewMessage([]byte(`{}`))))
+
+	cmp, err := async.New("test", f, func(async.Message) error { return nil }).WithRetries(1).Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+
+	wctx, wcnl := context.WithTimeout(context.Background(), time.Second)
+	defer wcnl()
+	assert.NoError(t, f.Wait(wctx, 2))
+	cnl()
+	assert.NoError(t, <-chDone)
+	assert.Equal(t, []string{"1", "2"}, f.Acked())
+}
+
+func TestFactory_Wait(t *testing.T) {
+	f, err := New()
+	require.NoError(t, err)
+	ctx, cnl := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cnl()
+	assert.Equal(t, context.DeadlineExceeded, f.Wait(ctx, 1))
+	assert.NoError(t, f.Wait(context.Background(), 0))
+}
+
+func waitPending(t *testing.T, f *Factory, n int) {
+	deadline := time.Now().Add(time.Second)
+	for f.Pending() != n {
+		if time.Now().After(deadline) {
+			assert.FailNow(t, "pending messages not queued back", "want %d, got %d", n, f.Pending())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
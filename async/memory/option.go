@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"errors"
+)
+
+// OptionFunc definition for configuring the consumer in a functional way.
+type OptionFunc func(*Factory) error
+
+// Source option for setting the source of the messages, e.g. the topic or the queue which is simulated, instead of "memory".
+func Source(src string) OptionFunc {
+	return func(f *Factory) error {
+		if src == "" {
+			return errors.New("source is required")
+		}
+		f.source = src
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package async
+
+import (
+	"github.com/beatlabs/patron/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type componentMetrics struct {
+	consumerErrors     *prometheus.CounterVec
+	workersBusy        *prometheus.GaugeVec
+	dispatchBlocked    *prometheus.CounterVec
+	messageRetries     *prometheus.CounterVec
+	rateLimitThrottled *prometheus.CounterVec
+	rateLimitWait      *prometheus.HistogramVec
+	rateLimitRate      *prometheus.GaugeVec
+}
+
+func newComponentMetrics(mr *metrics.Registry) (*componentMetrics, error) {
+	consumerErrors, err := mr.CounterVec(
+		prometheus.CounterOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "consumer_errors",
+			Help:      "Consumer errors, classified by name and type",
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	workersBusy, err := mr.GaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "workers_busy",
+			Help:      "Workers processing a message, classified by name",
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	dispatchBlocked, err := mr.CounterVec(
+		prometheus.CounterOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "dispatch_blocked",
+			Help:      "Messages which waited for a busy worker before being dispatched, classified by name",
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	messageRetries, err := mr.CounterVec(
+		prometheus.CounterOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "message_retries",
+			Help:      "Processing retries of messages, classified by name and outcome of the message",
+		},
+		"name", "outcome",
+	)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitThrottled, err := mr.CounterVec(
+		prometheus.CounterOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "rate_limit_throttled",
+			Help:      "Messages which waited for the rate limit before being processed, classified by name",
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitWait, err := mr.HistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "rate_limit_wait_seconds",
+			Help:      "Time the throttled messages waited for the rate limit, classified by name",
+			Buckets:   prometheus.DefBuckets,
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitRate, err := mr.GaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "rate_limit_rate",
+			Help:      "Current rate limit in messages per second, which is lowered by the adaptive rate, classified by name",
+		},
+		"name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &componentMetrics{
+		consumerErrors:     consumerErrors,
+		workersBusy:        workersBusy,
+		dispatchBlocked:    dispatchBlocked,
+		messageRetries:     messageRetries,
+		rateLimitThrottled: rateLimitThrottled,
+		rateLimitWait:      rateLimitWait,
+		rateLimitRate:      rateLimitRate,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MiddlewareFunc type declaration of middleware func, which wraps a processor.
+type MiddlewareFunc func(next ProcessorFunc) ProcessorFunc
+
+// MiddlewareChain chains middlewares to a processor func.
+func MiddlewareChain(f ProcessorFunc, mm ...MiddlewareFunc) ProcessorFunc {
+	for i := len(mm) - 1; i >= 0; i-- {
+		f = mm[i](f)
+	}
+	return f
+}
+
+// NewRecoveryMiddleware creates a MiddlewareFunc that recovers from a panic of the processor and returns it as an error,
+// in order for the failure strategy to be executed instead of crashing the service.
+func NewRecoveryMiddleware() MiddlewareFunc {
+	return func(next ProcessorFunc) ProcessorFunc {
+		return func(msg Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = recoveredError(r)
+					log.FromContext(msg.Context()).Errorf("recovering from an error %v", err)
+				}
+			}()
+			return next(msg)
+		}
+	}
+}
+
+// NewTimeoutMiddleware creates a MiddlewareFunc that sets a deadline to the context of the message,
+// which the processor has to respect. A non-positive timeout sets no deadline.
+func NewTimeoutMiddleware(timeout time.Duration) MiddlewareFunc {
+	return func(next ProcessorFunc) ProcessorFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(msg Message) error {
+			ctx, cnl := context.WithTimeout(msg.Context(), timeout)
+			defer cnl()
+			err := next(withContext(ctx, msg))
+			if err != nil && ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("processing timed out after %v: %w", timeout, err)
+			}
+			return err
+		}
+	}
+}
+
+// NewLoggingMiddleware creates a MiddlewareFunc that logs the duration and the outcome of the processing of every message,
+// on debug logging level if it succeeds and on warn logging level if it fails.
+func NewLoggingMiddleware() MiddlewareFunc {
+	return func(next ProcessorFunc) ProcessorFunc {
+		return func(msg Message) error {
+			start := time.Now()
+			err := next(msg)
+			fields := map[string]interface{}{
+				"duration": time.Since(start).String(),
+			}
+			if src := msg.Metadata().Source; src != "" {
+				fields["source"] = src
+			}
+			lgr := log.FromContext(msg.Context())
+			if err != nil {
+				fields["error"] = err.Error()
+				lgr.Sub(fields).Warn("failed to process message")
+				return err
+			}
+			lgr.Sub(fields).Debug("message processed")
+			return nil
+		}
+	}
+}
+
+// NewMetricsMiddleware creates a MiddlewareFunc that observes the processing duration of every message
+// with the component_async_message_duration_seconds histogram, classified by the name of the component and the outcome.
+// The default registry is used if the registry is nil.
+func NewMetricsMiddleware(name string, mr *metrics.Registry) (MiddlewareFunc, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	duration, err := mr.HistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "component",
+			Subsystem: "async",
+			Name:      "message_duration_seconds",
+			Help:      "Processing duration of messages, classified by name and outcome",
+		},
+		"name", "outcome",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(next ProcessorFunc) ProcessorFunc {
+		return func(msg Message) error {
+			start := time.Now()
+			err := next(msg)
+			outcome := "success"
+			if err != nil {
+				outcome = "failure"
+			}
+			duration.WithLabelValues(name, outcome).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}, nil
+}
+
+func recoveredError(r interface{}) error {
+	switch x := r.(type) {
+	case string:
+		return fmt.Errorf("panic: %s", x)
+	case error:
+		return fmt.Errorf("panic: %w", x)
+	default:
+		return fmt.Errorf("panic: %v", x)
+	}
+}
+
+// recoverBatch wraps the batch processor in order to return a panic as an error.
+func recoverBatch(bp BatchProcessorFunc) BatchProcessorFunc {
+	return func(b *Batch) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoveredError(r)
+				log.Errorf("recovering from an error %v", err)
+			}
+		}()
+		return bp(b)
+	}
+}
+
+// withContext returns the message with the context replaced.
+func withContext(ctx context.Context, msg Message) Message {
+	return &contextMessage{Message: msg, ctx: ctx}
+}
+
+type contextMessage struct {
+	Message
+	ctx context.Context
+}
+
+func (m *contextMessage) Context() context.Context {
+	return m.ctx
+}
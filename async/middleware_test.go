@@ -0,0 +1,177 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tagMiddleware(tag string, tags *[]string) MiddlewareFunc {
+	return func(next ProcessorFunc) ProcessorFunc {
+		return func(msg Message) error {
+			*tags = append(*tags, tag)
+			return next(msg)
+		}
+	}
+}
+
+func panicProcessor(v interface{}) ProcessorFunc {
+	return func(Message) error {
+		panic(v)
+	}
+}
+
+func TestMiddlewareChain(t *testing.T) {
+	var tags []string
+	proc := func(Message) error {
+		tags = append(tags, "proc")
+		return nil
+	}
+	err := MiddlewareChain(proc, tagMiddleware("first", &tags), tagMiddleware("second", &tags))(&mockMessage{ctx: context.Background()})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "proc"}, tags)
+}
+
+func TestNewRecoveryMiddleware(t *testing.T) {
+	errPanic := errors.New("PANIC ERROR")
+	tests := map[string]struct {
+		proc    ProcessorFunc
+		wantErr string
+	}{
+		"no panic":           {proc: func(Message) error { return nil }},
+		"error returned":     {proc: func(Message) error { return errProcess }, wantErr: "PROC ERROR"},
+		"panic with string":  {proc: panicProcessor("boom"), wantErr: "panic: boom"},
+		"panic with error":   {proc: panicProcessor(errPanic), wantErr: "panic: PANIC ERROR"},
+		"panic with integer": {proc: panicProcessor(-1), wantErr: "panic: -1"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := NewRecoveryMiddleware()(tt.proc)(&mockMessage{ctx: context.Background()})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+	err := NewRecoveryMiddleware()(panicProcessor(errPanic))(&mockMessage{ctx: context.Background()})
+	assert.True(t, errors.Is(err, errPanic))
+}
+
+func TestNewTimeoutMiddleware(t *testing.T) {
+	waitDeadline := func(msg Message) error {
+		<-msg.Context().Done()
+		return msg.Context().Err()
+	}
+	msg := &rawMessage{mockMessage: mockMessage{ctx: context.Background()}}
+
+	err := NewTimeoutMiddleware(10 * time.Millisecond)(waitDeadline)(msg)
+	assert.EqualError(t, err, "processing timed out after 10ms: context deadline exceeded")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	var got Message
+	err = NewTimeoutMiddleware(time.Second)(func(m Message) error {
+		got = m
+		return nil
+	})(msg)
+	assert.NoError(t, err)
+	_, ok := got.Context().Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, "orders", got.Metadata().Source)
+
+	err = NewTimeoutMiddleware(0)(func(m Message) error {
+		got = m
+		return nil
+	})(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
+
+func TestNewLoggingMiddleware(t *testing.T) {
+	mw := NewLoggingMiddleware()
+	assert.NoError(t, mw(func(Message) error { return nil })(&rawMessage{mockMessage: mockMessage{ctx: context.Background()}}))
+	assert.Equal(t, errProcess, mw(func(Message) error { return errProcess })(&mockMessage{ctx: context.Background()}))
+}
+
+func TestNewMetricsMiddleware(t *testing.T) {
+	_, err := NewMetricsMiddleware("", nil)
+	assert.EqualError(t, err, "name is required")
+	mw, err := NewMetricsMiddleware("test", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, mw)
+
+	mr, err := metrics.NewRegistry()
+	require.NoError(t, err)
+	mw, err = NewMetricsMiddleware("test", mr)
+	require.NoError(t, err)
+	msg := &mockMessage{ctx: context.Background()}
+	assert.NoError(t, mw(func(Message) error { return nil })(msg))
+	assert.NoError(t, mw(func(Message) error { return nil })(msg))
+	assert.Equal(t, errProcess, mw(func(Message) error { return errProcess })(msg))
+
+	body := scrape(t, mr)
+	assert.Contains(t, body, `component_async_message_duration_seconds_count{name="test",outcome="success"} 2`)
+	assert.Contains(t, body, `component_async_message_duration_seconds_count{name="test",outcome="failure"} 1`)
+}
+
+func TestBuilder_WithMiddlewares(t *testing.T) {
+	proc := mockProcessor{}
+	var tags []string
+	cmp, err := New("name", &mockConsumerFactory{}, proc.Process).
+		WithMiddlewares(tagMiddleware("first", &tags)).
+		WithMiddlewares(tagMiddleware("second", &tags)).
+		Create()
+	require.NoError(t, err)
+	assert.NoError(t, cmp.proc(&mockMessage{ctx: context.Background()}))
+	assert.Equal(t, []string{"first", "second"}, tags)
+	assert.Equal(t, 1, proc.execs)
+
+	_, err = New("name", &mockConsumerFactory{}, proc.Process).WithMiddlewares(NewLoggingMiddleware(), nil).Create()
+	assert.EqualError(t, err, "nil middleware provided\n")
+
+	_, err = New("name", &mockConsumerFactory{}, nil).
+		WithBatching(func(*Batch) error { return nil }, 10, time.Second).
+		WithMiddlewares(NewLoggingMiddleware()).
+		Create()
+	assert.EqualError(t, err, "batching cannot be combined with middlewares\n")
+}
+
+func TestRun_ProcessorPanic(t *testing.T) {
+	msg := &mockMessage{ctx: context.Background()}
+	cnr := mockConsumer{chMsg: make(chan Message, 1), chErr: make(chan error)}
+	cnr.chMsg <- msg
+	cmp, err := New("test", &mockConsumerFactory{c: &cnr}, panicProcessor("boom")).
+		WithFailureStrategy(NackStrategy).
+		Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cnl()
+	assert.NoError(t, <-chDone)
+	assert.True(t, msg.nacked)
+	assert.False(t, msg.acked)
+}
+
+func TestRun_BatchProcessorPanic(t *testing.T) {
+	msg := newBatchMessage()
+	cnr := mockConsumer{chMsg: make(chan Message, 1), chErr: make(chan error)}
+	cnr.chMsg <- msg
+	cmp, err := New("test", &mockConsumerFactory{c: &cnr}, nil).
+		WithBatching(func(*Batch) error { panic("boom") }, 1, time.Second).
+		Create()
+	require.NoError(t, err)
+
+	err = cmp.Run(context.Background())
+	assert.EqualError(t, err, "panic: boom")
+	assert.True(t, msg.nacked)
+}
@@ -0,0 +1,67 @@
+package async
+
+import (
+	"context"
+	"sync"
+)
+
+// PausableConsumer can be optionally implemented by a consumer in order to stop fetching messages from the broker
+// while the component is paused, instead of only holding back the messages which have already been fetched.
+type PausableConsumer interface {
+	Pause() error
+	Resume() error
+}
+
+// PauseGate blocks the callers of Wait while it is paused, which helps consumers implement the PausableConsumer interface.
+// The zero value is a gate which is not paused.
+type PauseGate struct {
+	mu      sync.Mutex
+	resumed chan struct{}
+}
+
+// Pause pauses the gate, returning false if it was already paused.
+func (g *PauseGate) Pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// Resume resumes the gate, releasing the callers of Wait, returning false if it was not paused.
+func (g *PauseGate) Resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+// Paused returns true if the gate is paused.
+func (g *PauseGate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.resumed != nil
+}
+
+// Wait blocks while the gate is paused, returning the error of the context if it is done first.
+func (g *PauseGate) Wait(ctx context.Context) error {
+	for {
+		g.mu.Lock()
+		resumed := g.resumed
+		g.mu.Unlock()
+		if resumed == nil {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-resumed:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseGate(t *testing.T) {
+	var g PauseGate
+	assert.False(t, g.Paused())
+	assert.NoError(t, g.Wait(context.Background()))
+	assert.False(t, g.Resume())
+
+	assert.True(t, g.Pause())
+	assert.False(t, g.Pause())
+	assert.True(t, g.Paused())
+	ctx, cnl := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cnl()
+	assert.Equal(t, context.DeadlineExceeded, g.Wait(ctx))
+
+	chDone := make(chan error)
+	go func() {
+		chDone <- g.Wait(context.Background())
+	}()
+	assert.True(t, g.Resume())
+	assert.NoError(t, <-chDone)
+	assert.False(t, g.Paused())
+}
+
+type pausableConsumer struct {
+	mockConsumer
+	sync.Mutex
+	pauseErr bool
+	pauses   int
+	resumes  int
+}
+
+func (pc *pausableConsumer) Pause() error {
+	pc.Lock()
+	defer pc.Unlock()
+	pc.pauses++
+	if pc.pauseErr {
+		return errors.New("PAUSE ERROR")
+	}
+	return nil
+}
+
+func (pc *pausableConsumer) Resume() error {
+	pc.Lock()
+	defer pc.Unlock()
+	pc.resumes++
+	return nil
+}
+
+func (pc *pausableConsumer) counts() (int, int) {
+	pc.Lock()
+	defer pc.Unlock()
+	return pc.pauses, pc.resumes
+}
+
+func TestComponent_PauseResume(t *testing.T) {
+	cnr := &pausableConsumer{mockConsumer: mockConsumer{chMsg: make(chan Message, 10), chErr: make(chan error, 10)}}
+	chProcessing := make(chan struct{})
+	chRelease := make(chan struct{})
+	cmp, err := New("test", &mockConsumerFactory{c: cnr}, func(Message) error {
+		chProcessing <- struct{}{}
+		<-chRelease
+		return nil
+	}).Create()
+	require.NoError(t, err)
+	assert.Equal(t, "test", cmp.Name())
+
+	// a component which is paused before running pauses its consumer once it is consuming
+	assert.NoError(t, cmp.Pause())
+	assert.NoError(t, cmp.Pause())
+	assert.True(t, cmp.Paused())
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	msg := &mockMessage{ctx: context.Background()}
+	cnr.chMsg <- msg
+	select {
+	case <-chProcessing:
+		assert.FailNow(t, "message processed while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	pauses, resumes := cnr.counts()
+	assert.Equal(t, 1, pauses)
+	assert.Equal(t, 0, resumes)
+	assert.Equal(t, 0, cmp.InFlight())
+
+	assert.NoError(t, cmp.Resume())
+	assert.NoError(t, cmp.Resume())
+	assert.False(t, cmp.Paused())
+	<-chProcessing
+	assert.Equal(t, 1, cmp.InFlight())
+	_, resumes = cnr.counts()
+	assert.Equal(t, 1, resumes)
+	close(chRelease)
+
+	cnl()
+	assert.NoError(t, <-chDone)
+	assert.Equal(t, 0, cmp.InFlight())
+	assert.True(t, msg.acked)
+}
+
+func TestComponent_Pause_Shutdown(t *testing.T) {
+	cnr := &pausableConsumer{mockConsumer: mockConsumer{chMsg: make(chan Message, 10), chErr: make(chan error, 10)}}
+	proc := mockProcessor{}
+	cmp, err := New("test", &mockConsumerFactory{c: cnr}, proc.Process).WithConcurrency(2).Create()
+	require.NoError(t, err)
+	require.NoError(t, cmp.Pause())
+
+	msg := &mockMessage{ctx: context.Background()}
+	cnr.chMsg <- msg
+	ctx, cnl := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cnl()
+	assert.NoError(t, cmp.Run(ctx))
+	assert.Equal(t, 0, proc.execs)
+	assert.True(t, msg.nacked)
+	assert.False(t, msg.acked)
+}
+
+func TestComponent_Pause_ConsumerError(t *testing.T) {
+	cnr := &pausableConsumer{
+		mockConsumer: mockConsumer{chMsg: make(chan Message, 10), chErr: make(chan error, 10)},
+		pauseErr:     true,
+	}
+	proc := mockProcessor{}
+	cmp, err := New("test", &mockConsumerFactory{c: cnr}, proc.Process).Create()
+	require.NoError(t, err)
+	require.NoError(t, cmp.Pause())
+	assert.EqualError(t, cmp.Run(context.Background()), "failed to pause consumer: PAUSE ERROR")
+}
@@ -0,0 +1,147 @@
+package async
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/beatlabs/patron/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pool of workers, each one with its own queue, so that the messages with the same key are processed in order.
+type pool struct {
+	queues  []chan Message
+	keyFunc KeyFunc
+	next    int
+	chFail  chan error
+	quit    chan struct{}
+	wg      sync.WaitGroup
+	busy    prometheus.Gauge
+	blocked prometheus.Counter
+}
+
+func (c *Component) newPool(ctx context.Context, cm *componentMetrics) *pool {
+	p := &pool{
+		queues:  make([]chan Message, c.workers),
+		keyFunc: c.keyFunc,
+		chFail:  make(chan error, c.workers),
+		quit:    make(chan struct{}),
+		busy:    cm.workersBusy.WithLabelValues(c.name),
+		blocked: cm.dispatchBlocked.WithLabelValues(c.name),
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan Message, 1)
+		p.wg.Add(1)
+		go p.work(p.queues[i], func(msg Message) error {
+			return c.processMessage(ctx, msg, cm)
+		})
+	}
+	return p
+}
+
+// work processes the messages of the queue until it is closed.
+// After the pool has stopped, the queued messages are nacked, in order to be redelivered.
+func (p *pool) work(queue <-chan Message, process func(Message) error) {
+	defer p.wg.Done()
+	for msg := range queue {
+		select {
+		case <-p.quit:
+			nackMessage(msg)
+			continue
+		default:
+		}
+		p.busy.Inc()
+		err := process(msg)
+		p.busy.Dec()
+		if err != nil {
+			select {
+			case p.chFail <- err:
+			default:
+			}
+		}
+	}
+}
+
+// dispatch queues the message to the worker of its key and blocks while the worker is busy,
+// so that the consumer is not read faster than the messages are processed.
+// The message is nacked if the context is cancelled or a worker fails while waiting, in which case the failure is returned.
+func (p *pool) dispatch(ctx context.Context, msg Message) error {
+	queue := p.queues[p.index(msg)]
+	select {
+	case queue <- msg:
+		return nil
+	default:
+	}
+	p.blocked.Inc()
+	select {
+	case queue <- msg:
+		return nil
+	case <-ctx.Done():
+		nackMessage(msg)
+		return nil
+	case err := <-p.chFail:
+		nackMessage(msg)
+		return err
+	}
+}
+
+// index returns the worker of the key of the message, or the next worker in turn when the message has no key.
+func (p *pool) index(msg Message) int {
+	if p.keyFunc != nil {
+		if key := p.keyFunc(msg); key != "" {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(key))
+			return int(h.Sum32() % uint32(len(p.queues)))
+		}
+	}
+	i := p.next
+	p.next = (p.next + 1) % len(p.queues)
+	return i
+}
+
+// stop waits for the in-flight messages to complete, after nacking the queued ones.
+func (p *pool) stop() {
+	close(p.quit)
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+// dispatching consumes messages and dispatches them to a pool of workers until the context is cancelled or an error occurs.
+// The first processing error stops the pool, after the in-flight messages of the other workers are completed.
+func (c *Component) dispatching(ctx context.Context, cns Consumer, stopFetching context.CancelFunc,
+	chMsg <-chan Message, chErr <-chan error, cm *componentMetrics) error {
+	p := c.newPool(ctx, cm)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.stop()
+			return shutdown(cns, stopFetching, chMsg)
+		case err := <-p.chFail:
+			p.stop()
+			closeConsumer(cns)
+			return err
+		case msg := <-chMsg:
+			if c.admit(ctx, cm) != nil {
+				nackMessage(msg)
+				p.stop()
+				return shutdown(cns, stopFetching, chMsg)
+			}
+			log.Debug("New message from consumer arrived")
+			err := p.dispatch(ctx, msg)
+			if err != nil {
+				p.stop()
+				closeConsumer(cns)
+				return err
+			}
+		case errMsg := <-chErr:
+			p.stop()
+			closeConsumer(cns)
+			return fmt.Errorf("an error occurred during message consumption: %w", errMsg)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package async
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_Concurrency_OrderPerKey verifies that the messages with the same key are processed in order,
+// while all messages are processed by the pool of workers
+func TestRun_Concurrency_OrderPerKey(t *testing.T) {
+	keys := []string{"a", "b", "c"}
+	count := 20
+	chMsg := make(chan Message, len(keys)*count)
+	var msgs []*keyedMessage
+	for i := 0; i < count; i++ {
+		for _, k := range keys {
+			m := &keyedMessage{key: k, seq: i}
+			msgs = append(msgs, m)
+			chMsg <- m
+		}
+	}
+
+	var mu sync.Mutex
+	processed := map[string][]int{}
+	var wg sync.WaitGroup
+	wg.Add(len(msgs))
+	proc := func(msg Message) error {
+		defer wg.Done()
+		m := msg.(*keyedMessage)
+		time.Sleep(time.Duration(m.seq%3) * time.Millisecond)
+		mu.Lock()
+		processed[m.key] = append(processed[m.key], m.seq)
+		mu.Unlock()
+		return nil
+	}
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runConcurrently(ctx, t, chMsg, proc, 4, messageKey, nil)
+	wg.Wait()
+	cnl()
+	assert.NoError(t, <-chDone)
+
+	for _, k := range keys {
+		require.Len(t, processed[k], count)
+		for i, seq := range processed[k] {
+			assert.Equal(t, i, seq, "message of key %s processed out of order", k)
+		}
+	}
+	for _, m := range msgs {
+		assert.True(t, m.isAcked())
+	}
+}
+
+// TestRun_Concurrency_Parallel verifies that the messages without a key are processed in parallel
+func TestRun_Concurrency_Parallel(t *testing.T) {
+	chMsg := make(chan Message, 2)
+	chMsg <- &keyedMessage{}
+	chMsg <- &keyedMessage{}
+
+	chStarted := make(chan struct{}, 2)
+	release := make(chan struct{})
+	proc := func(msg Message) error {
+		chStarted <- struct{}{}
+		<-release
+		return nil
+	}
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runConcurrently(ctx, t, chMsg, proc, 2, nil, nil)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-chStarted:
+		case <-time.After(time.Second):
+			assert.FailNow(t, "messages are not processed in parallel")
+		}
+	}
+	close(release)
+	cnl()
+	assert.NoError(t, <-chDone)
+}
+
+// TestRun_Concurrency_Backpressure verifies that the consumer is not read while the worker of the key is busy
+// and that the waiting is reported in the metrics
+func TestRun_Concurrency_Backpressure(t *testing.T) {
+	chMsg := make(chan Message, 6)
+	var msgs []*keyedMessage
+	for i := 0; i < 6; i++ {
+		m := &keyedMessage{key: "a", seq: i}
+		msgs = append(msgs, m)
+		chMsg <- m
+	}
+
+	chStarted := make(chan struct{}, 6)
+	release := make(chan struct{})
+	proc := func(msg Message) error {
+		chStarted <- struct{}{}
+		<-release
+		return nil
+	}
+	mr, err := metrics.NewRegistry()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := runConcurrently(ctx, t, chMsg, proc, 2, messageKey, mr)
+	<-chStarted
+	time.Sleep(20 * time.Millisecond)
+
+	// one message in-flight, one queued to the worker and one waiting to be dispatched
+	assert.Len(t, chMsg, 3)
+	body := scrape(t, mr)
+	assert.Contains(t, body, `component_async_workers_busy{name="test"} 1`)
+	assert.Contains(t, body, `component_async_dispatch_blocked{name="test"}`)
+
+	cnl()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	assert.NoError(t, <-chDone)
+	assert.True(t, msgs[0].isAcked())
+	for _, m := range msgs[1:] {
+		assert.True(t, m.isNacked())
+		assert.False(t, m.isAcked())
+	}
+}
+
+// TestRun_Concurrency_ProcessError verifies that a processing error with the NackExitStrategy stops the pool,
+// after the in-flight messages are completed, and nacks the queued messages
+func TestRun_Concurrency_ProcessError(t *testing.T) {
+	failing := &keyedMessage{key: "fail"}
+	queued := &keyedMessage{key: "fail"}
+	chMsg := make(chan Message, 2)
+	chMsg <- failing
+	chMsg <- queued
+
+	release := make(chan struct{})
+	proc := func(msg Message) error {
+		<-release
+		return errProcess
+	}
+
+	chDone := runConcurrently(context.Background(), t, chMsg, proc, 2, messageKey, nil)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	err := <-chDone
+	assert.EqualError(t, err, errProcess.Error())
+	assert.True(t, failing.isNacked())
+	assert.True(t, queued.isNacked())
+	assert.False(t, queued.isAcked())
+}
+
+func runConcurrently(ctx context.Context, t *testing.T, chMsg chan Message, proc ProcessorFunc, workers uint,
+	kf KeyFunc, mr *metrics.Registry) <-chan error {
+	cf := &mockConsumerFactory{c: &mockConsumer{chMsg: chMsg, chErr: make(chan error)}}
+	b := New("test", cf, proc).WithConcurrency(workers)
+	if kf != nil {
+		b.WithKeyFunc(kf)
+	}
+	if mr != nil {
+		b.WithMetrics(mr)
+	}
+	cmp, err := b.Create()
+	require.NoError(t, err)
+
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	return chDone
+}
+
+func scrape(t *testing.T, mr *metrics.Registry) string {
+	rsp := httptest.NewRecorder()
+	mr.Handler().ServeHTTP(rsp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rsp.Code)
+	return rsp.Body.String()
+}
+
+func messageKey(msg Message) string {
+	m, ok := msg.(*keyedMessage)
+	if !ok {
+		return ""
+	}
+	return m.key
+}
+
+// keyedMessage is a message with an ordering key, which can be acked and nacked concurrently.
+type keyedMessage struct {
+	sync.Mutex
+	key    string
+	seq    int
+	acked  bool
+	nacked bool
+}
+
+func (km *keyedMessage) Context() context.Context {
+	return context.Background()
+}
+
+func (km *keyedMessage) Decode(v interface{}) error {
+	return nil
+}
+
+func (km *keyedMessage) Ack() error {
+	km.Lock()
+	defer km.Unlock()
+	km.acked = true
+	return nil
+}
+
+func (km *keyedMessage) Nack() error {
+	km.Lock()
+	defer km.Unlock()
+	km.nacked = true
+	return nil
+}
+
+func (km *keyedMessage) Metadata() Metadata {
+	return Metadata{Key: km.key}
+}
+
+func (km *keyedMessage) isAcked() bool {
+	km.Lock()
+	defer km.Unlock()
+	return km.acked
+}
+
+func (km *keyedMessage) isNacked() bool {
+	km.Lock()
+	defer km.Unlock()
+	return km.nacked
+}
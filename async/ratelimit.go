@@ -0,0 +1,162 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit of the consumption of messages, which is a token bucket that is refilled with rate messages per second
+// and holds up to burst messages, so that a burst of messages can be processed without waiting.
+type RateLimit struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    int
+	current  float64
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+	adaptive *adaptiveRate
+}
+
+// adaptiveRate halves the rate, down to min, at the end of every window of processed messages whose error rate exceeds
+// maxErrorRate, and doubles it, up to the configured rate, at the end of every other window.
+type adaptiveRate struct {
+	min          float64
+	maxErrorRate float64
+	window       int
+	processed    int
+	failed       int
+}
+
+// RateLimitOptionFunc definition for configuring the rate limit in a functional way.
+type RateLimitOptionFunc func(*RateLimit) error
+
+// NewRateLimit creates a rate limit of rate messages per second with a burst.
+func NewRateLimit(rate float64, burst uint, oo ...RateLimitOptionFunc) (*RateLimit, error) {
+	if rate <= 0 || math.IsInf(rate, 1) {
+		return nil, errors.New("rate must be positive")
+	}
+	if burst == 0 {
+		return nil, errors.New("burst must be positive")
+	}
+	rl := &RateLimit{
+		rate:    rate,
+		burst:   int(burst),
+		current: rate,
+		tokens:  float64(burst),
+		now:     time.Now,
+	}
+	for _, o := range oo {
+		err := o(rl)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rl.adaptive != nil && rl.adaptive.min > rl.rate {
+		return nil, errors.New("minimum rate must be less or equal than the rate")
+	}
+	rl.last = rl.now()
+	return rl, nil
+}
+
+// AdaptiveRate option for slowing down when the processing error rate climbs.
+// At the end of every window of processed messages the rate is halved, down to min, if the error rate exceeds maxErrorRate,
+// otherwise it is doubled, up to the configured rate.
+func AdaptiveRate(min, maxErrorRate float64, window uint) RateLimitOptionFunc {
+	return func(rl *RateLimit) error {
+		if min <= 0 {
+			return errors.New("minimum rate must be positive")
+		}
+		if maxErrorRate < 0 || maxErrorRate >= 1 {
+			return errors.New("max error rate must be between 0 and 1")
+		}
+		if window == 0 {
+			return errors.New("window must be positive")
+		}
+		rl.adaptive = &adaptiveRate{min: min, maxErrorRate: maxErrorRate, window: int(window)}
+		return nil
+	}
+}
+
+// Rate returns the current rate of messages per second, which is lower than the configured rate while it adapts to errors.
+func (rl *RateLimit) Rate() float64 {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return rl.current
+}
+
+// wait blocks until a message can be consumed, returning the duration it waited,
+// or the error of the context if it is done first.
+func (rl *RateLimit) wait(ctx context.Context) (time.Duration, error) {
+	d := rl.reserve()
+	if d <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		rl.cancel()
+		return d, ctx.Err()
+	case <-timer.C:
+		return d, nil
+	}
+}
+
+// reserve takes a token from the bucket, returning the duration until the token is available,
+// since the tokens which are taken from an empty bucket are borrowed from the next refills.
+func (rl *RateLimit) reserve() time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.refill()
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-rl.tokens / rl.current * float64(time.Second))
+}
+
+// cancel gives back a token which has been reserved but not used.
+func (rl *RateLimit) cancel() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.tokens = math.Min(rl.tokens+1, float64(rl.burst))
+}
+
+func (rl *RateLimit) refill() {
+	now := rl.now()
+	elapsed := now.Sub(rl.last).Seconds()
+	rl.last = now
+	if elapsed > 0 {
+		rl.tokens = math.Min(rl.tokens+elapsed*rl.current, float64(rl.burst))
+	}
+}
+
+// observe records the outcome of the processing of a message, which adapts the rate if the adaptive rate option is set.
+func (rl *RateLimit) observe(err error) {
+	if rl == nil || rl.adaptive == nil {
+		return
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	a := rl.adaptive
+	a.processed++
+	if err != nil {
+		a.failed++
+	}
+	if a.processed < a.window {
+		return
+	}
+	// the tokens are refilled with the previous rate up to now
+	rl.refill()
+	if float64(a.failed)/float64(a.processed) > a.maxErrorRate {
+		rl.current = math.Max(rl.current/2, a.min)
+	} else {
+		rl.current = math.Min(rl.current*2, rl.rate)
+	}
+	a.processed = 0
+	a.failed = 0
+}
@@ -0,0 +1,156 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRateLimit(t *testing.T) {
+	tests := map[string]struct {
+		rate    float64
+		burst   uint
+		oo      []RateLimitOptionFunc
+		wantErr string
+	}{
+		"success":                       {rate: 10, burst: 1},
+		"success, adaptive":             {rate: 10, burst: 1, oo: []RateLimitOptionFunc{AdaptiveRate(1, 0.1, 10)}},
+		"failure, zero rate":            {rate: 0, burst: 1, wantErr: "rate must be positive"},
+		"failure, infinite rate":        {rate: math.Inf(1), burst: 1, wantErr: "rate must be positive"},
+		"failure, zero burst":           {rate: 10, burst: 0, wantErr: "burst must be positive"},
+		"failure, zero minimum rate":    {rate: 10, burst: 1, oo: []RateLimitOptionFunc{AdaptiveRate(0, 0.1, 10)}, wantErr: "minimum rate must be positive"},
+		"failure, minimum rate exceeds": {rate: 10, burst: 1, oo: []RateLimitOptionFunc{AdaptiveRate(20, 0.1, 10)}, wantErr: "minimum rate must be less or equal than the rate"},
+		"failure, error rate":           {rate: 10, burst: 1, oo: []RateLimitOptionFunc{AdaptiveRate(1, 1, 10)}, wantErr: "max error rate must be between 0 and 1"},
+		"failure, zero window":          {rate: 10, burst: 1, oo: []RateLimitOptionFunc{AdaptiveRate(1, 0.1, 0)}, wantErr: "window must be positive"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewRateLimit(tt.rate, tt.burst, tt.oo...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.rate, got.Rate())
+			}
+		})
+	}
+}
+
+func TestRateLimit_reserve(t *testing.T) {
+	rl, err := NewRateLimit(10, 2)
+	require.NoError(t, err)
+	now := time.Now()
+	rl.now = func() time.Time { return now }
+	rl.last = now
+
+	// the burst is available without waiting, while the next tokens are borrowed from the refills
+	assert.Equal(t, time.Duration(0), rl.reserve())
+	assert.Equal(t, time.Duration(0), rl.reserve())
+	assert.Equal(t, 100*time.Millisecond, rl.reserve())
+	assert.Equal(t, 200*time.Millisecond, rl.reserve())
+	rl.cancel()
+	assert.Equal(t, 200*time.Millisecond, rl.reserve())
+
+	// the bucket is refilled up to the burst
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), rl.reserve())
+	assert.Equal(t, time.Duration(0), rl.reserve())
+	assert.Equal(t, 100*time.Millisecond, rl.reserve())
+}
+
+func TestRateLimit_wait(t *testing.T) {
+	rl, err := NewRateLimit(1, 1)
+	require.NoError(t, err)
+	wait, err := rl.wait(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), wait)
+
+	ctx, cnl := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cnl()
+	wait, err = rl.wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, wait > 0)
+}
+
+func TestRateLimit_observe(t *testing.T) {
+	var nilRL *RateLimit
+	nilRL.observe(errors.New("ERROR"))
+
+	rl, err := NewRateLimit(100, 1, AdaptiveRate(10, 0.5, 4))
+	require.NoError(t, err)
+	observe := func(failures int) {
+		for i := 0; i < 4; i++ {
+			if i < failures {
+				rl.observe(errors.New("ERROR"))
+			} else {
+				rl.observe(nil)
+			}
+		}
+	}
+
+	observe(2)
+	assert.Equal(t, 100.0, rl.Rate())
+	observe(3)
+	assert.Equal(t, 50.0, rl.Rate())
+	observe(4)
+	assert.Equal(t, 25.0, rl.Rate())
+	observe(4)
+	observe(4)
+	assert.Equal(t, 10.0, rl.Rate())
+	observe(0)
+	assert.Equal(t, 20.0, rl.Rate())
+	observe(0)
+	observe(0)
+	observe(0)
+	assert.Equal(t, 100.0, rl.Rate())
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	rl, err := NewRateLimit(50, 1)
+	require.NoError(t, err)
+	mr, err := metrics.NewRegistry()
+	require.NoError(t, err)
+
+	cnr := mockConsumer{chMsg: make(chan Message, 5), chErr: make(chan error)}
+	for i := 0; i < 5; i++ {
+		cnr.chMsg <- &mockMessage{ctx: context.Background()}
+	}
+	chProcessed := make(chan struct{}, 5)
+	cmp, err := New("test", &mockConsumerFactory{c: &cnr}, func(Message) error {
+		chProcessed <- struct{}{}
+		return nil
+	}).WithRateLimit(rl).WithMetrics(mr).Create()
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, cmp.Info()["rate_limit"])
+	assert.Equal(t, 1, cmp.Info()["rate_limit_burst"])
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	for i := 0; i < 5; i++ {
+		<-chProcessed
+	}
+	elapsed := time.Since(start)
+	cnl()
+	assert.NoError(t, <-chDone)
+
+	// the first message is processed immediately and the next four wait 20ms each
+	assert.True(t, elapsed >= 70*time.Millisecond, "elapsed %v", elapsed)
+	out := scrape(t, mr)
+	assert.Contains(t, out, `component_async_rate_limit_throttled{name="test"} 4`)
+	assert.Contains(t, out, `component_async_rate_limit_wait_seconds_count{name="test"} 4`)
+	assert.Contains(t, out, `component_async_rate_limit_rate{name="test"} 50`)
+
+	_, err = New("test", &mockConsumerFactory{c: &cnr}, func(Message) error { return nil }).WithRateLimit(nil).Create()
+	assert.EqualError(t, err, "nil rate limit provided\n")
+}
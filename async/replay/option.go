@@ -0,0 +1,32 @@
+package replay
+
+import (
+	"errors"
+)
+
+// OptionFunc definition for configuring the consumer in a functional way.
+type OptionFunc func(*Factory) error
+
+// Checkpoint option for setting the file which keeps the last line up to which all records have been acked or nacked.
+// A replay resumes after the line of an existing checkpoint file.
+func Checkpoint(path string) OptionFunc {
+	return func(f *Factory) error {
+		if path == "" {
+			return errors.New("checkpoint path is required")
+		}
+		f.checkpoint = path
+		return nil
+	}
+}
+
+// Report option for setting the file to which the outcome of every record is appended, as a newline-delimited JSON entry.
+// The file is opened once the factory is created.
+func Report(path string) OptionFunc {
+	return func(f *Factory) error {
+		if path == "" {
+			return errors.New("report path is required")
+		}
+		f.reportPath = path
+		return nil
+	}
+}
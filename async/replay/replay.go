@@ -0,0 +1,456 @@
+// Package replay provides a consumer which replays captured messages from a file of newline-delimited JSON records,
+// in order to re-run a processor over them, keeping track of its progress in a checkpoint file so that a replay can resume.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/correlation"
+	"github.com/beatlabs/patron/encoding"
+	patronjson "github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/trace"
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go"
+)
+
+// Stdin is the path which replays the records from the standard input.
+const Stdin = "-"
+
+// Record of a captured message, which is a line of the replay file.
+// A body which is a JSON string is used unquoted, or decoded from base64 if Base64 is set, while any other JSON value is used as is.
+type Record struct {
+	Body        json.RawMessage   `json:"body"`
+	Base64      bool              `json:"base64,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ContentType string            `json:"content_type,omitempty"`
+	Key         string            `json:"key,omitempty"`
+	Timestamp   time.Time         `json:"timestamp,omitempty"`
+}
+
+func (r *Record) payload() ([]byte, error) {
+	body := bytes.TrimSpace(r.Body)
+	if len(body) == 0 || body[0] != '"' {
+		return body, nil
+	}
+	var s string
+	err := json.Unmarshal(body, &s)
+	if err != nil {
+		return nil, err
+	}
+	if r.Base64 {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return []byte(s), nil
+}
+
+// ReportEntry of the output report, which is written every time a message is acked or nacked.
+type ReportEntry struct {
+	Line      int       `json:"line"`
+	Outcome   string    `json:"outcome"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Factory of replay consumers.
+// The records are numbered by their line and the checkpoint is the last line up to which all records have been acked or nacked.
+type Factory struct {
+	sync.Mutex
+	path       string
+	checkpoint string
+	reportPath string
+	report     *os.File
+	stdin      *bufio.Reader
+	line       int
+	pending    *pendingRecord
+	watermark  int
+	done       map[int]bool
+	eof        bool
+	chDone     chan struct{}
+}
+
+type pendingRecord struct {
+	line int
+	data []byte
+}
+
+// New creates a new replay consumer factory for a file, or the standard input if the path is Stdin.
+// The replay resumes after the line of the checkpoint file, if any.
+func New(path string, oo ...OptionFunc) (*Factory, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	f := &Factory{
+		path:   path,
+		done:   make(map[int]bool),
+		chDone: make(chan struct{}),
+	}
+	for _, o := range oo {
+		err := o(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if f.checkpoint != "" {
+		wm, err := readCheckpoint(f.checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		f.watermark = wm
+	}
+	if f.reportPath != "" {
+		file, err := os.OpenFile(f.reportPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open report: %w", err)
+		}
+		f.report = file
+	}
+	return f, nil
+}
+
+// Done returns a channel which is closed when all the records have been read and acked or nacked,
+// in order for the service to be stopped when the replay is complete.
+func (f *Factory) Done() <-chan struct{} {
+	return f.chDone
+}
+
+// Checkpoint returns the last line up to which all records have been acked or nacked.
+func (f *Factory) Checkpoint() int {
+	f.Lock()
+	defer f.Unlock()
+	return f.watermark
+}
+
+// Close closes the report file, if any.
+func (f *Factory) Close() error {
+	if f.report == nil {
+		return nil
+	}
+	return f.report.Close()
+}
+
+// Create a new consumer.
+// The file is read from the start, skipping the records which have been acked or nacked, while the standard input
+// is read from where the previous consumer stopped.
+func (f *Factory) Create() (async.Consumer, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.path == Stdin {
+		if f.stdin == nil {
+			f.stdin = bufio.NewReader(os.Stdin)
+		}
+		return &consumer{f: f, rd: f.stdin}, nil
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	f.line = 0
+	f.eof = false
+	f.pending = nil
+	return &consumer{f: f, rd: bufio.NewReader(file), file: file}, nil
+}
+
+// next returns the next record which has not been acked or nacked, along with its line,
+// or io.EOF if all records have been read.
+func (f *Factory) next(rd *bufio.Reader) (int, []byte, error) {
+	f.Lock()
+	defer f.Unlock()
+	if f.pending != nil {
+		p := f.pending
+		f.pending = nil
+		return p.line, p.data, nil
+	}
+	for {
+		data, err := rd.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			if err == io.EOF {
+				f.eof = true
+				f.complete()
+			}
+			return 0, nil, err
+		}
+		f.line++
+		if f.line <= f.watermark || f.done[f.line] {
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			f.advance(f.line)
+			continue
+		}
+		return f.line, data, nil
+	}
+}
+
+// unread keeps a record which could not be delivered, in order to be delivered first by the next consumer.
+func (f *Factory) unread(line int, data []byte) {
+	f.Lock()
+	defer f.Unlock()
+	f.pending = &pendingRecord{line: line, data: data}
+}
+
+// record writes the outcome of the record to the report and moves the checkpoint forward.
+func (f *Factory) record(line int, outcome string) error {
+	f.Lock()
+	defer f.Unlock()
+	if f.report != nil {
+		b, err := json.Marshal(ReportEntry{Line: line, Outcome: outcome, Timestamp: time.Now().UTC()})
+		if err != nil {
+			return err
+		}
+		_, err = f.report.Write(append(b, '\n'))
+		if err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	if line <= f.watermark || f.done[line] {
+		return nil
+	}
+	f.advance(line)
+	f.complete()
+	if f.checkpoint == "" {
+		return nil
+	}
+	return writeCheckpoint(f.checkpoint, f.watermark)
+}
+
+// advance marks the line as done and moves the watermark over the contiguous done lines.
+func (f *Factory) advance(line int) {
+	f.done[line] = true
+	for f.done[f.watermark+1] {
+		delete(f.done, f.watermark+1)
+		f.watermark++
+	}
+}
+
+func (f *Factory) complete() {
+	if !f.eof || f.watermark < f.line || f.pending != nil {
+		return
+	}
+	select {
+	case <-f.chDone:
+	default:
+		close(f.chDone)
+	}
+}
+
+func readCheckpoint(path string) (int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	wm, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || wm < 0 {
+		return 0, fmt.Errorf("invalid checkpoint %q", string(b))
+	}
+	return wm, nil
+}
+
+// writeCheckpoint writes the checkpoint to a temporary file which replaces the checkpoint file, so that it is never partially written.
+func writeCheckpoint(path string, wm int) error {
+	tmp := path + ".tmp"
+	err := ioutil.WriteFile(tmp, []byte(strconv.Itoa(wm)+"\n"), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// source returns the source of the records, which is the path of the replay file or stdin.
+func (f *Factory) source() string {
+	if f.path == Stdin {
+		return "stdin"
+	}
+	return f.path
+}
+
+type consumer struct {
+	f    *Factory
+	rd   *bufio.Reader
+	file *os.File
+}
+
+// Consume delivers the records one at a time, until all records have been read or the context is cancelled.
+// An invalid record is nacked and returned as an error.
+func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan error, error) {
+	chMsg := make(chan async.Message)
+	chErr := make(chan error)
+
+	go func() {
+		for {
+			if ctx.Err() != nil {
+				log.Info("canceling consuming messages requested")
+				return
+			}
+			line, data, err := c.f.next(c.rd)
+			if err == io.EOF {
+				log.Infof("all records of %s have been read", c.f.source())
+				return
+			}
+			if err != nil {
+				c.sendErr(ctx, chErr, fmt.Errorf("failed to read replay file: %w", err))
+				return
+			}
+
+			msg, err := c.message(ctx, line, data)
+			if err != nil {
+				// an invalid record cannot be processed by any retry, so it is reported as nacked and the replay goes on
+				log.Errorf("invalid record at line %d of %s is nacked: %v", line, c.f.source(), err)
+				err = c.f.record(line, nackOutcome)
+				if err != nil {
+					c.sendErr(ctx, chErr, err)
+					return
+				}
+				continue
+			}
+			select {
+			case chMsg <- msg:
+			case <-ctx.Done():
+				msg.span.Finish()
+				c.f.unread(line, data)
+				return
+			}
+		}
+	}()
+
+	return chMsg, chErr, nil
+}
+
+func (c *consumer) sendErr(ctx context.Context, chErr chan<- error, err error) {
+	select {
+	case chErr <- err:
+	case <-ctx.Done():
+	}
+}
+
+func (c *consumer) message(ctx context.Context, line int, data []byte) (*message, error) {
+	var r Record
+	err := json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := r.payload()
+	if err != nil {
+		return nil, err
+	}
+	hh := make(map[string]string, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		hh[k] = v
+	}
+	if r.ContentType != "" {
+		hh[encoding.ContentTypeHeader] = r.ContentType
+	}
+	ct, ok := hh[encoding.ContentTypeHeader]
+	if !ok {
+		ct = patronjson.Type
+	}
+	dec, err := async.DetermineDecoder(ct)
+	if err != nil {
+		return nil, err
+	}
+
+	corID := hh[correlation.HeaderID]
+	if corID == "" {
+		corID = uuid.New().String()
+	}
+	sp, ctxCh := trace.ConsumerSpan(ctx, trace.ComponentOpName(trace.ReplayConsumerComponent, c.f.source()),
+		trace.ReplayConsumerComponent, corID, hh, opentracing.Tag{Key: "line", Value: line})
+	ctxCh = correlation.ContextWithID(ctxCh, corID)
+	ctxCh = log.WithContext(ctxCh, log.Sub(map[string]interface{}{"correlationID": corID, "line": line}))
+
+	return &message{
+		f:       c.f,
+		line:    line,
+		ctx:     ctxCh,
+		span:    sp,
+		dec:     dec,
+		payload: payload,
+		headers: hh,
+		key:     r.Key,
+		ts:      r.Timestamp,
+	}, nil
+}
+
+// Close the consumer, closing the replay file.
+func (c *consumer) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+const (
+	ackOutcome  = "ack"
+	nackOutcome = "nack"
+)
+
+type message struct {
+	f       *Factory
+	line    int
+	ctx     context.Context
+	span    opentracing.Span
+	dec     encoding.DecodeRawFunc
+	payload []byte
+	headers map[string]string
+	key     string
+	ts      time.Time
+}
+
+// Context of the message.
+func (m *message) Context() context.Context {
+	return m.ctx
+}
+
+// Decode the payload of the message to the provided argument.
+func (m *message) Decode(v interface{}) error {
+	return m.dec(m.payload, v)
+}
+
+// Ack reports the record as acked and moves the checkpoint forward.
+func (m *message) Ack() error {
+	trace.SpanSuccess(m.span)
+	return m.f.record(m.line, ackOutcome)
+}
+
+// Nack reports the record as nacked and moves the checkpoint forward, since a replay does not redeliver records.
+func (m *message) Nack() error {
+	trace.SpanError(m.span)
+	return m.f.record(m.line, nackOutcome)
+}
+
+// Metadata returns the path of the replay file, or stdin, as source, the line as ID, and the key, timestamp, headers and body of the record.
+func (m *message) Metadata() async.Metadata {
+	hh := make(map[string]string, len(m.headers))
+	for k, v := range m.headers {
+		hh[k] = v
+	}
+	return async.Metadata{
+		Source:    m.f.source(),
+		ID:        strconv.Itoa(m.line),
+		Key:       m.key,
+		Timestamp: m.ts,
+		Headers:   hh,
+		Payload:   m.payload,
+	}
+}
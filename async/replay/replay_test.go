@@ -0,0 +1,242 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/correlation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const records = `{"body":{"id":1},"key":"order-1","timestamp":"2020-01-02T03:04:05Z","headers":{"X-Correlation-Id":"123"}}
+
+{"body":{"id":2},"headers":{"X-Fail":"true"}}
+{"body":"{\"id\":3}","content_type":"application/json; charset=utf-8"}
+`
+
+func TestNew(t *testing.T) {
+	dir := tempDir(t)
+	invalid := filepath.Join(dir, "invalid")
+	require.NoError(t, ioutil.WriteFile(invalid, []byte("abc"), 0644))
+	tests := map[string]struct {
+		path    string
+		oo      []OptionFunc
+		wantErr string
+	}{
+		"success":                 {path: "replay.jsonl"},
+		"success, with options":   {path: Stdin, oo: []OptionFunc{Checkpoint(filepath.Join(dir, "checkpoint")), Report(filepath.Join(dir, "report"))}},
+		"failure, missing path":   {path: "", wantErr: "path is required"},
+		"failure, checkpoint":     {path: "replay.jsonl", oo: []OptionFunc{Checkpoint("")}, wantErr: "checkpoint path is required"},
+		"failure, report":         {path: "replay.jsonl", oo: []OptionFunc{Report("")}, wantErr: "report path is required"},
+		"failure, report dir":     {path: "replay.jsonl", oo: []OptionFunc{Report(filepath.Join(dir, "missing", "report"))}, wantErr: "failed to open report"},
+		"failure, bad checkpoint": {path: "replay.jsonl", oo: []OptionFunc{Checkpoint(invalid)}, wantErr: `invalid checkpoint "abc"`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := New(tt.path, tt.oo...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+				assert.NoError(t, got.Close())
+			}
+		})
+	}
+}
+
+func TestNew_ReportNotOpenedOnFailure(t *testing.T) {
+	dir := tempDir(t)
+	report := filepath.Join(dir, "report")
+	_, err := New("replay.jsonl", Report(report), Checkpoint(""))
+	assert.EqualError(t, err, "checkpoint path is required")
+	_, err = os.Stat(report)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecord_payload(t *testing.T) {
+	tests := map[string]struct {
+		record  string
+		want    string
+		wantErr bool
+	}{
+		"object":         {record: `{"body":{"id":1}}`, want: `{"id":1}`},
+		"string":         {record: `{"body":"plain text"}`, want: "plain text"},
+		"base64":         {record: `{"body":"aGVsbG8=","base64":true}`, want: "hello"},
+		"missing body":   {record: `{}`, want: ""},
+		"invalid base64": {record: `{"body":"###","base64":true}`, wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var r Record
+			require.NoError(t, json.Unmarshal([]byte(tt.record), &r))
+			got, err := r.payload()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestConsumer_Metadata(t *testing.T) {
+	dir := tempDir(t)
+	path := writeRecords(t, dir, records)
+	f, err := New(path)
+	require.NoError(t, err)
+	cns, err := f.Create()
+	require.NoError(t, err)
+	ctx, cnl := context.WithCancel(context.Background())
+	defer cnl()
+	chMsg, _, err := cns.Consume(ctx)
+	require.NoError(t, err)
+
+	msg := <-chMsg
+	assert.Equal(t, "123", correlation.IDFromContext(msg.Context()))
+	assert.Equal(t, async.Metadata{
+		Source:    path,
+		ID:        "1",
+		Key:       "order-1",
+		Timestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Headers:   map[string]string{correlation.HeaderID: "123"},
+		Payload:   []byte(`{"id":1}`),
+	}, msg.Metadata())
+	var got map[string]int
+	assert.NoError(t, msg.Decode(&got))
+	assert.Equal(t, map[string]int{"id": 1}, got)
+	assert.NoError(t, cns.Close())
+}
+
+func TestComponent_Replay(t *testing.T) {
+	dir := tempDir(t)
+	path := writeRecords(t, dir, records)
+	checkpoint := filepath.Join(dir, "checkpoint")
+	report := filepath.Join(dir, "report")
+
+	f, err := New(path, Checkpoint(checkpoint), Report(report))
+	require.NoError(t, err)
+	ids := runReplay(t, f)
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, []int{1, 2, 3}, ids)
+	assert.Equal(t, 4, f.Checkpoint())
+	assertCheckpoint(t, checkpoint, "4")
+	assert.Equal(t, []ReportEntry{{Line: 1, Outcome: "ack"}, {Line: 3, Outcome: "nack"}, {Line: 4, Outcome: "ack"}}, readReport(t, report))
+
+	// a replay which has completed does not process any record when resumed
+	f, err = New(path, Checkpoint(checkpoint))
+	require.NoError(t, err)
+	assert.Empty(t, runReplay(t, f))
+}
+
+func TestComponent_Resume(t *testing.T) {
+	dir := tempDir(t)
+	path := writeRecords(t, dir, records)
+	checkpoint := filepath.Join(dir, "checkpoint")
+	require.NoError(t, ioutil.WriteFile(checkpoint, []byte("3\n"), 0644))
+
+	f, err := New(path, Checkpoint(checkpoint))
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, runReplay(t, f))
+	assertCheckpoint(t, checkpoint, "4")
+}
+
+func TestComponent_InvalidRecord(t *testing.T) {
+	dir := tempDir(t)
+	path := writeRecords(t, dir, "{\"body\":{\"id\":1}}\nnot json\n{\"body\":{\"id\":2},\"content_type\":\"application/xml\"}\n{\"body\":{\"id\":3}}\n")
+	report := filepath.Join(dir, "report")
+
+	f, err := New(path, Report(report))
+	require.NoError(t, err)
+
+	// the invalid records are nacked and the replay goes on with the rest of the records
+	assert.Equal(t, []int{1, 3}, runReplay(t, f))
+	assert.Equal(t, 4, f.Checkpoint())
+	// an invalid record is reported while the previous one is processed
+	assert.ElementsMatch(t, []ReportEntry{{Line: 1, Outcome: "ack"}, {Line: 2, Outcome: "nack"}, {Line: 3, Outcome: "nack"}, {Line: 4, Outcome: "ack"}},
+		readReport(t, report))
+	assert.NoError(t, f.Close())
+}
+
+// runReplay runs an async component over the replay until it is done, returning the IDs of the processed records.
+// Records with the X-Fail header fail to be processed and are nacked.
+func runReplay(t *testing.T, f *Factory) []int {
+	var ids []int
+	proc := func(msg async.Message) error {
+		var rec struct {
+			ID int `json:"id"`
+		}
+		if err := msg.Decode(&rec); err != nil {
+			return err
+		}
+		ids = append(ids, rec.ID)
+		if msg.Metadata().Headers["X-Fail"] == "true" {
+			return assert.AnError
+		}
+		return nil
+	}
+	cmp, err := async.New("replay", f, proc).WithFailureStrategy(async.NackStrategy).Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		assert.FailNow(t, "replay is not done")
+	}
+	cnl()
+	assert.NoError(t, <-chDone)
+	return ids
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "replay")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func writeRecords(t *testing.T, dir, data string) string {
+	path := filepath.Join(dir, "replay.jsonl")
+	require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	return path
+}
+
+func assertCheckpoint(t *testing.T, path, want string) {
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, strings.TrimSpace(string(b)))
+}
+
+func readReport(t *testing.T, path string) []ReportEntry {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var entries []ReportEntry
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		var e ReportEntry
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &e))
+		assert.False(t, e.Timestamp.IsZero())
+		e.Timestamp = time.Time{}
+		entries = append(entries, e)
+	}
+	return entries
+}
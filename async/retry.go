@@ -0,0 +1,167 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/beatlabs/patron/log"
+	"github.com/opentracing/opentracing-go"
+)
+
+var errRetryAborted = errors.New("retry aborted")
+
+// RetryPolicy of the processing of a message, which is retried on failure before the failure strategy is executed.
+type RetryPolicy struct {
+	attempts  int
+	backoff   func(retry int) time.Duration
+	jitter    bool
+	retryable func(error) bool
+}
+
+// RetryOptionFunc definition for configuring the retry policy in a functional way.
+type RetryOptionFunc func(*RetryPolicy) error
+
+// NewRetryPolicy creates a retry policy with the maximum attempts of processing a message, including the first one.
+// By default the message is retried immediately, unless the error is permanent.
+func NewRetryPolicy(attempts uint, oo ...RetryOptionFunc) (*RetryPolicy, error) {
+	if attempts == 0 {
+		return nil, errors.New("attempts must be positive")
+	}
+	rp := &RetryPolicy{
+		attempts:  int(attempts),
+		backoff:   func(int) time.Duration { return 0 },
+		retryable: func(err error) bool { return !IsPermanent(err) },
+	}
+	for _, o := range oo {
+		err := o(rp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return rp, nil
+}
+
+// ConstantBackoff option for waiting the same duration before every retry.
+func ConstantBackoff(wait time.Duration) RetryOptionFunc {
+	return func(rp *RetryPolicy) error {
+		if wait <= 0 {
+			return errors.New("wait must be positive")
+		}
+		rp.backoff = func(int) time.Duration { return wait }
+		return nil
+	}
+}
+
+// ExponentialBackoff option for waiting before every retry a duration which starts at initial,
+// doubles on every retry and is capped at max.
+func ExponentialBackoff(initial, max time.Duration) RetryOptionFunc {
+	return func(rp *RetryPolicy) error {
+		if initial <= 0 {
+			return errors.New("initial wait must be positive")
+		}
+		if max < initial {
+			return errors.New("max wait must be greater or equal than the initial wait")
+		}
+		rp.backoff = func(retry int) time.Duration {
+			wait := initial
+			for i := 1; i < retry && wait < max; i++ {
+				wait *= 2
+			}
+			if wait > max {
+				return max
+			}
+			return wait
+		}
+		return nil
+	}
+}
+
+// Jitter option for randomizing every wait of the backoff between its half and its full duration,
+// so that the retries of several instances are spread out.
+func Jitter() RetryOptionFunc {
+	return func(rp *RetryPolicy) error {
+		rp.jitter = true
+		return nil
+	}
+}
+
+// Classifier option for deciding which errors are retryable, instead of retrying all errors except the permanent ones.
+func Classifier(retryable func(error) bool) RetryOptionFunc {
+	return func(rp *RetryPolicy) error {
+		if retryable == nil {
+			return errors.New("classifier is required")
+		}
+		rp.retryable = retryable
+		return nil
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string {
+	return pe.err.Error()
+}
+
+func (pe *permanentError) Unwrap() error {
+	return pe.err
+}
+
+// Permanent wraps an error of a processor in order to signal that the message should not be retried.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if the error, or any error it wraps, has been marked as permanent.
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+// wait returns the duration to wait before the retry.
+func (rp *RetryPolicy) wait(retry int) time.Duration {
+	wait := rp.backoff(retry)
+	if rp.jitter && wait > 1 {
+		wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+	return wait
+}
+
+// process processes the message until it succeeds, fails with a non retryable error or the attempts are exhausted,
+// and returns the number of retries along with the last error.
+// The attempts are recorded on the span of the message, if any.
+// The errRetryAborted is returned if the context is cancelled while waiting to retry.
+func (rp *RetryPolicy) process(ctx context.Context, msg Message, proc ProcessorFunc) (int, error) {
+	if rp == nil {
+		return 0, proc(msg)
+	}
+	retry := 0
+	for {
+		err := proc(msg)
+		if err == nil || retry+1 >= rp.attempts || !rp.retryable(err) {
+			setAttemptsTag(msg, retry+1)
+			return retry, err
+		}
+		retry++
+		wait := rp.wait(retry)
+		log.FromContext(msg.Context()).Warnf("failed to process message, retry %d/%d in %v: %v", retry, rp.attempts-1, wait, err)
+		select {
+		case <-ctx.Done():
+			setAttemptsTag(msg, retry)
+			return retry - 1, errRetryAborted
+		case <-time.After(wait):
+		}
+	}
+}
+
+func setAttemptsTag(msg Message, attempts int) {
+	if sp := opentracing.SpanFromContext(msg.Context()); sp != nil {
+		sp.SetTag("attempts", attempts)
+	}
+}
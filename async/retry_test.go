@@ -0,0 +1,197 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/metrics"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	tests := map[string]struct {
+		attempts uint
+		oo       []RetryOptionFunc
+		wantErr  string
+	}{
+		"success":                                {attempts: 3},
+		"success, with options":                  {attempts: 3, oo: []RetryOptionFunc{ExponentialBackoff(time.Millisecond, time.Second), Jitter(), Classifier(func(error) bool { return true })}},
+		"failure, zero attempts":                 {attempts: 0, wantErr: "attempts must be positive"},
+		"failure, invalid constant backoff":      {attempts: 3, oo: []RetryOptionFunc{ConstantBackoff(0)}, wantErr: "wait must be positive"},
+		"failure, invalid exponential backoff":   {attempts: 3, oo: []RetryOptionFunc{ExponentialBackoff(0, time.Second)}, wantErr: "initial wait must be positive"},
+		"failure, max less than initial backoff": {attempts: 3, oo: []RetryOptionFunc{ExponentialBackoff(time.Second, time.Millisecond)}, wantErr: "max wait must be greater or equal than the initial wait"},
+		"failure, nil classifier":                {attempts: 3, oo: []RetryOptionFunc{Classifier(nil)}, wantErr: "classifier is required"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewRetryPolicy(tt.attempts, tt.oo...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_wait(t *testing.T) {
+	rp, err := NewRetryPolicy(5)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), rp.wait(1))
+
+	rp, err = NewRetryPolicy(5, ConstantBackoff(10*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Millisecond, rp.wait(1))
+	assert.Equal(t, 10*time.Millisecond, rp.wait(4))
+
+	rp, err = NewRetryPolicy(5, ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond))
+	require.NoError(t, err)
+	var got []time.Duration
+	for i := 1; i <= 5; i++ {
+		got = append(got, rp.wait(i))
+	}
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}, got)
+
+	rp, err = NewRetryPolicy(5, ConstantBackoff(10*time.Millisecond), Jitter())
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		wait := rp.wait(1)
+		assert.True(t, wait >= 5*time.Millisecond && wait <= 10*time.Millisecond, "wait %v out of range", wait)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	assert.Nil(t, Permanent(nil))
+	err := Permanent(errProcess)
+	assert.EqualError(t, err, errProcess.Error())
+	assert.True(t, IsPermanent(err))
+	assert.True(t, errors.Is(err, errProcess))
+	assert.False(t, IsPermanent(errProcess))
+}
+
+func TestRun_RetryPolicy(t *testing.T) {
+	errPermanent := Permanent(errProcess)
+	tests := map[string]struct {
+		errs           []error
+		wantExecs      int
+		wantAcked      bool
+		wantRetries    int
+		wantOutcome    string
+		wantDeadLetter bool
+	}{
+		"success after retries": {
+			errs:        []error{errProcess, errProcess, nil},
+			wantExecs:   3,
+			wantAcked:   true,
+			wantRetries: 2,
+			wantOutcome: "success",
+		},
+		"failure after exhausting the attempts": {
+			errs:           []error{errProcess, errProcess, errProcess, nil},
+			wantExecs:      3,
+			wantAcked:      true,
+			wantRetries:    2,
+			wantOutcome:    "failure",
+			wantDeadLetter: true,
+		},
+		"failure without retries on permanent error": {
+			errs:           []error{errPermanent, nil},
+			wantExecs:      1,
+			wantAcked:      true,
+			wantDeadLetter: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mtr := mocktracer.New()
+			sp := mtr.StartSpan("test").(*mocktracer.MockSpan)
+			msg := &rawMessage{mockMessage: mockMessage{ctx: opentracing.ContextWithSpan(context.Background(), sp)}}
+			execs := 0
+			proc := func(Message) error {
+				err := tt.errs[execs]
+				execs++
+				return err
+			}
+			var published *DeadLetter
+			dlp := DeadLetterPublisherFunc(func(_ context.Context, dl *DeadLetter) error {
+				published = dl
+				return nil
+			})
+			rp, err := NewRetryPolicy(3, ConstantBackoff(time.Millisecond))
+			require.NoError(t, err)
+			mr, err := metrics.NewRegistry()
+			require.NoError(t, err)
+
+			cnr := mockConsumer{chMsg: make(chan Message, 1), chErr: make(chan error)}
+			cnr.chMsg <- msg
+			cmp, err := New("test", &mockConsumerFactory{c: &cnr}, proc).
+				WithFailureStrategy(DeadLetterStrategy).
+				WithDeadLetter(dlp).
+				WithRetryPolicy(rp).
+				WithMetrics(mr).
+				Create()
+			require.NoError(t, err)
+			assert.Equal(t, 3, cmp.Info()["message_attempts"])
+
+			ctx, cnl := context.WithCancel(context.Background())
+			chDone := make(chan error, 1)
+			go func() {
+				chDone <- cmp.Run(ctx)
+			}()
+			time.Sleep(50 * time.Millisecond)
+			cnl()
+			assert.NoError(t, <-chDone)
+
+			assert.Equal(t, tt.wantExecs, execs)
+			assert.Equal(t, tt.wantAcked, msg.acked)
+			assert.Equal(t, tt.wantExecs, sp.Tag("attempts"))
+			if tt.wantDeadLetter {
+				require.NotNil(t, published)
+				assert.Equal(t, tt.wantRetries, published.Retries)
+			} else {
+				assert.Nil(t, published)
+			}
+			if tt.wantOutcome != "" {
+				assert.Contains(t, scrape(t, mr), fmt.Sprintf(`component_async_message_retries{name="test",outcome="%s"} %d`, tt.wantOutcome, tt.wantRetries))
+			} else {
+				assert.NotContains(t, scrape(t, mr), "component_async_message_retries{")
+			}
+		})
+	}
+}
+
+// TestRun_RetryPolicy_Shutdown verifies that the message is nacked when the component stops while waiting to retry it
+func TestRun_RetryPolicy_Shutdown(t *testing.T) {
+	msg := &mockMessage{ctx: context.Background()}
+	proc := mockProcessor{errReturn: true}
+	rp, err := NewRetryPolicy(3, ConstantBackoff(time.Minute))
+	require.NoError(t, err)
+
+	cnr := mockConsumer{chMsg: make(chan Message, 1), chErr: make(chan error)}
+	cnr.chMsg <- msg
+	cmp, err := New("test", &mockConsumerFactory{c: &cnr}, proc.Process).WithRetryPolicy(rp).Create()
+	require.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chDone := make(chan error, 1)
+	go func() {
+		chDone <- cmp.Run(ctx)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cnl()
+	assert.NoError(t, <-chDone)
+	assert.Equal(t, 1, proc.execs)
+	assert.True(t, msg.nacked)
+	assert.False(t, msg.acked)
+
+	_, err = New("test", &mockConsumerFactory{c: &cnr}, proc.Process).WithRetryPolicy(nil).Create()
+	assert.EqualError(t, err, "nil retry policy provided\n")
+}
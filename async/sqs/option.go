@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"github.com/beatlabs/patron/metrics"
 )
 
 const twelveHoursInSeconds = 43200
@@ -69,3 +71,15 @@ func QueueStatsInterval(interval time.Duration) OptionFunc {
 		return nil
 	}
 }
+
+// Metrics option for setting the registry of the metrics of the consumer,
+// instead of the default registry of the metrics package at the time the consumer is created.
+func Metrics(mr *metrics.Registry) OptionFunc {
+	return func(f *Factory) error {
+		if mr == nil {
+			return errors.New("metrics registry is nil")
+		}
+		f.mr = mr
+		return nil
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/beatlabs/patron/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -174,3 +175,33 @@ func TestQueueStatsInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestMetrics(t *testing.T) {
+	mr, err := metrics.NewRegistry()
+	require.NoError(t, err)
+	tests := map[string]struct {
+		mr          *metrics.Registry
+		expectedErr string
+	}{
+		"success": {
+			mr: mr,
+		},
+		"nil registry": {
+			mr:          nil,
+			expectedErr: "metrics registry is nil",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := NewFactory(&stubQueue{}, "queue")
+			require.NoError(t, err)
+			err = Metrics(tt.mr)(f)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mr, f.mr)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -14,7 +15,9 @@ import (
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/beatlabs/patron/trace"
 	"github.com/google/uuid"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -28,6 +31,8 @@ const (
 	sqsAttributeApproximateNumberOfMessagesDelayed    = "ApproximateNumberOfMessagesDelayed"
 	sqsAttributeApproximateNumberOfMessagesNotVisible = "ApproximateNumberOfMessagesNotVisible"
 	sqsAttributeSentTimestamp                         = "SentTimestamp"
+	sqsAttributeMessageGroupID                        = "MessageGroupId"
+	sqsAttributeApproximateReceiveCount               = "ApproximateReceiveCount"
 
 	sqsMessageAttributeAll = "All"
 
@@ -36,41 +41,51 @@ const (
 	fetchedMessageState messageState = "FETCHED"
 )
 
-var messageAge *prometheus.GaugeVec
-var messageCounter *prometheus.CounterVec
-var queueSize *prometheus.GaugeVec
+// consumerMetrics are the metrics of a consumer, which are shared by the consumers of the same registry.
+type consumerMetrics struct {
+	messageAge     *prometheus.GaugeVec
+	messageCounter *prometheus.CounterVec
+	queueSize      *prometheus.GaugeVec
+}
 
-func init() {
-	messageAge = prometheus.NewGaugeVec(
+func newConsumerMetrics(mr *metrics.Registry) (*consumerMetrics, error) {
+	messageAge, err := mr.GaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "component",
 			Subsystem: "sqs_consumer",
 			Name:      "message_age",
 			Help:      "Message age based on the SentTimestamp SQS attribute",
 		},
-		[]string{"queue"},
+		"queue",
 	)
-	prometheus.MustRegister(messageAge)
-	messageCounter = prometheus.NewCounterVec(
+	if err != nil {
+		return nil, err
+	}
+	messageCounter, err := mr.CounterVec(
 		prometheus.CounterOpts{
 			Namespace: "component",
 			Subsystem: "sqs_consumer",
 			Name:      "message_counter",
 			Help:      "Message counter",
 		},
-		[]string{"queue", "state", "hasError"},
+		"queue", "state", "hasError",
 	)
-	prometheus.MustRegister(messageCounter)
-	queueSize = prometheus.NewGaugeVec(
+	if err != nil {
+		return nil, err
+	}
+	queueSize, err := mr.GaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "component",
 			Subsystem: "sqs_consumer",
 			Name:      "queue_size",
 			Help:      "Queue size reported by AWS",
 		},
-		[]string{"state"},
+		"queue", "state",
 	)
-	prometheus.MustRegister(queueSize)
+	if err != nil {
+		return nil, err
+	}
+	return &consumerMetrics{messageAge: messageAge, messageCounter: messageCounter, queueSize: queueSize}, nil
 }
 
 type message struct {
@@ -81,6 +96,7 @@ type message struct {
 	msg       *sqs.Message
 	span      opentracing.Span
 	dec       encoding.DecodeRawFunc
+	metrics   *consumerMetrics
 }
 
 // Context of the message.
@@ -100,10 +116,10 @@ func (m *message) Ack() error {
 		ReceiptHandle: m.msg.ReceiptHandle,
 	})
 	if err != nil {
-		messageCountErrorInc(m.queueName, ackMessageState, 1)
+		m.metrics.messageCountErrorInc(m.queueName, ackMessageState, 1)
 		return nil
 	}
-	messageCountInc(m.queueName, ackMessageState, 1)
+	m.metrics.messageCountInc(m.queueName, ackMessageState, 1)
 	trace.SpanSuccess(m.span)
 	return nil
 }
@@ -112,11 +128,43 @@ func (m *message) Ack() error {
 // We could investigate to support ChangeMessageVisibility which could be used to make the message visible again sooner
 // than the visibility timeout.
 func (m *message) Nack() error {
-	messageCountInc(m.queueName, nackMessageState, 1)
+	m.metrics.messageCountInc(m.queueName, nackMessageState, 1)
 	trace.SpanError(m.span)
 	return nil
 }
 
+// Metadata returns the queue name, message ID, sent timestamp, string message attributes as headers, system attributes,
+// approximate receive count and body of the message.
+func (m *message) Metadata() async.Metadata {
+	md := async.Metadata{
+		Source:     m.queueName,
+		ID:         aws.StringValue(m.msg.MessageId),
+		Headers:    mapHeader(m.msg.MessageAttributes),
+		Attributes: make(map[string]string, len(m.msg.Attributes)),
+		Payload:    []byte(aws.StringValue(m.msg.Body)),
+	}
+	for k, v := range m.msg.Attributes {
+		md.Attributes[k] = aws.StringValue(v)
+	}
+	if ts, err := strconv.ParseInt(md.Attributes[sqsAttributeSentTimestamp], 10, 64); err == nil {
+		md.Timestamp = time.Unix(0, ts*int64(time.Millisecond)).UTC()
+	}
+	if count, err := strconv.Atoi(md.Attributes[sqsAttributeApproximateReceiveCount]); err == nil {
+		md.DeliveryCount = count
+	}
+	return md
+}
+
+// MessageGroupID returns the message group of a SQS FIFO queue message, in order to be used as the ordering key of the async component.
+// An empty group is returned for messages of standard queues and any other message.
+func MessageGroupID(msg async.Message) string {
+	m, ok := msg.(*message)
+	if !ok {
+		return ""
+	}
+	return aws.StringValue(m.msg.Attributes[sqsAttributeMessageGroupID])
+}
+
 // Factory for creating SQS consumers.
 type Factory struct {
 	queueName         string
@@ -127,6 +175,7 @@ type Factory struct {
 	visibilityTimeout int64
 	buffer            int
 	statsInterval     time.Duration
+	mr                *metrics.Registry
 }
 
 // NewFactory creates a new consumer factory.
@@ -167,8 +216,42 @@ func NewFactory(queue sqsiface.SQSAPI, queueName string, oo ...OptionFunc) (*Fac
 	return f, nil
 }
 
+// Info returns information about the consumer configuration.
+func (f *Factory) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"type":               "sqs",
+		"queue":              f.queueName,
+		"queue_url":          f.queueURL,
+		"max_messages":       f.maxMessages,
+		"poll_wait_seconds":  f.pollWaitSeconds,
+		"visibility_timeout": f.visibilityTimeout,
+		"buffer":             f.buffer,
+	}
+}
+
+// HealthCheck returns a health check of the queue, which succeeds when its URL can be retrieved.
+func (f *Factory) HealthCheck() health.Check {
+	return func(ctx context.Context) error {
+		_, err := f.queue.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{
+			QueueName: aws.String(f.queueName),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get URL of queue %s: %w", f.queueName, err)
+		}
+		return nil
+	}
+}
+
 // Create a new SQS consumer.
 func (f *Factory) Create() (async.Consumer, error) {
+	mr := f.mr
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	cm, err := newConsumerMetrics(mr)
+	if err != nil {
+		return nil, err
+	}
 	return &consumer{
 		queueName:         f.queueName,
 		queue:             f.queue,
@@ -178,10 +261,12 @@ func (f *Factory) Create() (async.Consumer, error) {
 		buffer:            f.buffer,
 		visibilityTimeout: f.visibilityTimeout,
 		statsInterval:     f.statsInterval,
+		metrics:           cm,
 	}, nil
 }
 
 type consumer struct {
+	available         int64
 	queueName         string
 	queueURL          string
 	queue             sqsiface.SQSAPI
@@ -190,7 +275,26 @@ type consumer struct {
 	visibilityTimeout int64
 	buffer            int
 	statsInterval     time.Duration
+	metrics           *consumerMetrics
 	cnl               context.CancelFunc
+	gate              async.PauseGate
+}
+
+// Health reports the approximate number of available messages of the queue as the lag, as of the latest queue stats.
+func (c *consumer) Health() async.ConsumerHealth {
+	return async.ConsumerHealth{Assigned: true, Lag: atomic.LoadInt64(&c.available)}
+}
+
+// Pause stops the consumer from polling the queue, after the messages which have been received are delivered.
+func (c *consumer) Pause() error {
+	c.gate.Pause()
+	return nil
+}
+
+// Resume resumes polling the queue.
+func (c *consumer) Resume() error {
+	c.gate.Resume()
+	return nil
 }
 
 // Consume messages from SQS and send them to the channel.
@@ -202,7 +306,7 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 
 	go func() {
 		for {
-			if sqsCtx.Err() != nil {
+			if c.gate.Wait(sqsCtx) != nil {
 				return
 			}
 			log.Debugf("polling SQS queue %s for messages", c.queueName)
@@ -213,6 +317,8 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 				VisibilityTimeout:   aws.Int64(c.visibilityTimeout),
 				AttributeNames: aws.StringSlice([]string{
 					sqsAttributeSentTimestamp,
+					sqsAttributeMessageGroupID,
+					sqsAttributeApproximateReceiveCount,
 				}),
 				MessageAttributeNames: aws.StringSlice([]string{
 					sqsMessageAttributeAll,
@@ -226,10 +332,10 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 				return
 			}
 
-			messageCountInc(c.queueName, fetchedMessageState, len(output.Messages))
+			c.metrics.messageCountInc(c.queueName, fetchedMessageState, len(output.Messages))
 
 			for _, msg := range output.Messages {
-				observerMessageAge(c.queueName, msg.Attributes)
+				c.metrics.observerMessageAge(c.queueName, msg.Attributes)
 
 				corID := getCorrelationID(msg.MessageAttributes)
 
@@ -242,7 +348,7 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 
 				ct, err := determineContentType(msg.MessageAttributes)
 				if err != nil {
-					messageCountErrorInc(c.queueName, fetchedMessageState, 1)
+					c.metrics.messageCountErrorInc(c.queueName, fetchedMessageState, 1)
 					trace.SpanError(sp)
 					logger.Errorf("failed to determine content type: %v", err)
 					continue
@@ -250,7 +356,7 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 
 				dec, err := async.DetermineDecoder(ct)
 				if err != nil {
-					messageCountErrorInc(c.queueName, fetchedMessageState, 1)
+					c.metrics.messageCountErrorInc(c.queueName, fetchedMessageState, 1)
 					trace.SpanError(sp)
 					logger.Errorf("failed to determine decoder: %v", err)
 					continue
@@ -264,6 +370,7 @@ func (c *consumer) Consume(ctx context.Context) (<-chan async.Message, <-chan er
 					ctx:       ctxCh,
 					queue:     c.queue,
 					dec:       dec,
+					metrics:   c.metrics,
 				}
 			}
 		}
@@ -309,19 +416,20 @@ func (c *consumer) reportQueueStats(ctx context.Context, queueURL string) error
 	if err != nil {
 		return err
 	}
-	queueSize.WithLabelValues("available").Set(size)
+	c.metrics.queueSize.WithLabelValues(c.queueName, "available").Set(size)
+	atomic.StoreInt64(&c.available, int64(size))
 
 	size, err = getAttributeFloat64(rsp.Attributes, sqsAttributeApproximateNumberOfMessagesDelayed)
 	if err != nil {
 		return err
 	}
-	queueSize.WithLabelValues("delayed").Set(size)
+	c.metrics.queueSize.WithLabelValues(c.queueName, "delayed").Set(size)
 
 	size, err = getAttributeFloat64(rsp.Attributes, sqsAttributeApproximateNumberOfMessagesNotVisible)
 	if err != nil {
 		return err
 	}
-	queueSize.WithLabelValues("invisible").Set(size)
+	c.metrics.queueSize.WithLabelValues(c.queueName, "invisible").Set(size)
 	return nil
 }
 
@@ -371,7 +479,7 @@ func mapHeader(ma map[string]*sqs.MessageAttributeValue) map[string]string {
 	return mp
 }
 
-func observerMessageAge(queue string, attributes map[string]*string) {
+func (cm *consumerMetrics) observerMessageAge(queue string, attributes map[string]*string) {
 	attribute, ok := attributes[sqsAttributeSentTimestamp]
 	if !ok || attribute == nil {
 		return
@@ -380,13 +488,13 @@ func observerMessageAge(queue string, attributes map[string]*string) {
 	if err != nil {
 		return
 	}
-	messageAge.WithLabelValues(queue).Set(time.Now().UTC().Sub(time.Unix(timestamp, 0)).Seconds())
+	cm.messageAge.WithLabelValues(queue).Set(time.Now().UTC().Sub(time.Unix(timestamp, 0)).Seconds())
 }
 
-func messageCountInc(queue string, state messageState, count int) {
-	messageCounter.WithLabelValues(queue, string(state), "false").Add(float64(count))
+func (cm *consumerMetrics) messageCountInc(queue string, state messageState, count int) {
+	cm.messageCounter.WithLabelValues(queue, string(state), "false").Add(float64(count))
 }
 
-func messageCountErrorInc(queue string, state messageState, count int) {
-	messageCounter.WithLabelValues(queue, string(state), "true").Add(float64(count))
+func (cm *consumerMetrics) messageCountErrorInc(queue string, state messageState, count int) {
+	cm.messageCounter.WithLabelValues(queue, string(state), "true").Add(float64(count))
 }
@@ -11,8 +11,10 @@ import (
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/beatlabs/patron/async"
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/metrics"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -100,6 +102,24 @@ func TestFactory_Create(t *testing.T) {
 	assert.Nil(t, cons.cnl)
 }
 
+func TestFactory_Info(t *testing.T) {
+	f, err := NewFactory(&stubQueue{}, "queueName")
+	require.NoError(t, err)
+	info := f.Info()
+	assert.Equal(t, "sqs", info["type"])
+	assert.Equal(t, "queueName", info["queue"])
+	assert.Equal(t, "URL", info["queue_url"])
+}
+
+func TestFactory_HealthCheck(t *testing.T) {
+	queue := &stubQueue{}
+	f, err := NewFactory(queue, "queue")
+	assert.NoError(t, err)
+	assert.NoError(t, f.HealthCheck()(context.Background()))
+	queue.getQueueURLErr = errors.New("TEST")
+	assert.EqualError(t, f.HealthCheck()(context.Background()), "failed to get URL of queue queue: TEST")
+}
+
 func Test_consumer_Consume(t *testing.T) {
 	f, err := NewFactory(&stubQueue{}, "queueName", QueueStatsInterval(10*time.Millisecond))
 	require.NoError(t, err)
@@ -115,6 +135,67 @@ func Test_consumer_Consume(t *testing.T) {
 	require.NoError(t, cns.Close())
 }
 
+func Test_consumer_PauseResume(t *testing.T) {
+	f, err := NewFactory(&stubQueue{}, "queueName")
+	require.NoError(t, err)
+	cns, err := f.Create()
+	require.NoError(t, err)
+	p, ok := cns.(async.PausableConsumer)
+	require.True(t, ok)
+	require.NoError(t, p.Pause())
+	chMsg, _, err := cns.Consume(context.Background())
+	require.NoError(t, err)
+
+	select {
+	case <-chMsg:
+		assert.FailNow(t, "queue polled while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.NoError(t, p.Resume())
+	assert.NotNil(t, <-chMsg)
+	require.NoError(t, cns.Close())
+}
+
+func Test_consumer_reportQueueStats(t *testing.T) {
+	mr, err := metrics.NewRegistry(metrics.Namespace("test"))
+	require.NoError(t, err)
+	sizes := map[string]string{"first": "1", "second": "5"}
+	for queueName, size := range sizes {
+		queue := &stubQueue{queueAttributes: map[string]*string{
+			sqsAttributeApproximateNumberOfMessages:           aws.String(size),
+			sqsAttributeApproximateNumberOfMessagesDelayed:    aws.String("0"),
+			sqsAttributeApproximateNumberOfMessagesNotVisible: aws.String("0"),
+		}}
+		f, err := NewFactory(queue, queueName, Metrics(mr))
+		require.NoError(t, err)
+		cns, err := f.Create()
+		require.NoError(t, err)
+		require.NoError(t, cns.(*consumer).reportQueueStats(context.Background(), "URL"))
+		lag, err := strconv.ParseInt(size, 10, 64)
+		require.NoError(t, err)
+		assert.Equal(t, async.ConsumerHealth{Assigned: true, Lag: lag}, cns.(async.HealthReporter).Health())
+	}
+
+	mff, err := mr.Gatherer().Gather()
+	require.NoError(t, err)
+	got := map[string]float64{}
+	for _, mf := range mff {
+		if mf.GetName() != "test_component_sqs_consumer_queue_size" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			ll := map[string]string{}
+			for _, lp := range m.GetLabel() {
+				ll[lp.GetName()] = lp.GetValue()
+			}
+			if ll["state"] == "available" {
+				got[ll["queue"]] = m.GetGauge().GetValue()
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{"first": 1, "second": 5}, got)
+}
+
 func Test_message(t *testing.T) {
 	type fields struct {
 		queue sqsiface.SQSAPI
@@ -129,6 +210,10 @@ func Test_message(t *testing.T) {
 			fields: fields{queue: &stubQueue{deleteMessageWithContextErr: errors.New("ERROR")}},
 		},
 	}
+	mr, err := metrics.NewRegistry()
+	require.NoError(t, err)
+	cm, err := newConsumerMetrics(mr)
+	require.NoError(t, err)
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			m := &message{
@@ -136,9 +221,18 @@ func Test_message(t *testing.T) {
 				queueURL:  "queueURL",
 				queueName: "queueName",
 				ctx:       context.Background(),
-				msg:       &sqs.Message{Body: aws.String(`{"key":"value"}`)},
-				span:      opentracing.StartSpan("test"),
-				dec:       json.DecodeRaw,
+				msg: &sqs.Message{
+					MessageId:         aws.String("1"),
+					Body:              aws.String(`{"key":"value"}`),
+					MessageAttributes: map[string]*sqs.MessageAttributeValue{"key": {StringValue: aws.String("value")}},
+					Attributes: map[string]*string{
+						sqsAttributeSentTimestamp:           aws.String("1577934245000"),
+						sqsAttributeApproximateReceiveCount: aws.String("3"),
+					},
+				},
+				span:    opentracing.StartSpan("test"),
+				dec:     json.DecodeRaw,
+				metrics: cm,
 			}
 			assert.NoError(t, m.Ack())
 			assert.NoError(t, m.Nack())
@@ -146,10 +240,29 @@ func Test_message(t *testing.T) {
 			var mp map[string]string
 			assert.NoError(t, m.Decode(&mp))
 			assert.Equal(t, map[string]string{"key": "value"}, mp)
+			assert.Equal(t, async.Metadata{
+				Source:    "queueName",
+				ID:        "1",
+				Timestamp: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				Headers:   map[string]string{"key": "value"},
+				Attributes: map[string]string{
+					sqsAttributeSentTimestamp:           "1577934245000",
+					sqsAttributeApproximateReceiveCount: "3",
+				},
+				DeliveryCount: 3,
+				Payload:       []byte(`{"key":"value"}`),
+			}, m.Metadata())
 		})
 	}
 }
 
+func TestMessageGroupID(t *testing.T) {
+	msg := &message{msg: &sqs.Message{Attributes: map[string]*string{sqsAttributeMessageGroupID: aws.String("order-1")}}}
+	assert.Equal(t, "order-1", MessageGroupID(msg))
+	assert.Equal(t, "", MessageGroupID(&message{msg: &sqs.Message{}}))
+	assert.Equal(t, "", MessageGroupID(nil))
+}
+
 func Test_getCorrelationID(t *testing.T) {
 	withID := map[string]*sqs.MessageAttributeValue{correlation.HeaderID: {StringValue: aws.String("123")}}
 	withoutID := map[string]*sqs.MessageAttributeValue{correlation.HeaderID: {}}
@@ -176,6 +289,7 @@ type stubQueue struct {
 	receiveMessageWithContextErr     error
 	getQueueAttributesWithContextErr error
 	deleteMessageWithContextErr      error
+	queueAttributes                  map[string]*string
 }
 
 func (s stubQueue) AddPermission(*sqs.AddPermissionInput) (*sqs.AddPermissionOutput, error) {
@@ -273,6 +387,9 @@ func (s stubQueue) GetQueueAttributesWithContext(aws.Context, *sqs.GetQueueAttri
 	if s.getQueueAttributesWithContextErr != nil {
 		return nil, s.getQueueAttributesWithContextErr
 	}
+	if s.queueAttributes != nil {
+		return &sqs.GetQueueAttributesOutput{Attributes: s.queueAttributes}, nil
+	}
 	return &sqs.GetQueueAttributesOutput{
 		Attributes: map[string]*string{
 			sqsAttributeApproximateNumberOfMessages:           aws.String("1"),
@@ -297,8 +414,8 @@ func (s stubQueue) GetQueueUrl(*sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, e
 }
 
 //nolint:golint
-func (s stubQueue) GetQueueUrlWithContext(aws.Context, *sqs.GetQueueUrlInput, ...request.Option) (*sqs.GetQueueUrlOutput, error) {
-	panic("implement me")
+func (s stubQueue) GetQueueUrlWithContext(_ aws.Context, in *sqs.GetQueueUrlInput, _ ...request.Option) (*sqs.GetQueueUrlOutput, error) {
+	return s.GetQueueUrl(in)
 }
 
 //nolint:golint
@@ -0,0 +1,123 @@
+package patron
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/beatlabs/patron/config"
+	"github.com/beatlabs/patron/log"
+	jaeger "github.com/uber/jaeger-client-go"
+)
+
+const configFileEnv = "PATRON_CONFIG_FILE"
+
+const (
+	jaegerProvider = "jaeger"
+	zipkinProvider = "zipkin"
+	noopProvider   = "noop"
+	// customProvider is reported as the tracing provider when it is set with the Tracing option.
+	customProvider = "custom"
+)
+
+// serviceConfig is the configuration of the service, which is loaded from env vars
+// and the optional JSON or YAML file set with the PATRON_CONFIG_FILE env var.
+type serviceConfig struct {
+	LogLevel            string        `config:"log_level" env:"PATRON_LOG_LEVEL" default:"info"`
+	HTTPPort            int           `config:"http_default_port" env:"PATRON_HTTP_DEFAULT_PORT" default:"50000"`
+	ManagementPort      int           `config:"http_management_port" env:"PATRON_HTTP_MANAGEMENT_PORT" default:"0"`
+	ShutdownGracePeriod time.Duration `config:"shutdown_grace_period" env:"PATRON_SHUTDOWN_GRACE_PERIOD" default:"20s"`
+	TracingProvider     string        `config:"tracing_provider" env:"PATRON_TRACING_PROVIDER" default:"jaeger"`
+	Jaeger              jaegerConfig  `config:"jaeger"`
+	Zipkin              zipkinConfig  `config:"zipkin"`
+	MetricsNamespace    string        `config:"metrics_namespace" env:"PATRON_METRICS_NAMESPACE"`
+}
+
+// Validate validates the service configuration.
+func (c *serviceConfig) Validate() error {
+	switch log.Level(c.LogLevel) {
+	case log.DebugLevel, log.InfoLevel, log.WarnLevel, log.ErrorLevel, log.FatalLevel, log.PanicLevel:
+	default:
+		return fmt.Errorf("log level %q is not valid", c.LogLevel)
+	}
+	if c.HTTPPort <= 0 || c.HTTPPort > 65535 {
+		return fmt.Errorf("HTTP default port %d is not valid", c.HTTPPort)
+	}
+	if c.ManagementPort < 0 || c.ManagementPort > 65535 || c.ManagementPort == c.HTTPPort {
+		return fmt.Errorf("HTTP management port %d is not valid", c.ManagementPort)
+	}
+	if c.ShutdownGracePeriod <= 0 {
+		return errors.New("shutdown grace period must be positive")
+	}
+	switch c.TracingProvider {
+	case jaegerProvider, zipkinProvider, noopProvider:
+	default:
+		return fmt.Errorf("tracing provider %q is not valid", c.TracingProvider)
+	}
+	return nil
+}
+
+type jaegerConfig struct {
+	AgentHost    string  `config:"agent_host" env:"PATRON_JAEGER_AGENT_HOST" default:"0.0.0.0"`
+	AgentPort    string  `config:"agent_port" env:"PATRON_JAEGER_AGENT_PORT" default:"6831"`
+	SamplerType  string  `config:"sampler_type" env:"PATRON_JAEGER_SAMPLER_TYPE" default:"probabilistic"`
+	SamplerParam float64 `config:"sampler_param" env:"PATRON_JAEGER_SAMPLER_PARAM" default:"0.0"`
+}
+
+// Validate validates the Jaeger configuration.
+func (c *jaegerConfig) Validate() error {
+	switch c.SamplerType {
+	case jaeger.SamplerTypeConst, jaeger.SamplerTypeProbabilistic, jaeger.SamplerTypeRateLimiting, jaeger.SamplerTypeRemote:
+	default:
+		return fmt.Errorf("jaeger sampler type %q is not valid", c.SamplerType)
+	}
+	if c.SamplerParam < 0 {
+		return errors.New("jaeger sampler param must not be negative")
+	}
+	return nil
+}
+
+// zipkinConfig is the configuration of the Zipkin tracing provider, which uses the sampler settings of the Jaeger configuration.
+type zipkinConfig struct {
+	URL string `config:"url" env:"PATRON_ZIPKIN_URL" default:"http://localhost:9411/api/v1/spans"`
+}
+
+// loadServiceConfig loads the service configuration.
+func loadServiceConfig() (*serviceConfig, error) {
+	var oo []config.OptionFunc
+	if file, ok := os.LookupEnv(configFileEnv); ok {
+		oo = append(oo, config.File(file))
+	}
+	cfg := serviceConfig{}
+	err := config.Load(&cfg, oo...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service configuration: %w", err)
+	}
+	return &cfg, nil
+}
+
+// effectiveConfig returns the effective configuration of the service and the application, with the secrets masked.
+// The service configuration reflects the options which override it, and a tracing provider set with the Tracing option
+// is reported as custom, without the Jaeger and Zipkin configuration which is not used.
+func (s *Service) effectiveConfig() map[string]interface{} {
+	cfg := map[string]interface{}{}
+	svc, err := config.Masked(s.config())
+	if err == nil {
+		svc["shutdown_grace_period"] = s.gracePeriod.String()
+		svc["http_management_port"] = s.mgmtPort
+		if s.tp != nil {
+			svc["tracing_provider"] = customProvider
+			delete(svc, "jaeger")
+			delete(svc, "zipkin")
+		}
+		cfg["service"] = svc
+	}
+	if s.appCfg != nil {
+		app, err := config.Masked(s.appCfg)
+		if err == nil {
+			cfg["application"] = app
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,357 @@
+// Package config provides loading of struct based configuration from defaults, JSON or YAML files and env vars.
+//
+// The configuration is described with the following struct field tags:
+//
+//   - config, the key of the field in files and in the masked output, which defaults to the lower case field name
+//   - env, the env var which overrides the value of the field
+//   - default, the value of the field when it is not provided by a file or an env var
+//   - required, when set to true the value has to be provided by a default, a file or an env var
+//   - secret, when set to true the value is masked in the output of Masked
+//
+// Values from files override the defaults and env vars override the values from files.
+// Supported field types are string, bool, integers, floats, time.Duration, string slices
+// (comma separated in env vars and defaults) and nested structs.
+// If the configuration struct, or a nested struct, implements Validator, it is validated after loading.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const maskedValue = "xxxxx"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Validator can be optionally implemented by a configuration struct in order to validate the loaded values.
+type Validator interface {
+	Validate() error
+}
+
+// OptionFunc definition for configuring the loading in a functional way.
+type OptionFunc func(*loader) error
+
+type loader struct {
+	files  []string
+	lookup func(string) (string, bool)
+}
+
+// File option for loading values from a JSON (.json) or YAML (.yaml, .yml) file.
+// Multiple files are applied in the order provided, each one overriding the values of the previous.
+func File(path string) OptionFunc {
+	return func(l *loader) error {
+		if path == "" {
+			return errors.New("file path is required")
+		}
+		l.files = append(l.files, path)
+		return nil
+	}
+}
+
+// Load populates the configuration struct pointed by v from the defaults, the files and the env vars.
+func Load(v interface{}, oo ...OptionFunc) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("configuration has to be a non nil pointer to a struct")
+	}
+
+	l := &loader{lookup: os.LookupEnv}
+	for _, o := range oo {
+		err := o(l)
+		if err != nil {
+			return err
+		}
+	}
+
+	values := map[string]interface{}{}
+	for _, f := range l.files {
+		fv, err := readFile(f)
+		if err != nil {
+			return err
+		}
+		merge(values, fv)
+	}
+
+	return l.load(rv.Elem(), values, "")
+}
+
+func (l *loader) load(rv reflect.Value, values map[string]interface{}, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		key := fieldKey(sf)
+		path := prefix + key
+		fv := rv.Field(i)
+
+		if fv.Kind() == reflect.Struct {
+			nested, _ := values[key].(map[string]interface{})
+			err := l.load(fv, nested, path+".")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		raw, ok := sf.Tag.Lookup("default")
+		if v, found := values[key]; found {
+			raw, ok = stringify(v), true
+		}
+		if env := sf.Tag.Get("env"); env != "" {
+			if v, found := l.lookup(env); found {
+				raw, ok = v, true
+			}
+		}
+		if !ok {
+			if sf.Tag.Get("required") == "true" {
+				return fmt.Errorf("%s is required", path)
+			}
+			continue
+		}
+		err := setField(fv, raw)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", path, err)
+		}
+	}
+
+	if vd, ok := rv.Addr().Interface().(Validator); ok {
+		err := vd.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	}
+	return nil
+}
+
+// Masked returns the configuration struct pointed by v, or v itself, as a map keyed by the config keys,
+// with the values of the secret fields masked. It is suitable for exposing the effective configuration.
+// Pointers are dereferenced and slices, arrays and maps are traversed, so that the secret fields
+// of the structs they contain are masked as well. Fields of any other kind, e.g. channels and funcs, are rejected.
+func Masked(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("configuration has to be a struct or a pointer to a struct")
+	}
+	return masked(rv, "")
+}
+
+func masked(rv reflect.Value, prefix string) (map[string]interface{}, error) {
+	rt := rv.Type()
+	m := make(map[string]interface{}, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+		key := fieldKey(sf)
+		if sf.Tag.Get("secret") == "true" {
+			if fv.IsZero() {
+				m[key] = ""
+			} else {
+				m[key] = maskedValue
+			}
+			continue
+		}
+		v, err := maskedField(fv, prefix+key)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// maskedField returns the value of a field, with the secret fields of the structs it contains masked.
+func maskedField(fv reflect.Value, path string) (interface{}, error) {
+	if fv.Type() == durationType {
+		return fv.Interface().(time.Duration).String(), nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fv.Interface(), nil
+	case reflect.Ptr, reflect.Interface:
+		if fv.IsNil() {
+			return nil, nil
+		}
+		return maskedField(fv.Elem(), path)
+	case reflect.Struct:
+		return masked(fv, path+".")
+	case reflect.Slice, reflect.Array:
+		if fv.Kind() == reflect.Slice && fv.IsNil() {
+			return fv.Interface(), nil
+		}
+		vv := make([]interface{}, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v, err := maskedField(fv.Index(i), fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			vv[i] = v
+		}
+		return vv, nil
+	case reflect.Map:
+		if fv.IsNil() {
+			return fv.Interface(), nil
+		}
+		m := make(map[string]interface{}, fv.Len())
+		iter := fv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			v, err := maskedField(iter.Value(), fmt.Sprintf("%s[%s]", path, key))
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("%s has unsupported type %s", path, fv.Type())
+	}
+}
+
+func fieldKey(sf reflect.StructField) string {
+	if key := sf.Tag.Get("config"); key != "" {
+		return key
+	}
+	return strings.ToLower(sf.Name)
+}
+
+func setField(fv reflect.Value, raw string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", fv.Type())
+		}
+		var ss []string
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			if s != "" {
+				ss = append(ss, s)
+			}
+		}
+		fv.Set(reflect.ValueOf(ss).Convert(fv.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
+
+func stringify(v interface{}) string {
+	if vv, ok := v.([]interface{}); ok {
+		ss := make([]string, 0, len(vv))
+		for _, s := range vv {
+			ss = append(ss, fmt.Sprint(s))
+		}
+		return strings.Join(ss, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+func readFile(path string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		values := map[string]interface{}{}
+		err = dec.Decode(&values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode JSON config file %s: %w", path, err)
+		}
+		return values, nil
+	case ".yaml", ".yml":
+		values := map[interface{}]interface{}{}
+		err = yaml.Unmarshal(b, &values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML config file %s: %w", path, err)
+		}
+		return stringKeys(values), nil
+	}
+	return nil, fmt.Errorf("config file %s has an unsupported extension", path)
+}
+
+// stringKeys converts the maps decoded from YAML, which are keyed by interface{}, to maps keyed by string.
+func stringKeys(m map[interface{}]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if vm, ok := v.(map[interface{}]interface{}); ok {
+			res[fmt.Sprint(k)] = stringKeys(vm)
+			continue
+		}
+		res[fmt.Sprint(k)] = v
+	}
+	return res
+}
+
+// merge copies the values of src to dst, merging nested maps.
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		sm, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dm, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dm = map[string]interface{}{}
+			dst[k] = dm
+		}
+		merge(dm, sm)
+	}
+}
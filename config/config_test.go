@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dbConfig struct {
+	User     string `env:"TEST_CONFIG_DB_USER" default:"root"`
+	Password string `env:"TEST_CONFIG_DB_PASSWORD" secret:"true"`
+}
+
+type testConfig struct {
+	Name    string        `config:"name" env:"TEST_CONFIG_NAME" required:"true"`
+	Port    int           `config:"port" env:"TEST_CONFIG_PORT" default:"80"`
+	Debug   bool          `config:"debug" env:"TEST_CONFIG_DEBUG"`
+	Ratio   float64       `config:"ratio" default:"0.5"`
+	Retries uint          `config:"retries" default:"3"`
+	Timeout time.Duration `config:"timeout" env:"TEST_CONFIG_TIMEOUT" default:"1s"`
+	Hosts   []string      `config:"hosts" env:"TEST_CONFIG_HOSTS"`
+	DB      dbConfig      `config:"db"`
+	ignored string
+}
+
+func (c *testConfig) Validate() error {
+	if c.Port == 0 {
+		return errors.New("port is zero")
+	}
+	return nil
+}
+
+type unsupportedConfig struct {
+	Values map[string]string `default:"a"`
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		oo      []OptionFunc
+		want    testConfig
+		wantErr string
+	}{
+		{
+			name: "success, defaults and env",
+			env:  map[string]string{"TEST_CONFIG_NAME": "from-env", "TEST_CONFIG_HOSTS": "a, b,", "TEST_CONFIG_DEBUG": "true"},
+			want: testConfig{Name: "from-env", Port: 80, Debug: true, Ratio: 0.5, Retries: 3, Timeout: time.Second,
+				Hosts: []string{"a", "b"}, DB: dbConfig{User: "root"}},
+		},
+		{
+			name: "success, files override defaults",
+			oo:   []OptionFunc{File("testdata/config.yaml"), File("testdata/config.json")},
+			want: testConfig{Name: "from-yaml", Port: 9090, Ratio: 0.25, Retries: 3, Timeout: 5 * time.Second,
+				Hosts: []string{"a", "b"}, DB: dbConfig{User: "admin", Password: "secret"}},
+		},
+		{
+			name: "success, env overrides files",
+			env:  map[string]string{"TEST_CONFIG_PORT": "7070", "TEST_CONFIG_DB_USER": "user"},
+			oo:   []OptionFunc{File("testdata/config.yaml")},
+			want: testConfig{Name: "from-yaml", Port: 7070, Ratio: 0.5, Retries: 3, Timeout: 5 * time.Second,
+				Hosts: []string{"a", "b"}, DB: dbConfig{User: "user"}},
+		},
+		{name: "failure, missing required", wantErr: "name is required"},
+		{
+			name:    "failure, invalid value",
+			env:     map[string]string{"TEST_CONFIG_NAME": "name", "TEST_CONFIG_TIMEOUT": "5"},
+			wantErr: "failed to set timeout: time: missing unit in duration \"5\"",
+		},
+		{
+			name:    "failure, validation",
+			env:     map[string]string{"TEST_CONFIG_NAME": "name", "TEST_CONFIG_PORT": "0"},
+			wantErr: "invalid configuration: port is zero",
+		},
+		{name: "failure, empty file path", oo: []OptionFunc{File("")}, wantErr: "file path is required"},
+		{name: "failure, missing file", oo: []OptionFunc{File("testdata/missing.yaml")}, wantErr: "failed to read config file: open testdata/missing.yaml: no such file or directory"},
+		{name: "failure, invalid file", oo: []OptionFunc{File("testdata/invalid.yaml")}, wantErr: "failed to decode YAML config file testdata/invalid.yaml: yaml: line 1: did not find expected node content"},
+		{name: "failure, unsupported file", oo: []OptionFunc{File("testdata/config.toml")}, wantErr: "config file testdata/config.toml has an unsupported extension"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				assert.NoError(t, os.Setenv(k, v))
+			}
+			defer func() {
+				for k := range tt.env {
+					assert.NoError(t, os.Unsetenv(k))
+				}
+			}()
+			got := testConfig{}
+			err := Load(&got, tt.oo...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidConfiguration(t *testing.T) {
+	assert.EqualError(t, Load(testConfig{}), "configuration has to be a non nil pointer to a struct")
+	assert.EqualError(t, Load((*testConfig)(nil)), "configuration has to be a non nil pointer to a struct")
+	assert.EqualError(t, Load(&unsupportedConfig{}), "failed to set values: unsupported type map[string]string")
+}
+
+func TestMasked(t *testing.T) {
+	cfg := testConfig{Name: "name", Port: 80, Timeout: time.Second, DB: dbConfig{User: "root", Password: "secret"}}
+	got, err := Masked(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":    "name",
+		"port":    80,
+		"debug":   false,
+		"ratio":   0.0,
+		"retries": uint(0),
+		"timeout": "1s",
+		"hosts":   []string(nil),
+		"db":      map[string]interface{}{"user": "root", "password": "xxxxx"},
+	}, got)
+
+	cfg.DB.Password = ""
+	got, err = Masked(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "", got["db"].(map[string]interface{})["password"])
+
+	_, err = Masked("config")
+	assert.EqualError(t, err, "configuration has to be a struct or a pointer to a struct")
+}
+
+func TestMasked_Nested(t *testing.T) {
+	type nestedConfig struct {
+		Primary   *dbConfig            `config:"primary"`
+		Missing   *dbConfig            `config:"missing"`
+		Replicas  []dbConfig           `config:"replicas"`
+		Shards    map[string]*dbConfig `config:"shards"`
+		Any       interface{}          `config:"any"`
+		Ports     []int                `config:"ports"`
+		Passwords []string             `config:"passwords" secret:"true"`
+	}
+	cfg := nestedConfig{
+		Primary:   &dbConfig{User: "primary", Password: "secret"},
+		Replicas:  []dbConfig{{User: "replica", Password: "secret"}},
+		Shards:    map[string]*dbConfig{"eu": {User: "shard", Password: "secret"}},
+		Any:       dbConfig{User: "any", Password: "secret"},
+		Ports:     []int{80},
+		Passwords: []string{"secret"},
+	}
+	got, err := Masked(&cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"primary":   map[string]interface{}{"user": "primary", "password": "xxxxx"},
+		"missing":   nil,
+		"replicas":  []interface{}{map[string]interface{}{"user": "replica", "password": "xxxxx"}},
+		"shards":    map[string]interface{}{"eu": map[string]interface{}{"user": "shard", "password": "xxxxx"}},
+		"any":       map[string]interface{}{"user": "any", "password": "xxxxx"},
+		"ports":     []interface{}{80},
+		"passwords": "xxxxx",
+	}, got)
+}
+
+func TestMasked_UnsupportedType(t *testing.T) {
+	type chanConfig struct {
+		DB struct {
+			Notify chan string `config:"notify"`
+		} `config:"db"`
+	}
+	_, err := Masked(chanConfig{})
+	assert.EqualError(t, err, "db.notify has unsupported type chan string")
+
+	type funcConfig struct {
+		Hooks []func() `config:"hooks"`
+	}
+	_, err = Masked(funcConfig{Hooks: []func(){func() {}}})
+	assert.EqualError(t, err, "hooks[0] has unsupported type func()")
+}
@@ -0,0 +1,140 @@
+package patron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadServiceConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    serviceConfig
+		wantErr string
+	}{
+		{
+			name: "success, defaults",
+			want: serviceConfig{LogLevel: "info", HTTPPort: 50000, ShutdownGracePeriod: 20 * time.Second,
+				Jaeger:          jaegerConfig{AgentHost: "0.0.0.0", AgentPort: "6831", SamplerType: "probabilistic"},
+				TracingProvider: "jaeger", Zipkin: zipkinConfig{URL: "http://localhost:9411/api/v1/spans"}},
+		},
+		{
+			name: "success, file and env",
+			env:  map[string]string{configFileEnv: "testdata/config.yaml", "PATRON_JAEGER_AGENT_HOST": "jaeger"},
+			want: serviceConfig{LogLevel: "debug", HTTPPort: 50123, ShutdownGracePeriod: 20 * time.Second,
+				Jaeger:          jaegerConfig{AgentHost: "jaeger", AgentPort: "6831", SamplerType: "const", SamplerParam: 1},
+				TracingProvider: "jaeger", Zipkin: zipkinConfig{URL: "http://localhost:9411/api/v1/spans"}},
+		},
+		{
+			name:    "failure, invalid log level",
+			env:     map[string]string{"PATRON_LOG_LEVEL": "verbose"},
+			wantErr: "failed to load service configuration: invalid configuration: log level \"verbose\" is not valid",
+		},
+		{
+			name:    "failure, invalid port",
+			env:     map[string]string{"PATRON_HTTP_DEFAULT_PORT": "70000"},
+			wantErr: "failed to load service configuration: invalid configuration: HTTP default port 70000 is not valid",
+		},
+		{
+			name: "success, management port",
+			env:  map[string]string{"PATRON_HTTP_MANAGEMENT_PORT": "50001"},
+			want: serviceConfig{LogLevel: "info", HTTPPort: 50000, ManagementPort: 50001, ShutdownGracePeriod: 20 * time.Second,
+				Jaeger:          jaegerConfig{AgentHost: "0.0.0.0", AgentPort: "6831", SamplerType: "probabilistic"},
+				TracingProvider: "jaeger", Zipkin: zipkinConfig{URL: "http://localhost:9411/api/v1/spans"}},
+		},
+		{
+			name:    "failure, management port same as port",
+			env:     map[string]string{"PATRON_HTTP_MANAGEMENT_PORT": "50000"},
+			wantErr: "failed to load service configuration: invalid configuration: HTTP management port 50000 is not valid",
+		},
+		{
+			name: "success, zipkin",
+			env:  map[string]string{"PATRON_TRACING_PROVIDER": "zipkin", "PATRON_ZIPKIN_URL": "http://zipkin:9411/api/v1/spans"},
+			want: serviceConfig{LogLevel: "info", HTTPPort: 50000, ShutdownGracePeriod: 20 * time.Second,
+				Jaeger:          jaegerConfig{AgentHost: "0.0.0.0", AgentPort: "6831", SamplerType: "probabilistic"},
+				TracingProvider: "zipkin", Zipkin: zipkinConfig{URL: "http://zipkin:9411/api/v1/spans"}},
+		},
+		{
+			name: "success, metrics namespace",
+			env:  map[string]string{"PATRON_METRICS_NAMESPACE": "shop"},
+			want: serviceConfig{LogLevel: "info", HTTPPort: 50000, ShutdownGracePeriod: 20 * time.Second,
+				Jaeger:          jaegerConfig{AgentHost: "0.0.0.0", AgentPort: "6831", SamplerType: "probabilistic"},
+				TracingProvider: "jaeger", Zipkin: zipkinConfig{URL: "http://localhost:9411/api/v1/spans"}, MetricsNamespace: "shop"},
+		},
+		{
+			name:    "failure, memory tracing provider",
+			env:     map[string]string{"PATRON_TRACING_PROVIDER": "memory"},
+			wantErr: "failed to load service configuration: invalid configuration: tracing provider \"memory\" is not valid",
+		},
+		{
+			name:    "failure, invalid tracing provider",
+			env:     map[string]string{"PATRON_TRACING_PROVIDER": "datadog"},
+			wantErr: "failed to load service configuration: invalid configuration: tracing provider \"datadog\" is not valid",
+		},
+		{
+			name:    "failure, invalid sampler type",
+			env:     map[string]string{"PATRON_JAEGER_SAMPLER_TYPE": "always"},
+			wantErr: "failed to load service configuration: invalid configuration: jaeger sampler type \"always\" is not valid",
+		},
+		{
+			name:    "failure, invalid sampler param",
+			env:     map[string]string{"PATRON_JAEGER_SAMPLER_PARAM": "-1"},
+			wantErr: "failed to load service configuration: invalid configuration: jaeger sampler param must not be negative",
+		},
+		{
+			name:    "failure, missing file",
+			env:     map[string]string{configFileEnv: "testdata/missing.yaml"},
+			wantErr: "failed to load service configuration: failed to read config file: open testdata/missing.yaml: no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				defer setEnv(t, k, v)()
+			}
+			got, err := loadServiceConfig()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, *got)
+			}
+		})
+	}
+}
+
+type appConfig struct {
+	User     string `config:"user"`
+	Password string `config:"password" secret:"true"`
+}
+
+func TestService_EffectiveConfig(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	s, err := New("test", "", Config(&appConfig{User: "user", Password: "pass"}), ShutdownGracePeriod(time.Second))
+	assert.NoError(t, err)
+
+	cfg := s.effectiveConfig()
+	svc := cfg["service"].(map[string]interface{})
+	assert.Equal(t, "info", svc["log_level"])
+	assert.Equal(t, "1s", svc["shutdown_grace_period"])
+	assert.Equal(t, "0.0.0.0", svc["jaeger"].(map[string]interface{})["agent_host"])
+	assert.Equal(t, map[string]interface{}{"user": "user", "password": "xxxxx"}, cfg["application"])
+	assert.Equal(t, 0, svc["http_management_port"])
+	assert.Equal(t, "jaeger", svc["tracing_provider"])
+}
+
+func TestService_EffectiveConfig_Options(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	s, err := New("test", "", ManagementPort(61000), Tracing(trace.Noop()))
+	assert.NoError(t, err)
+
+	svc := s.effectiveConfig()["service"].(map[string]interface{})
+	assert.Equal(t, 61000, svc["http_management_port"])
+	assert.Equal(t, "custom", svc["tracing_provider"])
+	assert.NotContains(t, svc, "jaeger")
+	assert.NotContains(t, svc, "zipkin")
+}
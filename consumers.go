@@ -0,0 +1,67 @@
+package patron
+
+import (
+	"fmt"
+
+	"github.com/beatlabs/patron/sync/http"
+)
+
+// Pausable can be optionally implemented by a component, e.g. the async component, in order to be listed, paused and resumed
+// via the /consumers routes of the default HTTP component, even when it is run by a supervisor.
+type Pausable interface {
+	Name() string
+	Pause() error
+	Resume() error
+	Paused() bool
+	InFlight() int
+}
+
+// consumerManager manages the pausable components of the service, looking them up by name in all phases.
+type consumerManager struct {
+	s *Service
+}
+
+func (cm consumerManager) Consumers() []http.ConsumerStatus {
+	ss := make([]http.ConsumerStatus, 0)
+	for _, p := range cm.pausables() {
+		ss = append(ss, http.ConsumerStatus{Name: p.Name(), Paused: p.Paused(), InFlight: p.InFlight()})
+	}
+	return ss
+}
+
+func (cm consumerManager) Pause(name string) error {
+	p, err := cm.pausable(name)
+	if err != nil {
+		return err
+	}
+	return p.Pause()
+}
+
+func (cm consumerManager) Resume(name string) error {
+	p, err := cm.pausable(name)
+	if err != nil {
+		return err
+	}
+	return p.Resume()
+}
+
+func (cm consumerManager) pausable(name string) (Pausable, error) {
+	for _, p := range cm.pausables() {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", http.ErrConsumerNotFound, name)
+}
+
+func (cm consumerManager) pausables() []Pausable {
+	var pp []Pausable
+	for _, ph := range cm.s.orderedPhases() {
+		for _, cp := range ph.cps {
+			if p, ok := supervised(cp).(Pausable); ok {
+				pp = append(pp, p)
+			}
+		}
+	}
+	return pp
+}
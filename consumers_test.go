@@ -0,0 +1,79 @@
+package patron
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/beatlabs/patron/sync/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerManager(t *testing.T) {
+	orders := &pausableComponent{name: "orders", inFlight: 3}
+	payments := &pausableComponent{name: "payments", err: errors.New("failed to pause")}
+	s, err := New("test", "", Components(orders, &testComponent{}), Phase("last", payments))
+	require.NoError(t, err)
+	cm := consumerManager{s: s}
+
+	assert.Equal(t, []http.ConsumerStatus{{Name: "payments"}, {Name: "orders", InFlight: 3}}, cm.Consumers())
+
+	assert.NoError(t, cm.Pause("orders"))
+	assert.True(t, orders.paused)
+	assert.Equal(t, http.ConsumerStatus{Name: "orders", Paused: true, InFlight: 3}, cm.Consumers()[1])
+	assert.NoError(t, cm.Resume("orders"))
+	assert.False(t, orders.paused)
+
+	assert.EqualError(t, cm.Pause("payments"), "failed to pause")
+	err = cm.Resume("missing")
+	assert.True(t, errors.Is(err, http.ErrConsumerNotFound))
+	assert.EqualError(t, err, "consumer not found: missing")
+}
+
+func TestConsumerManager_Supervised(t *testing.T) {
+	orders := &pausableComponent{name: "orders"}
+	sv, err := NewSupervisor("supervisor", orders)
+	require.NoError(t, err)
+	nested, err := NewSupervisor("nested", &pausableComponent{name: "payments"})
+	require.NoError(t, err)
+	outer, err := NewSupervisor("outer", nested)
+	require.NoError(t, err)
+	s, err := New("test", "", Components(sv, outer))
+	require.NoError(t, err)
+	cm := consumerManager{s: s}
+
+	assert.Equal(t, []http.ConsumerStatus{{Name: "orders"}, {Name: "payments"}}, cm.Consumers())
+	assert.NoError(t, cm.Pause("orders"))
+	assert.True(t, orders.paused)
+	assert.Equal(t, http.ConsumerStatus{Name: "orders", Paused: true}, cm.Consumers()[0])
+}
+
+type pausableComponent struct {
+	testComponent
+	name     string
+	paused   bool
+	inFlight int
+	err      error
+}
+
+func (pc *pausableComponent) Name() string { return pc.name }
+
+func (pc *pausableComponent) Pause() error {
+	if pc.err != nil {
+		return pc.err
+	}
+	pc.paused = true
+	return nil
+}
+
+func (pc *pausableComponent) Resume() error {
+	if pc.err != nil {
+		return pc.err
+	}
+	pc.paused = false
+	return nil
+}
+
+func (pc *pausableComponent) Paused() bool { return pc.paused }
+
+func (pc *pausableComponent) InFlight() int { return pc.inFlight }
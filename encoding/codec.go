@@ -0,0 +1,113 @@
+package encoding
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/encoding/protobuf"
+)
+
+// Codec of a media type, which encodes models and decodes them from readers or byte slices.
+type Codec struct {
+	// ContentType is the content type which is set on responses and messages encoded with the codec.
+	ContentType string
+	Encode      EncodeFunc
+	Decode      DecodeFunc
+	DecodeRaw   DecodeRawFunc
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		json.Type: {
+			ContentType: json.TypeCharset,
+			Encode:      json.Encode,
+			Decode:      json.Decode,
+			DecodeRaw:   json.DecodeRaw,
+		},
+		protobuf.Type: {
+			ContentType: protobuf.Type,
+			Encode:      protobuf.Encode,
+			Decode:      protobuf.Decode,
+			DecodeRaw:   protobuf.DecodeRaw,
+		},
+		protobuf.TypeGoogle: {
+			ContentType: protobuf.Type,
+			Encode:      protobuf.Encode,
+			Decode:      protobuf.Decode,
+			DecodeRaw:   protobuf.DecodeRaw,
+		},
+	}
+)
+
+// Register registers the codec for its content type and the additional media types, replacing any codec already registered for them.
+// The codecs are consulted by the HTTP handlers, the async consumers and the producers, so they should be registered at startup.
+// The decode function is derived from the raw decode function when it is not provided.
+func Register(c Codec, mediaTypes ...string) error {
+	if c.ContentType == "" {
+		return errors.New("content type is required")
+	}
+	if c.Encode == nil {
+		return errors.New("encode function is required")
+	}
+	if c.DecodeRaw == nil {
+		return errors.New("raw decode function is required")
+	}
+	if c.Decode == nil {
+		c.Decode = decodeFunc(c.DecodeRaw)
+	}
+
+	mm := make([]string, 0, len(mediaTypes)+1)
+	for _, ct := range append([]string{c.ContentType}, mediaTypes...) {
+		mt, err := mediaType(ct)
+		if err != nil {
+			return err
+		}
+		mm = append(mm, mt)
+	}
+
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	for _, mt := range mm {
+		codecs[mt] = c
+	}
+	return nil
+}
+
+// Lookup returns the codec registered for the media type of the content type, ignoring its parameters, e.g. the charset.
+func Lookup(contentType string) (Codec, bool) {
+	mt, err := mediaType(contentType)
+	if err != nil {
+		return Codec{}, false
+	}
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[mt]
+	return c, ok
+}
+
+func mediaType(contentType string) (string, error) {
+	if strings.TrimSpace(contentType) == "" {
+		return "", errors.New("media type is required")
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	return mt, nil
+}
+
+func decodeFunc(decRaw DecodeRawFunc) DecodeFunc {
+	return func(data io.Reader, v interface{}) error {
+		b, err := ioutil.ReadAll(data)
+		if err != nil {
+			return err
+		}
+		return decRaw(b, v)
+	}
+}
@@ -0,0 +1,84 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/encoding/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	tests := map[string]struct {
+		contentType     string
+		wantOK          bool
+		wantContentType string
+	}{
+		"json":                 {contentType: json.Type, wantOK: true, wantContentType: json.TypeCharset},
+		"json with charset":    {contentType: json.TypeCharset, wantOK: true, wantContentType: json.TypeCharset},
+		"json upper case":      {contentType: "Application/JSON", wantOK: true, wantContentType: json.TypeCharset},
+		"protobuf":             {contentType: protobuf.Type, wantOK: true, wantContentType: protobuf.Type},
+		"google protobuf":      {contentType: protobuf.TypeGoogle, wantOK: true, wantContentType: protobuf.Type},
+		"unregistered":         {contentType: "application/xml"},
+		"empty":                {contentType: ""},
+		"invalid content type": {contentType: "application/json; charset"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, ok := Lookup(tt.contentType)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantContentType, c.ContentType)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	enc := func(v interface{}) ([]byte, error) { return []byte(v.(string)), nil }
+	decRaw := func(data []byte, v interface{}) error {
+		s, ok := v.(*string)
+		if !ok {
+			return errors.New("string pointer expected")
+		}
+		*s = string(data)
+		return nil
+	}
+	tests := map[string]struct {
+		codec      Codec
+		mediaTypes []string
+		wantErr    string
+	}{
+		"success":                  {codec: Codec{ContentType: "text/x-test; charset=utf-8", Encode: enc, DecodeRaw: decRaw}, mediaTypes: []string{"text/x-alias"}},
+		"missing content type":     {codec: Codec{Encode: enc, DecodeRaw: decRaw}, wantErr: "content type is required"},
+		"missing encode":           {codec: Codec{ContentType: "text/x-fail", DecodeRaw: decRaw}, wantErr: "encode function is required"},
+		"missing raw decode":       {codec: Codec{ContentType: "text/x-fail", Encode: enc}, wantErr: "raw decode function is required"},
+		"invalid additional media": {codec: Codec{ContentType: "text/x-fail", Encode: enc, DecodeRaw: decRaw}, mediaTypes: []string{" "}, wantErr: "media type is required"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Register(tt.codec, tt.mediaTypes...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				_, ok := Lookup(tt.codec.ContentType)
+				assert.False(t, ok)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	for _, ct := range []string{"text/x-test", "text/x-alias; charset=utf-8"} {
+		c, ok := Lookup(ct)
+		require.True(t, ok)
+		assert.Equal(t, "text/x-test; charset=utf-8", c.ContentType)
+		b, err := c.Encode("value")
+		assert.NoError(t, err)
+		var got string
+		assert.NoError(t, c.Decode(bytes.NewReader(b), &got))
+		assert.Equal(t, "value", got)
+		assert.NoError(t, c.DecodeRaw(b, &got))
+		assert.Equal(t, "value", got)
+	}
+}
@@ -0,0 +1,276 @@
+// Package health provides a registry of named health checks, which are reported by the /alive and /ready routes
+// of the default HTTP component.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the default time a check is given to complete.
+	DefaultTimeout = 5 * time.Second
+	// DefaultCacheTTL is the default time the result of a check is cached.
+	DefaultCacheTTL = 10 * time.Second
+)
+
+// Status type definition of a check or a report.
+type Status string
+
+const (
+	// Up represents a healthy check or a report where all checks are healthy.
+	Up Status = "up"
+	// Degraded represents a report where only non critical checks are unhealthy.
+	Degraded Status = "degraded"
+	// Down represents an unhealthy check or a report where at least one critical check is unhealthy.
+	Down Status = "down"
+)
+
+// Check defines a function type for implementing a health check, which is healthy when it returns no error.
+type Check func(ctx context.Context) error
+
+// Result of a health check.
+type Result struct {
+	Status   Status    `json:"status"`
+	Critical bool      `json:"critical"`
+	Error    string    `json:"error,omitempty"`
+	Duration string    `json:"duration"`
+	Time     time.Time `json:"time"`
+}
+
+// Report of the health checks of a registry.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+var defaultRegistry = &Registry{timeout: DefaultTimeout, ttl: DefaultCacheTTL}
+
+// DefaultRegistry returns the default registry, which is used by the service unless configured otherwise.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register registers a named check in the default registry.
+func Register(name string, c Check, oo ...CheckOptionFunc) error {
+	return defaultRegistry.Register(name, c, oo...)
+}
+
+// Registry of named health checks.
+// The checks run concurrently, each one within a timeout, and their results are cached.
+type Registry struct {
+	sync.RWMutex
+	checks  []*check
+	timeout time.Duration
+	ttl     time.Duration
+}
+
+// OptionFunc definition for configuring the registry in a functional way.
+type OptionFunc func(*Registry) error
+
+// Timeout option for setting the default time the checks of the registry are given to complete.
+func Timeout(timeout time.Duration) OptionFunc {
+	return func(r *Registry) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		r.timeout = timeout
+		return nil
+	}
+}
+
+// CacheTTL option for setting the default time the results of the checks of the registry are cached.
+// A zero TTL disables caching.
+func CacheTTL(ttl time.Duration) OptionFunc {
+	return func(r *Registry) error {
+		if ttl < 0 {
+			return errors.New("cache TTL must not be negative")
+		}
+		r.ttl = ttl
+		return nil
+	}
+}
+
+// NewRegistry creates a new registry.
+func NewRegistry(oo ...OptionFunc) (*Registry, error) {
+	r := &Registry{timeout: DefaultTimeout, ttl: DefaultCacheTTL}
+	for _, o := range oo {
+		err := o(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register registers a named check, which is critical and part of the readiness report by default.
+func (r *Registry) Register(name string, c Check, oo ...CheckOptionFunc) error {
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if c == nil {
+		return errors.New("check is required")
+	}
+
+	chk := &check{name: name, fn: c, critical: true, timeout: r.timeout, ttl: r.ttl}
+	for _, o := range oo {
+		err := o(chk)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	for _, ch := range r.checks {
+		if ch.name == name {
+			return fmt.Errorf("check %s is already registered", name)
+		}
+	}
+	r.checks = append(r.checks, chk)
+	return nil
+}
+
+// Alive runs the liveness checks and reports their results.
+func (r *Registry) Alive(ctx context.Context) Report {
+	return r.report(ctx, true)
+}
+
+// Ready runs all checks and reports their results.
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.report(ctx, false)
+}
+
+func (r *Registry) report(ctx context.Context, liveness bool) Report {
+	r.RLock()
+	cc := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if liveness && !c.liveness {
+			continue
+		}
+		cc = append(cc, c)
+	}
+	r.RUnlock()
+
+	rr := make([]Result, len(cc))
+	wg := sync.WaitGroup{}
+	wg.Add(len(cc))
+	for i, c := range cc {
+		go func(i int, c *check) {
+			defer wg.Done()
+			rr[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	rep := Report{Status: Up, Checks: make(map[string]Result, len(cc))}
+	for i, c := range cc {
+		rep.Checks[c.name] = rr[i]
+		if rr[i].Status == Up {
+			continue
+		}
+		if c.critical {
+			rep.Status = Down
+		} else if rep.Status == Up {
+			rep.Status = Degraded
+		}
+	}
+	return rep
+}
+
+// CheckOptionFunc definition for configuring a check in a functional way.
+type CheckOptionFunc func(*check) error
+
+// NonCritical option for marking a check as non critical, which degrades the report instead of bringing it down.
+func NonCritical() CheckOptionFunc {
+	return func(c *check) error {
+		c.critical = false
+		return nil
+	}
+}
+
+// Liveness option for including a check in the liveness report, besides the readiness report.
+// Liveness checks should only fail when the service has to be restarted.
+func Liveness() CheckOptionFunc {
+	return func(c *check) error {
+		c.liveness = true
+		return nil
+	}
+}
+
+// CheckTimeout option for overriding the time the check is given to complete.
+func CheckTimeout(timeout time.Duration) CheckOptionFunc {
+	return func(c *check) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		c.timeout = timeout
+		return nil
+	}
+}
+
+// CheckCacheTTL option for overriding the time the result of the check is cached. A zero TTL disables caching.
+func CheckCacheTTL(ttl time.Duration) CheckOptionFunc {
+	return func(c *check) error {
+		if ttl < 0 {
+			return errors.New("cache TTL must not be negative")
+		}
+		c.ttl = ttl
+		return nil
+	}
+}
+
+type check struct {
+	sync.Mutex
+	name     string
+	fn       Check
+	critical bool
+	liveness bool
+	timeout  time.Duration
+	ttl      time.Duration
+	last     *Result
+}
+
+// run runs the check, unless its cached result is still valid.
+// Concurrent runs of the same check wait for the one in progress and share its result.
+// The result is not cached when the context of the caller is done, since it does not reflect the state of the check.
+func (c *check) run(parent context.Context) Result {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.last != nil && time.Since(c.last.Time) < c.ttl {
+		return *c.last
+	}
+
+	start := time.Now()
+	ctx, cnl := context.WithTimeout(parent, c.timeout)
+	defer cnl()
+
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-chErr:
+	case <-ctx.Done():
+		err = ctx.Err()
+		if err == context.DeadlineExceeded {
+			err = fmt.Errorf("check timed out after %v", c.timeout)
+		}
+	}
+
+	res := Result{Status: Up, Critical: c.critical, Duration: time.Since(start).String(), Time: start}
+	if err != nil {
+		res.Status = Down
+		res.Error = err.Error()
+	}
+	if parent.Err() == nil {
+		c.last = &res
+	}
+	return res
+}
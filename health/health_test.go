@@ -0,0 +1,203 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		oo      []OptionFunc
+		wantErr bool
+	}{
+		{"success", nil, false},
+		{"success with options", []OptionFunc{Timeout(time.Second), CacheTTL(0)}, false},
+		{"failure, invalid timeout", []OptionFunc{Timeout(0)}, true},
+		{"failure, invalid cache TTL", []OptionFunc{CacheTTL(-time.Second)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistry(tt.oo...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r, err := NewRegistry()
+	assert.NoError(t, err)
+	tests := []struct {
+		name    string
+		check   string
+		c       Check
+		oo      []CheckOptionFunc
+		wantErr bool
+	}{
+		{"success", "first", healthy, nil, false},
+		{"success with options", "second", healthy, []CheckOptionFunc{NonCritical(), Liveness(), CheckTimeout(time.Second), CheckCacheTTL(0)}, false},
+		{"failure, missing name", "", healthy, nil, true},
+		{"failure, missing check", "third", nil, nil, true},
+		{"failure, already registered", "first", healthy, nil, true},
+		{"failure, invalid timeout", "third", healthy, []CheckOptionFunc{CheckTimeout(0)}, true},
+		{"failure, invalid cache TTL", "third", healthy, []CheckOptionFunc{CheckCacheTTL(-time.Second)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := r.Register(tt.check, tt.c, tt.oo...)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRegistry_Report(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+	tests := []struct {
+		name      string
+		register  func(r *Registry)
+		wantAlive Status
+		wantReady Status
+		wantErrs  map[string]string
+	}{
+		{
+			name:      "no checks",
+			register:  func(r *Registry) {},
+			wantAlive: Up,
+			wantReady: Up,
+		},
+		{
+			name: "all healthy",
+			register: func(r *Registry) {
+				assert.NoError(t, r.Register("live", healthy, Liveness()))
+				assert.NoError(t, r.Register("ready", healthy))
+			},
+			wantAlive: Up,
+			wantReady: Up,
+		},
+		{
+			name: "non critical unhealthy",
+			register: func(r *Registry) {
+				assert.NoError(t, r.Register("live", healthy, Liveness()))
+				assert.NoError(t, r.Register("ready", unhealthy, NonCritical()))
+			},
+			wantAlive: Up,
+			wantReady: Degraded,
+			wantErrs:  map[string]string{"ready": "unhealthy"},
+		},
+		{
+			name: "critical unhealthy",
+			register: func(r *Registry) {
+				assert.NoError(t, r.Register("live", healthy, Liveness()))
+				assert.NoError(t, r.Register("optional", unhealthy, NonCritical()))
+				assert.NoError(t, r.Register("ready", unhealthy))
+			},
+			wantAlive: Up,
+			wantReady: Down,
+			wantErrs:  map[string]string{"ready": "unhealthy", "optional": "unhealthy"},
+		},
+		{
+			name: "liveness timed out",
+			register: func(r *Registry) {
+				assert.NoError(t, r.Register("live", slow, Liveness(), CheckTimeout(10*time.Millisecond)))
+			},
+			wantAlive: Down,
+			wantReady: Down,
+			wantErrs:  map[string]string{"live": "check timed out after 10ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRegistry()
+			assert.NoError(t, err)
+			tt.register(r)
+
+			alive := r.Alive(context.Background())
+			assert.Equal(t, tt.wantAlive, alive.Status)
+			for name := range alive.Checks {
+				assert.Equal(t, "live", name)
+			}
+
+			ready := r.Ready(context.Background())
+			assert.Equal(t, tt.wantReady, ready.Status)
+			for name, res := range ready.Checks {
+				assert.Equal(t, tt.wantErrs[name], res.Error)
+				if res.Error == "" {
+					assert.Equal(t, Up, res.Status)
+				} else {
+					assert.Equal(t, Down, res.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_Cache(t *testing.T) {
+	var runs int32
+	c := func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}
+	r, err := NewRegistry(CacheTTL(time.Minute))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Register("cached", c))
+	assert.NoError(t, r.Register("uncached", c, CheckCacheTTL(0)))
+
+	r.Ready(context.Background())
+	r.Ready(context.Background())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+}
+
+func TestRegistry_Cache_CancelledContext(t *testing.T) {
+	var runs int32
+	c := func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	r, err := NewRegistry(CacheTTL(time.Minute), Timeout(time.Minute))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Register("cancelled", c))
+
+	// the result of a check which is cancelled by the caller is not cached
+	ctx, cnl := context.WithCancel(context.Background())
+	cnl()
+	rep := r.Ready(ctx)
+	assert.Equal(t, Down, rep.Checks["cancelled"].Status)
+	assert.Equal(t, context.Canceled.Error(), rep.Checks["cancelled"].Error)
+
+	ctx, cnl = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cnl()
+	r.Ready(ctx)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
+
+func TestRegister(t *testing.T) {
+	assert.NoError(t, Register("default", healthy))
+	assert.Contains(t, DefaultRegistry().Ready(context.Background()).Checks, "default")
+}
+
+func healthy(context.Context) error {
+	return nil
+}
+
+func unhealthy(context.Context) error {
+	return errors.New("unhealthy")
+}
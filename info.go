@@ -0,0 +1,70 @@
+package patron
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/beatlabs/patron/reliability/circuitbreaker"
+)
+
+// info returns the information of the service, its components, the circuit breakers and the build.
+func (s *Service) info() map[string]interface{} {
+	host, err := os.Hostname()
+	if err != nil {
+		host = ""
+	}
+
+	phases := s.orderedPhases()
+	cc := make([]map[string]interface{}, 0)
+	for _, ph := range phases {
+		for _, cp := range ph.cps {
+			cc = append(cc, componentInfo(ph.name, cp))
+		}
+	}
+
+	return map[string]interface{}{
+		"name":             s.name,
+		"version":          s.version,
+		"host":             host,
+		"components":       cc,
+		"circuit_breakers": circuitbreaker.Statuses(),
+		"build":            buildInfo(),
+	}
+}
+
+func componentInfo(phase string, cp Component) map[string]interface{} {
+	info := describe(cp)
+	info["phase"] = phase
+	return info
+}
+
+// describe returns the information of a component, falling back to its Go type if it is not a Describer.
+func describe(cp Component) map[string]interface{} {
+	info := map[string]interface{}{}
+	if d, ok := cp.(Describer); ok {
+		for k, v := range d.Info() {
+			info[k] = v
+		}
+	}
+	if _, ok := info["type"]; !ok {
+		info["type"] = fmt.Sprintf("%T", cp)
+	}
+	return info
+}
+
+func buildInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"go_version": runtime.Version(),
+		"os":         runtime.GOOS,
+		"arch":       runtime.GOARCH,
+	}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info["path"] = bi.Main.Path
+	info["module_version"] = bi.Main.Version
+	return info
+}
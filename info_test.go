@@ -0,0 +1,42 @@
+package patron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Info(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	sup, err := NewSupervisor("sup", &describedComponent{}, MaxRestarts(1, time.Minute))
+	assert.NoError(t, err)
+	s, err := New("test", "1.2.3", Phase("infra", &describedComponent{}), Components(&testComponent{}, sup))
+	assert.NoError(t, err)
+
+	info := s.info()
+	assert.Equal(t, "test", info["name"])
+	assert.Equal(t, "1.2.3", info["version"])
+	assert.NotEmpty(t, info["host"])
+	assert.NotNil(t, info["circuit_breakers"])
+	assert.NotEmpty(t, info["build"].(map[string]interface{})["go_version"])
+
+	cc := info["components"].([]map[string]interface{})
+	assert.Len(t, cc, 4)
+	assert.Equal(t, map[string]interface{}{"type": "described", "topic": "test", "phase": "infra"}, cc[0])
+	assert.Equal(t, "http", cc[1]["type"])
+	assert.Equal(t, HTTPPhase, cc[1]["phase"])
+	assert.Contains(t, cc[1]["routes"], "GET /info")
+	assert.Equal(t, map[string]interface{}{"type": "*patron.testComponent", "phase": ComponentsPhase}, cc[2])
+	assert.Equal(t, "described", cc[3]["type"])
+	assert.Equal(t, "sup", cc[3]["supervisor"].(map[string]interface{})["name"])
+	assert.Equal(t, 1, cc[3]["supervisor"].(map[string]interface{})["max_restarts"])
+}
+
+type describedComponent struct {
+	testComponent
+}
+
+func (dc *describedComponent) Info() map[string]interface{} {
+	return map[string]interface{}{"type": "described", "topic": "test"}
+}
@@ -43,6 +43,11 @@ type Logger interface {
 	Level() Level
 }
 
+// LevelSetter can be optionally implemented by a logger in order to support changing its level at runtime.
+type LevelSetter interface {
+	SetLevel(lvl Level) error
+}
+
 type ctxKey struct{}
 
 // FactoryFunc function type for creating loggers.
@@ -145,6 +150,15 @@ func Debugf(msg string, args ...interface{}) {
 	logger.Debugf(msg, args...)
 }
 
+// SetLevel changes the level of the logger at runtime, if the logger implements LevelSetter.
+func SetLevel(lvl Level) error {
+	ls, ok := logger.(LevelSetter)
+	if !ok {
+		return errors.New("logger does not support changing the level")
+	}
+	return ls.SetLevel(lvl)
+}
+
 var levelPriorities = map[Level]int{
 	DebugLevel: 0,
 	InfoLevel:  1,
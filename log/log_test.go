@@ -164,6 +164,15 @@ func TestLog_Level(t *testing.T) {
 	}
 }
 
+func TestSetLevel(t *testing.T) {
+	logger = &nilLogger{}
+	assert.EqualError(t, SetLevel(DebugLevel), "logger does not support changing the level")
+	l := levelTestLogger{}
+	logger = &l
+	assert.NoError(t, SetLevel(DebugLevel))
+	assert.Equal(t, DebugLevel, l.level)
+}
+
 var bCtx context.Context
 
 func Benchmark_WithContext(b *testing.B) {
@@ -254,3 +263,12 @@ func (t *testLogger) Debugf(msg string, args ...interface{}) {
 func (t *testLogger) Level() Level {
 	return t.level
 }
+
+type levelTestLogger struct {
+	testLogger
+}
+
+func (t *levelTestLogger) SetLevel(lvl Level) error {
+	t.level = lvl
+	return nil
+}
@@ -2,6 +2,7 @@ package zerolog
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/beatlabs/patron/log"
 	"github.com/rs/zerolog"
@@ -18,9 +19,18 @@ var levelMap = map[log.Level]zerolog.Level{
 }
 
 // Logger abstraction based on zerolog.
+// The level is shared between the logger and its sub loggers, so that changing it at runtime applies to all of them.
 type Logger struct {
 	logger *zerolog.Logger
+	level  *atomic.Value
+	// leveledLogger holds the zerolog logger with the level applied, which is rebuilt only when the level changes.
+	leveledLogger atomic.Value
+}
+
+// leveledLogger is a zerolog logger along with the level applied to it.
+type leveledLogger struct {
 	level  log.Level
+	logger zerolog.Logger
 }
 
 // NewLogger creates a new logger.
@@ -28,8 +38,16 @@ func NewLogger(l *zerolog.Logger, lvl log.Level, f map[string]interface{}) log.L
 	if len(f) == 0 {
 		f = make(map[string]interface{})
 	}
-	zl := l.Level(levelMap[lvl]).With().Fields(f).Logger()
-	return &Logger{logger: &zl, level: lvl}
+	zl := l.With().Fields(f).Logger()
+	level := &atomic.Value{}
+	level.Store(lvl)
+	return newLogger(&zl, level)
+}
+
+func newLogger(zl *zerolog.Logger, level *atomic.Value) *Logger {
+	l := &Logger{logger: zl, level: level}
+	l.leveled()
+	return l
 }
 
 // Sub returns a sub logger with new fields attached.
@@ -38,70 +56,92 @@ func (l *Logger) Sub(ff map[string]interface{}) log.Logger {
 		return l
 	}
 	sl := l.logger.With().Fields(ff).Logger()
-	return &Logger{logger: &sl, level: l.level}
+	return newLogger(&sl, l.level)
 }
 
 // Panic logging.
 func (l *Logger) Panic(args ...interface{}) {
-	l.logger.Panic().Msg(fmt.Sprint(args...))
+	l.leveled().Panic().Msg(fmt.Sprint(args...))
 }
 
 // Panicf logging.
 func (l *Logger) Panicf(msg string, args ...interface{}) {
-	l.logger.Panic().Msgf(msg, args...)
+	l.leveled().Panic().Msgf(msg, args...)
 }
 
 // Fatal logging.
 func (l *Logger) Fatal(args ...interface{}) {
-	l.logger.Fatal().Msg(fmt.Sprint(args...))
+	l.leveled().Fatal().Msg(fmt.Sprint(args...))
 }
 
 // Fatalf logging.
 func (l *Logger) Fatalf(msg string, args ...interface{}) {
-	l.logger.Fatal().Msgf(msg, args...)
+	l.leveled().Fatal().Msgf(msg, args...)
 }
 
 // Error logging.
 func (l *Logger) Error(args ...interface{}) {
-	l.logger.Error().Msg(fmt.Sprint(args...))
+	l.leveled().Error().Msg(fmt.Sprint(args...))
 }
 
 // Errorf logging.
 func (l *Logger) Errorf(msg string, args ...interface{}) {
-	l.logger.Error().Msgf(msg, args...)
+	l.leveled().Error().Msgf(msg, args...)
 }
 
 // Warn logging.
 func (l *Logger) Warn(args ...interface{}) {
-	l.logger.Warn().Msg(fmt.Sprint(args...))
+	l.leveled().Warn().Msg(fmt.Sprint(args...))
 }
 
 // Warnf logging.
 func (l *Logger) Warnf(msg string, args ...interface{}) {
-	l.logger.Warn().Msgf(msg, args...)
+	l.leveled().Warn().Msgf(msg, args...)
 }
 
 // Info logging.
 func (l *Logger) Info(args ...interface{}) {
-	l.logger.Info().Msg(fmt.Sprint(args...))
+	l.leveled().Info().Msg(fmt.Sprint(args...))
 }
 
 // Infof logging.
 func (l *Logger) Infof(msg string, args ...interface{}) {
-	l.logger.Info().Msgf(msg, args...)
+	l.leveled().Info().Msgf(msg, args...)
 }
 
 // Debug logging.
 func (l *Logger) Debug(args ...interface{}) {
-	l.logger.Debug().Msg(fmt.Sprint(args...))
+	l.leveled().Debug().Msg(fmt.Sprint(args...))
 }
 
 // Debugf logging.
 func (l *Logger) Debugf(msg string, args ...interface{}) {
-	l.logger.Debug().Msgf(msg, args...)
+	l.leveled().Debug().Msgf(msg, args...)
 }
 
 // Level return the logging level.
 func (l *Logger) Level() log.Level {
-	return l.level
+	return l.level.Load().(log.Level)
+}
+
+// SetLevel changes the logging level of the logger and its sub loggers.
+func (l *Logger) SetLevel(lvl log.Level) error {
+	if _, ok := levelMap[lvl]; !ok {
+		return fmt.Errorf("invalid log level %q", lvl)
+	}
+	l.level.Store(lvl)
+	l.leveled()
+	return nil
+}
+
+// leveled returns the zerolog logger with the current logging level applied, which is stored when the level changes,
+// so that the sub loggers, which share the level, pick up a change the first time they log after it.
+func (l *Logger) leveled() *zerolog.Logger {
+	lvl := l.Level()
+	if ll, ok := l.leveledLogger.Load().(*leveledLogger); ok && ll.level == lvl {
+		return &ll.logger
+	}
+	ll := &leveledLogger{level: lvl, logger: l.logger.Level(levelMap[lvl])}
+	l.leveledLogger.Store(ll)
+	return &ll.logger
 }
@@ -143,6 +143,37 @@ func TestLog_Level(t *testing.T) {
 	}
 }
 
+func TestLogger_SetLevel(t *testing.T) {
+	var b bytes.Buffer
+	zl := zerolog.New(&b)
+	l := NewLogger(&zl, log.InfoLevel, f)
+	sl := l.Sub(map[string]interface{}{"subkey1": "subval1"})
+	sl.Debug("hidden")
+	assert.Empty(t, b.String())
+
+	ls, ok := l.(log.LevelSetter)
+	assert.True(t, ok)
+	assert.EqualError(t, ls.SetLevel("verbose"), "invalid log level \"verbose\"")
+	assert.NoError(t, ls.SetLevel(log.DebugLevel))
+	assert.Equal(t, log.DebugLevel, l.Level())
+	assert.Equal(t, log.DebugLevel, sl.Level())
+	sl.Debug("testing")
+	assert.Equal(t, "{\"lvl\":\"debug\",\"key\":\"value\",\"subkey1\":\"subval1\",\"msg\":\"testing\"}\n", b.String())
+}
+
+func TestLogger_leveled_NoAllocations(t *testing.T) {
+	var b bytes.Buffer
+	zl := zerolog.New(&b)
+	l := NewLogger(&zl, log.InfoLevel, f).(*Logger)
+	sl := l.Sub(map[string]interface{}{"subkey1": "subval1"}).(*Logger)
+	assert.Zero(t, testing.AllocsPerRun(100, func() { l.leveled() }))
+
+	assert.NoError(t, l.SetLevel(log.ErrorLevel))
+	sl.Warn("hidden")
+	assert.Empty(t, b.String())
+	assert.Zero(t, testing.AllocsPerRun(100, func() { sl.leveled() }))
+}
+
 var t int
 
 func Benchmark_LoggingEnabled(b *testing.B) {
@@ -0,0 +1,200 @@
+// Package metrics provides a registry of the prometheus metrics of the service and its components,
+// which applies the namespace and the constant labels of the service to every metric.
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	defaultMu       sync.RWMutex
+	defaultRegistry = newRegistry()
+)
+
+// DefaultRegistry returns the default registry, which is used by the components that are not given a registry.
+// The service replaces it on setup with a registry that has the namespace and the constant labels of the service.
+func DefaultRegistry() *Registry {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultRegistry
+}
+
+// SetDefaultRegistry replaces the default registry.
+func SetDefaultRegistry(r *Registry) error {
+	if r == nil {
+		return errors.New("registry is required")
+	}
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultRegistry = r
+	return nil
+}
+
+// Registry of metrics, which creates the metric vectors on first use and registers them in its own prometheus registry.
+// Asking for the same metric twice returns the same vector, so that several instances of a component share it.
+type Registry struct {
+	sync.Mutex
+	namespace string
+	labels    prometheus.Labels
+	reg       *prometheus.Registry
+	vecs      map[string]prometheus.Collector
+}
+
+// OptionFunc definition for configuring the registry in a functional way.
+type OptionFunc func(*Registry) error
+
+// Namespace option for prefixing the name of every metric of the registry.
+func Namespace(ns string) OptionFunc {
+	return func(r *Registry) error {
+		if ns == "" {
+			return errors.New("namespace is required")
+		}
+		r.namespace = ns
+		return nil
+	}
+}
+
+// ConstLabels option for adding constant labels to every metric of the registry, e.g. the service name and version.
+func ConstLabels(labels map[string]string) OptionFunc {
+	return func(r *Registry) error {
+		if len(labels) == 0 {
+			return errors.New("labels are required")
+		}
+		for k, v := range labels {
+			r.labels[k] = v
+		}
+		return nil
+	}
+}
+
+// NewRegistry creates a new registry.
+func NewRegistry(oo ...OptionFunc) (*Registry, error) {
+	r := newRegistry()
+	for _, o := range oo {
+		err := o(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func newRegistry() *Registry {
+	return &Registry{
+		labels: prometheus.Labels{},
+		reg:    prometheus.NewRegistry(),
+		vecs:   make(map[string]prometheus.Collector),
+	}
+}
+
+// CounterVec returns the counter vector of the options and label names, which is created and registered on first use.
+func (r *Registry) CounterVec(opts prometheus.CounterOpts, labels ...string) (*prometheus.CounterVec, error) {
+	opts.Namespace = r.prefix(opts.Namespace)
+	opts.ConstLabels = r.constLabels(opts.ConstLabels)
+	c, err := r.collector(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), func() prometheus.Collector {
+		return prometheus.NewCounterVec(opts, labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	cv, ok := c.(*prometheus.CounterVec)
+	if !ok {
+		return nil, fmt.Errorf("metric %s is not a counter vector", opts.Name)
+	}
+	return cv, nil
+}
+
+// GaugeVec returns the gauge vector of the options and label names, which is created and registered on first use.
+func (r *Registry) GaugeVec(opts prometheus.GaugeOpts, labels ...string) (*prometheus.GaugeVec, error) {
+	opts.Namespace = r.prefix(opts.Namespace)
+	opts.ConstLabels = r.constLabels(opts.ConstLabels)
+	c, err := r.collector(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), func() prometheus.Collector {
+		return prometheus.NewGaugeVec(opts, labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	gv, ok := c.(*prometheus.GaugeVec)
+	if !ok {
+		return nil, fmt.Errorf("metric %s is not a gauge vector", opts.Name)
+	}
+	return gv, nil
+}
+
+// HistogramVec returns the histogram vector of the options and label names, which is created and registered on first use.
+func (r *Registry) HistogramVec(opts prometheus.HistogramOpts, labels ...string) (*prometheus.HistogramVec, error) {
+	opts.Namespace = r.prefix(opts.Namespace)
+	opts.ConstLabels = r.constLabels(opts.ConstLabels)
+	c, err := r.collector(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), func() prometheus.Collector {
+		return prometheus.NewHistogramVec(opts, labels)
+	})
+	if err != nil {
+		return nil, err
+	}
+	hv, ok := c.(*prometheus.HistogramVec)
+	if !ok {
+		return nil, fmt.Errorf("metric %s is not a histogram vector", opts.Name)
+	}
+	return hv, nil
+}
+
+// Register registers a custom collector, which is responsible for applying the namespace and the labels of the registry.
+func (r *Registry) Register(c prometheus.Collector) error {
+	if c == nil {
+		return errors.New("collector is required")
+	}
+	return r.reg.Register(c)
+}
+
+// Gatherer returns the gatherer of the metrics of the registry, along with the ones of the default prometheus registry,
+// e.g. the process and go runtime metrics.
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return prometheus.Gatherers{r.reg, prometheus.DefaultGatherer}
+}
+
+// Handler returns a HTTP handler which exposes the metrics of the gatherer of the registry.
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.Gatherer(), promhttp.HandlerOpts{})
+}
+
+func (r *Registry) collector(name string, create func() prometheus.Collector) (prometheus.Collector, error) {
+	r.Lock()
+	defer r.Unlock()
+	if c, ok := r.vecs[name]; ok {
+		return c, nil
+	}
+	c := create()
+	err := r.reg.Register(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metric %s: %w", name, err)
+	}
+	r.vecs[name] = c
+	return c, nil
+}
+
+func (r *Registry) prefix(ns string) string {
+	if r.namespace == "" {
+		return ns
+	}
+	if ns == "" {
+		return r.namespace
+	}
+	return r.namespace + "_" + ns
+}
+
+func (r *Registry) constLabels(labels prometheus.Labels) prometheus.Labels {
+	ll := make(prometheus.Labels, len(r.labels)+len(labels))
+	for k, v := range r.labels {
+		ll[k] = v
+	}
+	for k, v := range labels {
+		ll[k] = v
+	}
+	return ll
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		oo      []OptionFunc
+		wantErr string
+	}{
+		{name: "success", oo: []OptionFunc{Namespace("test"), ConstLabels(map[string]string{"service": "test"})}},
+		{name: "success, without options"},
+		{name: "failure, empty namespace", oo: []OptionFunc{Namespace("")}, wantErr: "namespace is required"},
+		{name: "failure, empty labels", oo: []OptionFunc{ConstLabels(nil)}, wantErr: "labels are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewRegistry(tt.oo...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+			}
+		})
+	}
+}
+
+func TestRegistry_CounterVec(t *testing.T) {
+	r, err := NewRegistry(Namespace("test"), ConstLabels(map[string]string{"service": "test", "version": "1.0.0"}))
+	require.NoError(t, err)
+	opts := prometheus.CounterOpts{Namespace: "component", Name: "counter", Help: "Test counter"}
+
+	cv1, err := r.CounterVec(opts, "name")
+	require.NoError(t, err)
+	cv2, err := r.CounterVec(opts, "name")
+	require.NoError(t, err)
+	assert.Equal(t, cv1, cv2)
+	cv1.WithLabelValues("first").Inc()
+	cv2.WithLabelValues("first").Inc()
+
+	_, err = r.GaugeVec(prometheus.GaugeOpts{Namespace: "component", Name: "counter", Help: "Test gauge"}, "name")
+	assert.EqualError(t, err, "metric counter is not a gauge vector")
+
+	assert.Contains(t, scrape(t, r), `test_component_counter{name="first",service="test",version="1.0.0"} 2`)
+}
+
+func TestRegistry_GaugeVec(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+	opts := prometheus.GaugeOpts{Namespace: "component", Name: "gauge", Help: "Test gauge"}
+
+	gv1, err := r.GaugeVec(opts, "queue")
+	require.NoError(t, err)
+	gv2, err := r.GaugeVec(opts, "queue")
+	require.NoError(t, err)
+	gv1.WithLabelValues("first").Set(1)
+	gv2.WithLabelValues("second").Set(2)
+
+	_, err = r.CounterVec(prometheus.CounterOpts{Namespace: "component", Name: "gauge", Help: "Test counter"}, "queue")
+	assert.EqualError(t, err, "metric gauge is not a counter vector")
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `component_gauge{queue="first"} 1`)
+	assert.Contains(t, body, `component_gauge{queue="second"} 2`)
+}
+
+func TestRegistry_HistogramVec(t *testing.T) {
+	r, err := NewRegistry(ConstLabels(map[string]string{"service": "test"}))
+	require.NoError(t, err)
+	opts := prometheus.HistogramOpts{Namespace: "component", Name: "histogram", Help: "Test histogram", Buckets: []float64{1, 2}}
+
+	hv1, err := r.HistogramVec(opts, "name")
+	require.NoError(t, err)
+	hv2, err := r.HistogramVec(opts, "name")
+	require.NoError(t, err)
+	assert.Equal(t, hv1, hv2)
+	hv1.WithLabelValues("first").Observe(1.5)
+
+	_, err = r.CounterVec(prometheus.CounterOpts{Namespace: "component", Name: "histogram", Help: "Test counter"}, "name")
+	assert.EqualError(t, err, "metric histogram is not a counter vector")
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `component_histogram_bucket{name="first",service="test",le="2"} 1`)
+	assert.Contains(t, body, `component_histogram_count{name="first",service="test"} 1`)
+}
+
+func TestRegistry_Isolation(t *testing.T) {
+	r1, err := NewRegistry()
+	require.NoError(t, err)
+	r2, err := NewRegistry()
+	require.NoError(t, err)
+	opts := prometheus.CounterOpts{Namespace: "component", Name: "isolated", Help: "Test counter"}
+
+	cv1, err := r1.CounterVec(opts)
+	require.NoError(t, err)
+	cv2, err := r2.CounterVec(opts)
+	require.NoError(t, err)
+	cv1.WithLabelValues().Inc()
+
+	assert.Contains(t, scrape(t, r1), "component_isolated 1")
+	assert.NotContains(t, scrape(t, r2), "component_isolated 1")
+	assert.NotEqual(t, cv1, cv2)
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r, err := NewRegistry()
+	require.NoError(t, err)
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "custom", Help: "Custom counter"})
+
+	assert.EqualError(t, r.Register(nil), "collector is required")
+	assert.NoError(t, r.Register(c))
+	assert.Error(t, r.Register(c))
+	c.Inc()
+	assert.Contains(t, scrape(t, r), "custom 1")
+}
+
+func TestSetDefaultRegistry(t *testing.T) {
+	def := DefaultRegistry()
+	defer func() { assert.NoError(t, SetDefaultRegistry(def)) }()
+	r, err := NewRegistry()
+	require.NoError(t, err)
+
+	assert.EqualError(t, SetDefaultRegistry(nil), "registry is required")
+	assert.Equal(t, def, DefaultRegistry())
+	assert.NoError(t, SetDefaultRegistry(r))
+	assert.Equal(t, r, DefaultRegistry())
+}
+
+func scrape(t *testing.T, r *Registry) string {
+	rsp := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rsp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rsp.Code)
+	return rsp.Body.String()
+}
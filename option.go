@@ -2,9 +2,16 @@ package patron
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/beatlabs/patron/config"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/beatlabs/patron/sync/http"
+	"github.com/beatlabs/patron/sync/http/auth"
+	"github.com/beatlabs/patron/trace"
 )
 
 // OptionFunc definition for configuring the service in a functional way.
@@ -34,6 +41,33 @@ func Middlewares(mm ...http.MiddlewareFunc) OptionFunc {
 	}
 }
 
+// ManagementPort option for hosting the management routes of the default HTTP component, which are
+// the liveness, readiness, info, config, reload, consumers, profiling and metrics routes, on a dedicated port.
+// It overrides the PATRON_HTTP_MANAGEMENT_PORT env var.
+func ManagementPort(port int) OptionFunc {
+	return func(s *Service) error {
+		if port <= 0 || port > 65535 {
+			return errors.New("management port is not valid")
+		}
+		s.mgmtPort = port
+		log.Info("management port is set")
+		return nil
+	}
+}
+
+// ManagementMiddlewares option for adding middlewares to the management routes of the default HTTP component,
+// which apply only when the management routes are hosted on a dedicated port.
+func ManagementMiddlewares(mm ...http.MiddlewareFunc) OptionFunc {
+	return func(s *Service) error {
+		if len(mm) == 0 {
+			return errors.New("management middlewares are required")
+		}
+		s.mgmtMws = mm
+		log.Info("management middleware options are set")
+		return nil
+	}
+}
+
 // AliveCheck option for overriding the default liveness check of the default HTTP component.
 func AliveCheck(acf http.AliveCheckFunc) OptionFunc {
 	return func(s *Service) error {
@@ -58,19 +92,180 @@ func ReadyCheck(rcf http.ReadyCheckFunc) OptionFunc {
 	}
 }
 
+// HealthRegistry option for overriding the default health registry, whose checks are reported
+// by the liveness and readiness routes of the default HTTP component.
+func HealthRegistry(hr *health.Registry) OptionFunc {
+	return func(s *Service) error {
+		if hr == nil {
+			return errors.New("health registry is required")
+		}
+		s.hr = hr
+		log.Info("health registry is set")
+		return nil
+	}
+}
+
+// ComponentReadiness option for registering the health checks of the components which implement the ReadinessReporter
+// interface, e.g. the async components, in the health registry, which makes them part of the readiness report
+// of the default HTTP component. The checks are named after the components, e.g. component:orders, and fail when a consumer
+// is not consuming, has no partitions assigned or has made no progress for the stall timeout, while it has messages to process.
+// A zero stall timeout disables the check of the progress. The check options apply to every check, e.g. health.Liveness()
+// makes a stuck consumer fail the liveness report instead, so that the orchestrator restarts the service.
+func ComponentReadiness(stallTimeout time.Duration, oo ...health.CheckOptionFunc) OptionFunc {
+	return func(s *Service) error {
+		if stallTimeout < 0 {
+			return errors.New("stall timeout must not be negative")
+		}
+		s.readiness = &componentReadiness{stallTimeout: stallTimeout, oo: oo}
+		log.Info("component readiness is set")
+		return nil
+	}
+}
+
+// Tracing option for setting up tracing with the provider, instead of the one selected by the PATRON_TRACING_PROVIDER env var.
+func Tracing(p trace.Provider) OptionFunc {
+	return func(s *Service) error {
+		if p == nil {
+			return errors.New("tracing provider is required")
+		}
+		s.tp = p
+		log.Info("tracing provider is set")
+		return nil
+	}
+}
+
+// Metrics option for setting the metrics registry of the service, instead of the one created with the namespace
+// of the PATRON_METRICS_NAMESPACE env var and the service and version constant labels.
+// The registry becomes the default registry of the metrics package and is exposed by the /metrics route.
+func Metrics(mr *metrics.Registry) OptionFunc {
+	return func(s *Service) error {
+		if mr == nil {
+			return errors.New("metrics registry is required")
+		}
+		s.mr = mr
+		log.Info("metrics registry is set")
+		return nil
+	}
+}
+
 // Components option for adding additional components to the service.
+// The components are started after the default HTTP component and stopped before it.
 func Components(cc ...Component) OptionFunc {
 	return func(s *Service) error {
 		if len(cc) == 0 || cc[0] == nil {
 			return errors.New("components are required")
 		}
-		s.cps = append(s.cps, cc...)
+		s.cpsPhase.cps = append(s.cpsPhase.cps, cc...)
 		log.Info("component options are set")
 		return nil
 	}
 }
 
+// Phase option for adding a named phase of components to the service.
+// Phases are started in the order they are declared and before the default HTTP component,
+// which makes them suitable for components the rest of the service depends upon.
+// Phases are stopped in reverse order, after the default HTTP component has been stopped.
+func Phase(name string, cc ...Component) OptionFunc {
+	return func(s *Service) error {
+		if name == "" {
+			return errors.New("phase name is required")
+		}
+		if s.phase(name) != nil {
+			return fmt.Errorf("phase %s already exists", name)
+		}
+		if len(cc) == 0 {
+			return errors.New("components are required")
+		}
+		for _, c := range cc {
+			if c == nil {
+				return errors.New("component is nil")
+			}
+		}
+		s.phases = append(s.phases, &phase{name: name, cps: cc})
+		log.Infof("phase %s is set", name)
+		return nil
+	}
+}
+
+// PhaseShutdownTimeout option for setting the time a phase is given to stop, before its shutdown is reported as failed.
+// The phase has to be declared before setting its timeout, except for the HTTPPhase and ComponentsPhase which always exist.
+// By default the phase has to stop within what remains of the shutdown grace period of the service.
+func PhaseShutdownTimeout(name string, timeout time.Duration) OptionFunc {
+	return func(s *Service) error {
+		if timeout <= 0 {
+			return errors.New("phase shutdown timeout must be positive")
+		}
+		ph := s.phase(name)
+		if ph == nil {
+			return fmt.Errorf("phase %s does not exist", name)
+		}
+		ph.timeout = timeout
+		log.Infof("phase %s shutdown timeout is set", name)
+		return nil
+	}
+}
+
+// ShutdownGracePeriod option for setting the time the service is given to shut down gracefully.
+// In-flight requests of the default HTTP component are drained and the phases without
+// a shutdown timeout have to stop within this period.
+// It overrides the PATRON_SHUTDOWN_GRACE_PERIOD env var.
+func ShutdownGracePeriod(gp time.Duration) OptionFunc {
+	return func(s *Service) error {
+		if gp <= 0 {
+			return errors.New("shutdown grace period must be positive")
+		}
+		s.gracePeriod = gp
+		log.Info("shutdown grace period is set")
+		return nil
+	}
+}
+
+// Config option for exposing the application configuration, along with the service configuration,
+// on the /config route of the default HTTP component. The configuration is expected to be a struct,
+// or a pointer to a struct, described with the tags of the config package, so that secrets are masked.
+func Config(cfg interface{}) OptionFunc {
+	return func(s *Service) error {
+		if cfg == nil {
+			return errors.New("config is required")
+		}
+		_, err := config.Masked(cfg)
+		if err != nil {
+			return err
+		}
+		s.appCfg = cfg
+		log.Info("config option is set")
+		return nil
+	}
+}
+
+// ReloadEndpoint option for exposing the authenticated /reload route on the default HTTP component,
+// which reloads the configuration of the service the same way a SIGHUP does.
+func ReloadEndpoint(auth auth.Authenticator) OptionFunc {
+	return func(s *Service) error {
+		if auth == nil {
+			return errors.New("authenticator is required")
+		}
+		s.reloadAuth = auth
+		log.Info("reload endpoint is set")
+		return nil
+	}
+}
+
+// ConsumerEndpoints option for exposing the /consumers routes on the default HTTP component, which list the pausable components,
+// e.g. the async components, and pause or resume them by name. Pausing and resuming require authentication.
+func ConsumerEndpoints(auth auth.Authenticator) OptionFunc {
+	return func(s *Service) error {
+		if auth == nil {
+			return errors.New("authenticator is required")
+		}
+		s.consumersAuth = auth
+		log.Info("consumer endpoints are set")
+		return nil
+	}
+}
+
 // SIGHUP option for adding a handler when the service receives a SIGHUP.
+// The handler is called after the service has reloaded its configuration.
 func SIGHUP(handler func()) OptionFunc {
 	return func(s *Service) error {
 		if handler == nil {
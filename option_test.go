@@ -3,10 +3,15 @@ package patron
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/beatlabs/patron/health"
+	"github.com/beatlabs/patron/metrics"
 	phttp "github.com/beatlabs/patron/sync/http"
+	"github.com/beatlabs/patron/sync/http/auth"
+	"github.com/beatlabs/patron/trace"
 )
 
 func middleware(h http.Handler) http.Handler {
@@ -69,6 +74,56 @@ func TestMiddlewares(t *testing.T) {
 	}
 }
 
+func TestManagementPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		port    int
+		wantErr bool
+	}{
+		{"success", 50001, false},
+		{"failure due to zero port", 0, true},
+		{"failure due to invalid port", 70000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = ManagementPort(tt.port)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.port, s.mgmtPort)
+			}
+		})
+	}
+}
+
+func TestManagementMiddlewares(t *testing.T) {
+	tests := []struct {
+		name    string
+		mm      []phttp.MiddlewareFunc
+		wantErr bool
+	}{
+		{"success", []phttp.MiddlewareFunc{middleware}, false},
+		{"failure because empty", []phttp.MiddlewareFunc{}, true},
+		{"failure because nil", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = ManagementMiddlewares(tt.mm...)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, s.mgmtMws, 1)
+			}
+		})
+	}
+}
+
 func TestAliveCheck(t *testing.T) {
 	type args struct {
 		acf phttp.AliveCheckFunc
@@ -121,6 +176,86 @@ func TestReadyCheck(t *testing.T) {
 	}
 }
 
+func TestHealthRegistry(t *testing.T) {
+	hr, err := health.NewRegistry()
+	assert.NoError(t, err)
+	type args struct {
+		hr *health.Registry
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to nil registry", args{hr: nil}, true},
+		{"success", args{hr: hr}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			assert.Equal(t, health.DefaultRegistry(), s.hr)
+			err = HealthRegistry(tt.args.hr)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.hr, s.hr)
+			}
+		})
+	}
+}
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       trace.Provider
+		wantErr bool
+	}{
+		{"success", trace.Noop(), false},
+		{"failure due to nil provider", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = Tracing(tt.p)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, s.tp)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	mr, err := metrics.NewRegistry()
+	assert.NoError(t, err)
+	tests := []struct {
+		name    string
+		mr      *metrics.Registry
+		wantErr bool
+	}{
+		{"success", mr, false},
+		{"failure due to nil registry", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = Metrics(tt.mr)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.mr, s.mr)
+			}
+		})
+	}
+}
+
 func TestComponents(t *testing.T) {
 	type args struct {
 		c Component
@@ -173,3 +308,160 @@ func TestSIGHUP(t *testing.T) {
 		})
 	}
 }
+
+func TestPhase(t *testing.T) {
+	type args struct {
+		name string
+		cc   []Component
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to empty name", args{name: "", cc: []Component{&testComponent{}}}, true},
+		{"failure due to reserved name", args{name: HTTPPhase, cc: []Component{&testComponent{}}}, true},
+		{"failure due to empty components", args{name: "infra"}, true},
+		{"failure due to nil component", args{name: "infra", cc: []Component{nil}}, true},
+		{"success", args{name: "infra", cc: []Component{&testComponent{}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = Phase(tt.args.name, tt.args.cc...)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPhaseShutdownTimeout(t *testing.T) {
+	type args struct {
+		name    string
+		timeout time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to missing phase", args{name: "missing", timeout: time.Second}, true},
+		{"failure due to invalid timeout", args{name: HTTPPhase, timeout: 0}, true},
+		{"success", args{name: ComponentsPhase, timeout: time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = PhaseShutdownTimeout(tt.args.name, tt.args.timeout)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestShutdownGracePeriod(t *testing.T) {
+	type args struct {
+		gp time.Duration
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to zero grace period", args{gp: 0}, true},
+		{"failure due to negative grace period", args{gp: -time.Second}, true},
+		{"success", args{gp: time.Second}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = ShutdownGracePeriod(tt.args.gp)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.gp, s.gracePeriod)
+			}
+		})
+	}
+}
+
+func TestConfig(t *testing.T) {
+	type args struct {
+		cfg interface{}
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to nil config", args{cfg: nil}, true},
+		{"failure due to non struct config", args{cfg: "config"}, true},
+		{"failure due to unsupported field type", args{cfg: &struct{ Notify chan string }{}}, true},
+		{"success", args{cfg: &appConfig{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = Config(tt.args.cfg)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.cfg, s.appCfg)
+			}
+		})
+	}
+}
+
+func TestReloadEndpoint(t *testing.T) {
+	type args struct {
+		auth auth.Authenticator
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"failure due to nil authenticator", args{auth: nil}, true},
+		{"success", args{auth: &testAuthenticator{}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New("test", "1.0.0")
+			assert.NoError(t, err)
+			err = ReloadEndpoint(tt.args.auth)(s)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.auth, s.reloadAuth)
+			}
+		})
+	}
+}
+
+func TestConsumerEndpoints(t *testing.T) {
+	s, err := New("test", "1.0.0")
+	assert.NoError(t, err)
+	assert.EqualError(t, ConsumerEndpoints(nil)(s), "authenticator is required")
+	auth := &testAuthenticator{}
+	assert.NoError(t, ConsumerEndpoints(auth)(s))
+	assert.Equal(t, auth, s.consumersAuth)
+}
+
+type testAuthenticator struct{}
+
+func (ta *testAuthenticator) Authenticate(req *http.Request) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,159 @@
+package patron
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/beatlabs/patron/log"
+)
+
+const (
+	// HTTPPhase is the name of the phase which hosts the default HTTP component.
+	HTTPPhase = "http"
+	// ComponentsPhase is the name of the phase which hosts the components added with the Components option.
+	ComponentsPhase = "components"
+)
+
+// StartNotifier can be optionally implemented by a component which needs some time to become operational.
+// The service does not start the next phase until the returned channel of every component in the current phase is closed.
+type StartNotifier interface {
+	Started() <-chan struct{}
+}
+
+// phase groups components which are started together.
+// Phases are started in order and stopped in reverse order.
+type phase struct {
+	name    string
+	cps     []Component
+	timeout time.Duration
+}
+
+// runningPhase tracks the components of a started phase.
+type runningPhase struct {
+	name    string
+	timeout time.Duration
+	ctx     *phaseContext
+	cnl     context.CancelFunc
+	started chan struct{}
+	done    chan struct{}
+}
+
+// run starts all components of the phase, each with a context which carries the values of the parent context,
+// e.g. the logger, but is cancelled only when the phase is stopped, so that the phases are stopped in reverse order
+// instead of all at once when the parent context is cancelled.
+// Once the phase is stopped, the deadline of the context is the one by which the components have to return.
+// Every component result is reported to chErr.
+func (p *phase) run(parent context.Context, chErr chan<- error) *runningPhase {
+	cctx, cnl := context.WithCancel(valuesContext{parent: parent})
+	ctx := &phaseContext{Context: cctx}
+	rp := &runningPhase{
+		name:    p.name,
+		timeout: p.timeout,
+		ctx:     ctx,
+		cnl:     cnl,
+		started: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	log.Infof("starting phase %s with %d components", p.name, len(p.cps))
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(p.cps))
+	for _, cp := range p.cps {
+		go func(c Component) {
+			defer wg.Done()
+			err := c.Run(ctx)
+			if err != nil {
+				err = fmt.Errorf("phase %s: %w", p.name, err)
+			}
+			chErr <- err
+		}(cp)
+	}
+
+	go func() {
+		wg.Wait()
+		close(rp.done)
+	}()
+
+	go func() {
+		for _, cp := range p.cps {
+			sn, ok := cp.(StartNotifier)
+			if !ok {
+				continue
+			}
+			select {
+			case <-sn.Started():
+			case <-rp.done:
+				return
+			}
+		}
+		close(rp.started)
+	}()
+
+	return rp
+}
+
+// valuesContext carries the values of its parent, without its deadline and cancellation.
+type valuesContext struct {
+	parent context.Context
+}
+
+func (valuesContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (valuesContext) Done() <-chan struct{} { return nil }
+
+func (valuesContext) Err() error { return nil }
+
+func (vc valuesContext) Value(key interface{}) interface{} { return vc.parent.Value(key) }
+
+// phaseContext is the context of the components of a phase, whose deadline is set when the phase is stopped,
+// e.g. for the HTTP component to drain its requests only for the time left.
+type phaseContext struct {
+	context.Context
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (pc *phaseContext) Deadline() (time.Time, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return pc.deadline, !pc.deadline.IsZero()
+}
+
+func (pc *phaseContext) setDeadline(deadline time.Time) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.deadline = deadline
+}
+
+// stop cancels the components of the phase and waits for them to return.
+// The components have to return within the timeout of the phase or, if it is not set, until the deadline.
+// Otherwise an error is returned.
+func (rp *runningPhase) stop(deadline time.Time) error {
+	log.Infof("stopping phase %s", rp.name)
+	if rp.timeout > 0 {
+		deadline = time.Now().Add(rp.timeout)
+	}
+	rp.ctx.setDeadline(deadline)
+	rp.cnl()
+
+	tm := time.NewTimer(time.Until(deadline))
+	defer tm.Stop()
+
+	select {
+	case <-rp.done:
+		return nil
+	case <-tm.C:
+		select {
+		case <-rp.done:
+			return nil
+		default:
+		}
+		if rp.timeout > 0 {
+			return fmt.Errorf("phase %s: shutdown timed out after %v", rp.name, rp.timeout)
+		}
+		return fmt.Errorf("phase %s: shutdown did not complete within the grace period", rp.name)
+	}
+}
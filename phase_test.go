@@ -0,0 +1,205 @@
+package patron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/async"
+	"github.com/beatlabs/patron/async/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Run_PhaseOrder(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	rec := &recorder{}
+	infra := &orderedComponent{name: "infra", rec: rec, startDelay: 20 * time.Millisecond}
+	db := &orderedComponent{name: "db", rec: rec}
+	consumer := &orderedComponent{name: "consumer", rec: rec}
+	s, err := New("test", "", Phase("infra", infra), Phase("db", db), Components(consumer))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chErr := make(chan error)
+	go func() {
+		chErr <- s.Run(ctx)
+	}()
+	<-consumer.Started()
+	cnl()
+
+	assert.NoError(t, <-chErr)
+	assert.Equal(t, []string{"start infra", "start db", "start consumer", "stop consumer", "stop db", "stop infra"}, rec.events())
+}
+
+func TestService_Run_PhaseOrder_AsyncComponent(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	f, err := memory.New()
+	assert.NoError(t, err)
+	asyncCmp, err := async.New("async", f, func(async.Message) error { return nil }).Create()
+	assert.NoError(t, err)
+	rec := &recorder{}
+	consumer := &orderedComponent{name: "consumer", rec: rec}
+	s, err := New("test", "", Phase("async", asyncCmp), Components(consumer))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	chErr := make(chan error)
+	go func() {
+		chErr <- s.Run(ctx)
+	}()
+	<-consumer.Started()
+	select {
+	case <-asyncCmp.Started():
+	default:
+		assert.Fail(t, "the components phase started before the async component")
+	}
+	cnl()
+
+	assert.NoError(t, <-chErr)
+}
+
+func TestService_Run_PhaseShutdownTimeout(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	rec := &recorder{}
+	slow := &orderedComponent{name: "slow", rec: rec, stopDelay: time.Second}
+	s, err := New("test", "", Phase("slow", slow), PhaseShutdownTimeout("slow", 10*time.Millisecond))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	cnl()
+	err = s.Run(ctx)
+	assert.EqualError(t, err, "phase slow: shutdown timed out after 10ms\n")
+}
+
+func TestService_Run_ShutdownGracePeriod(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	rec := &recorder{}
+	slow := &orderedComponent{name: "slow", rec: rec, stopDelay: time.Second}
+	s, err := New("test", "", Phase("slow", slow), ShutdownGracePeriod(10*time.Millisecond))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	cnl()
+	err = s.Run(ctx)
+	assert.EqualError(t, err, "phase slow: shutdown did not complete within the grace period\n")
+}
+
+func TestService_Run_PhaseComponentError(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	rec := &recorder{}
+	consumer := &orderedComponent{name: "consumer", rec: rec}
+	failing := &orderedComponent{name: "failing", rec: rec, failing: true}
+	s, err := New("test", "", Phase("failing", failing), Components(consumer))
+	assert.NoError(t, err)
+
+	err = s.Run(context.Background())
+	assert.EqualError(t, err, "phase failing: failed to start component\n")
+	assert.Empty(t, rec.events())
+}
+
+type ctxKey struct{}
+
+func TestService_Run_PhaseContext(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	chValue := make(chan interface{}, 1)
+	cmp := &ctxComponent{chValue: chValue}
+	s, err := New("test", "", Phase("ctx", cmp))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	chErr := make(chan error)
+	go func() {
+		chErr <- s.Run(ctx)
+	}()
+	assert.Equal(t, "value", <-chValue)
+	cnl()
+	assert.NoError(t, <-chErr)
+}
+
+func TestService_Run_PhaseContextDeadline(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	chDeadline := make(chan time.Time, 1)
+	cmp := &deadlineComponent{chDeadline: chDeadline}
+	s, err := New("test", "", Phase("deadline", cmp), ShutdownGracePeriod(time.Minute))
+	assert.NoError(t, err)
+
+	ctx, cnl := context.WithCancel(context.Background())
+	cnl()
+	start := time.Now()
+	assert.NoError(t, s.Run(ctx))
+	deadline := <-chDeadline
+	assert.WithinDuration(t, start.Add(time.Minute), deadline, 5*time.Second)
+}
+
+// deadlineComponent reports the deadline of its context once it is stopped.
+type deadlineComponent struct {
+	chDeadline chan time.Time
+}
+
+func (dc *deadlineComponent) Run(ctx context.Context) error {
+	<-ctx.Done()
+	deadline, _ := ctx.Deadline()
+	dc.chDeadline <- deadline
+	return nil
+}
+
+type ctxComponent struct {
+	chValue chan interface{}
+}
+
+func (cc *ctxComponent) Run(ctx context.Context) error {
+	cc.chValue <- ctx.Value(ctxKey{})
+	<-ctx.Done()
+	return nil
+}
+
+type recorder struct {
+	sync.Mutex
+	ee []string
+}
+
+func (r *recorder) record(event string) {
+	r.Lock()
+	defer r.Unlock()
+	r.ee = append(r.ee, event)
+}
+
+func (r *recorder) events() []string {
+	r.Lock()
+	defer r.Unlock()
+	return r.ee
+}
+
+type orderedComponent struct {
+	name       string
+	rec        *recorder
+	startDelay time.Duration
+	stopDelay  time.Duration
+	failing    bool
+	once       sync.Once
+	started    chan struct{}
+}
+
+func (oc *orderedComponent) Started() <-chan struct{} {
+	return oc.startedCh()
+}
+
+func (oc *orderedComponent) startedCh() chan struct{} {
+	oc.once.Do(func() { oc.started = make(chan struct{}) })
+	return oc.started
+}
+
+func (oc *orderedComponent) Run(ctx context.Context) error {
+	if oc.failing {
+		return errors.New("failed to start component")
+	}
+	time.Sleep(oc.startDelay)
+	oc.rec.record("start " + oc.name)
+	close(oc.startedCh())
+	<-ctx.Done()
+	time.Sleep(oc.stopDelay)
+	oc.rec.record("stop " + oc.name)
+	return nil
+}
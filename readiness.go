@@ -0,0 +1,40 @@
+package patron
+
+import (
+	"time"
+
+	"github.com/beatlabs/patron/health"
+)
+
+// ReadinessReporter can be optionally implemented by a component, e.g. the async component, in order to register
+// its health check in the health registry of the service with the ComponentReadiness option.
+type ReadinessReporter interface {
+	Name() string
+	HealthCheck(stallTimeout time.Duration) health.Check
+}
+
+type componentReadiness struct {
+	stallTimeout time.Duration
+	oo           []health.CheckOptionFunc
+}
+
+// registerReadiness registers the health checks of the components which implement the ReadinessReporter interface
+// in all phases, including the ones run by a supervisor, named after the components.
+func (s *Service) registerReadiness() error {
+	if s.readiness == nil {
+		return nil
+	}
+	for _, ph := range s.orderedPhases() {
+		for _, cp := range ph.cps {
+			rr, ok := supervised(cp).(ReadinessReporter)
+			if !ok {
+				continue
+			}
+			err := s.hr.Register("component:"+rr.Name(), rr.HealthCheck(s.readiness.stallTimeout), s.readiness.oo...)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package patron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentReadiness(t *testing.T) {
+	s, err := New("test", "1.0.0")
+	require.NoError(t, err)
+	assert.EqualError(t, ComponentReadiness(-time.Second)(s), "stall timeout must not be negative")
+	assert.NoError(t, ComponentReadiness(time.Minute, health.Liveness())(s))
+	assert.Equal(t, time.Minute, s.readiness.stallTimeout)
+	assert.Len(t, s.readiness.oo, 1)
+}
+
+func TestService_registerReadiness(t *testing.T) {
+	orders := &readinessComponent{name: "orders"}
+	payments := &readinessComponent{name: "payments", err: errors.New("stalled")}
+	cc := []Component{orders, &testComponent{}}
+
+	hr, err := health.NewRegistry()
+	require.NoError(t, err)
+	_, err = New("test", "", HealthRegistry(hr), Components(cc...), Phase("last", payments))
+	require.NoError(t, err)
+	assert.Equal(t, health.Up, hr.Ready(context.Background()).Status)
+
+	hr, err = health.NewRegistry()
+	require.NoError(t, err)
+	_, err = New("test", "", HealthRegistry(hr), Components(cc...), Phase("last", payments), ComponentReadiness(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, orders.stallTimeout)
+	rep := hr.Ready(context.Background())
+	assert.Equal(t, health.Down, rep.Status)
+	require.Len(t, rep.Checks, 2)
+	assert.Equal(t, health.Up, rep.Checks["component:orders"].Status)
+	assert.Equal(t, health.Down, rep.Checks["component:payments"].Status)
+	assert.Equal(t, "stalled", rep.Checks["component:payments"].Error)
+
+	_, err = New("test", "", HealthRegistry(hr), Components(cc...), ComponentReadiness(time.Minute))
+	assert.EqualError(t, err, "check component:orders is already registered")
+}
+
+func TestService_registerReadiness_Supervised(t *testing.T) {
+	orders := &readinessComponent{name: "orders", err: errors.New("stalled")}
+	sv, err := NewSupervisor("supervisor", orders)
+	require.NoError(t, err)
+
+	hr, err := health.NewRegistry()
+	require.NoError(t, err)
+	_, err = New("test", "", HealthRegistry(hr), Components(sv), ComponentReadiness(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, orders.stallTimeout)
+	rep := hr.Ready(context.Background())
+	assert.Equal(t, health.Down, rep.Status)
+	require.Len(t, rep.Checks, 1)
+	assert.Equal(t, "stalled", rep.Checks["component:orders"].Error)
+}
+
+type readinessComponent struct {
+	testComponent
+	name         string
+	stallTimeout time.Duration
+	err          error
+}
+
+func (rc *readinessComponent) Name() string { return rc.name }
+
+func (rc *readinessComponent) HealthCheck(stallTimeout time.Duration) health.Check {
+	rc.stallTimeout = stallTimeout
+	return func(context.Context) error { return rc.err }
+}
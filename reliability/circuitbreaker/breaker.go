@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -25,27 +27,47 @@ const (
 )
 
 var (
-	tsFuture       = int64(math.MaxInt64)
-	openError      = new(OpenError)
-	breakerCounter *prometheus.CounterVec
-	statusMap      = map[status]string{close: "close", open: "open"}
+	tsFuture  = int64(math.MaxInt64)
+	openError = new(OpenError)
+	statusMap = map[status]string{close: "close", open: "open"}
+	registry  = breakerRegistry{breakers: make(map[string]*CircuitBreaker)}
 )
 
-func init() {
-	breakerCounter = prometheus.NewCounterVec(
+// breakerRegistry keeps track of the created circuit breakers in order to report their status.
+type breakerRegistry struct {
+	sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// Statuses returns the current status of every created circuit breaker by name,
+// which is one of close, open and half-open.
+func Statuses() map[string]string {
+	registry.Lock()
+	defer registry.Unlock()
+	ss := make(map[string]string, len(registry.breakers))
+	for name, cb := range registry.breakers {
+		ss[name] = cb.Status()
+	}
+	return ss
+}
+
+func breakerCounterInc(mr *metrics.Registry, name string, st status) {
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	breakerCounter, err := mr.CounterVec(
 		prometheus.CounterOpts{
 			Namespace: "reliability",
 			Subsystem: "circuit_breaker",
 			Name:      "errors",
 			Help:      "Circuit breaker status, classified by name and status",
 		},
-		[]string{"name", "status"},
+		"name", "status",
 	)
-
-	prometheus.MustRegister(breakerCounter)
-}
-
-func breakerCounterInc(name string, st status) {
+	if err != nil {
+		log.Errorf("failed to get circuit breaker counter: %v", err)
+		return
+	}
 	breakerCounter.WithLabelValues(name, statusMap[st]).Inc()
 }
 
@@ -59,6 +81,8 @@ type Setting struct {
 	RetrySuccessThreshold uint
 	// The threshold of how many retry executions are allowed when the status is half-open.
 	MaxRetryExecutionThreshold uint
+	// The registry of the metrics, which defaults to the default registry of the metrics package.
+	Metrics *metrics.Registry
 }
 
 // Action function to execute in circuit breaker.
@@ -77,6 +101,7 @@ type CircuitBreaker struct {
 }
 
 // New constructor.
+// The circuit breaker replaces any previously created one with the same name in the reported statuses.
 func New(name string, s Setting) (*CircuitBreaker, error) {
 
 	if name == "" {
@@ -87,7 +112,7 @@ func New(name string, s Setting) (*CircuitBreaker, error) {
 		return nil, errors.New("max retry has to be greater than the retry threshold")
 	}
 
-	return &CircuitBreaker{
+	cb := &CircuitBreaker{
 		name:       name,
 		set:        s,
 		status:     close,
@@ -95,7 +120,32 @@ func New(name string, s Setting) (*CircuitBreaker, error) {
 		failures:   0,
 		retries:    0,
 		nextRetry:  tsFuture,
-	}, nil
+	}
+
+	registry.Lock()
+	registry.breakers[name] = cb
+	registry.Unlock()
+
+	return cb, nil
+}
+
+// Unregister removes the circuit breaker from the reported statuses, unless it has been replaced by a newer one with the same name.
+func (cb *CircuitBreaker) Unregister() {
+	registry.Lock()
+	defer registry.Unlock()
+	if registry.breakers[cb.name] == cb {
+		delete(registry.breakers, cb.name)
+	}
+}
+
+// Status returns the current status of the circuit breaker, which is one of close, open and half-open.
+func (cb *CircuitBreaker) Status() string {
+	if cb.isHalfOpen() {
+		return "half-open"
+	}
+	cb.RLock()
+	defer cb.RUnlock()
+	return statusMap[cb.status]
 }
 
 func (cb *CircuitBreaker) isHalfOpen() bool {
@@ -185,7 +235,7 @@ func (cb *CircuitBreaker) transitionToOpen() {
 	cb.executions = 0
 	cb.retries = 0
 	cb.nextRetry = time.Now().Add(cb.set.RetryTimeout).UnixNano()
-	breakerCounterInc(cb.name, cb.status)
+	breakerCounterInc(cb.set.Metrics, cb.name, cb.status)
 }
 
 func (cb *CircuitBreaker) transitionToClose() {
@@ -194,5 +244,5 @@ func (cb *CircuitBreaker) transitionToClose() {
 	cb.executions = 0
 	cb.retries = 0
 	cb.nextRetry = tsFuture
-	breakerCounterInc(cb.name, cb.status)
+	breakerCounterInc(cb.set.Metrics, cb.name, cb.status)
 }
@@ -5,6 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/beatlabs/patron/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,9 +23,9 @@ func TestNew(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		{name: "success", args: args{name: "test", s: validSetting}, wantErr: false},
+		{name: "success", args: args{name: "new", s: validSetting}, wantErr: false},
 		{name: "missing name", args: args{name: "", s: validSetting}, wantErr: true},
-		{name: "invalid settings", args: args{name: "test", s: invalidSetting}, wantErr: true},
+		{name: "invalid settings", args: args{name: "new", s: invalidSetting}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -34,11 +37,29 @@ func TestNew(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, got)
+				got.Unregister()
 			}
 		})
 	}
 }
 
+func TestNew_SameName(t *testing.T) {
+	set := Setting{FailureThreshold: 1, RetryTimeout: time.Minute, RetrySuccessThreshold: 1, MaxRetryExecutionThreshold: 1}
+	first, err := New("same", set)
+	assert.NoError(t, err)
+	second, err := New("same", set)
+	assert.NoError(t, err)
+	_, err = second.Execute(testFailureAction)
+	assert.Error(t, err)
+	assert.Equal(t, "open", Statuses()["same"])
+
+	// the replaced circuit breaker does not remove the newer one
+	first.Unregister()
+	assert.Equal(t, "open", Statuses()["same"])
+	second.Unregister()
+	assert.NotContains(t, Statuses(), "same")
+}
+
 func TestCircuitBreaker_isHalfOpen(t *testing.T) {
 	type fields struct {
 		status    status
@@ -184,7 +205,8 @@ var err error
 func BenchmarkCircuitBreaker_Execute(b *testing.B) {
 	set := Setting{FailureThreshold: uint(1), RetryTimeout: 1 * time.Second, RetrySuccessThreshold: uint(1), MaxRetryExecutionThreshold: 1}
 	var cb *CircuitBreaker
-	cb, err = New("test", set)
+	cb, err = New("benchmark", set)
+	defer cb.Unregister()
 	b.ReportAllocs()
 	b.ResetTimer()
 
@@ -200,3 +222,34 @@ func testSuccessAction() (interface{}, error) {
 func testFailureAction() (interface{}, error) {
 	return "", errors.New("Test error")
 }
+
+func TestStatuses(t *testing.T) {
+	set := Setting{FailureThreshold: 1, RetryTimeout: 10 * time.Millisecond, RetrySuccessThreshold: 1, MaxRetryExecutionThreshold: 1}
+	cb, err := New("status", set)
+	assert.NoError(t, err)
+	assert.Equal(t, "close", cb.Status())
+	assert.Equal(t, "close", Statuses()["status"])
+
+	_, err = cb.Execute(testFailureAction)
+	assert.Error(t, err)
+	assert.Equal(t, "open", Statuses()["status"])
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, "half-open", Statuses()["status"])
+}
+
+func TestCircuitBreaker_Metrics(t *testing.T) {
+	mr, err := metrics.NewRegistry(metrics.ConstLabels(map[string]string{"service": "test"}))
+	assert.NoError(t, err)
+	set := Setting{FailureThreshold: 1, RetryTimeout: time.Minute, RetrySuccessThreshold: 1, MaxRetryExecutionThreshold: 1, Metrics: mr}
+	cb, err := New("metrics", set)
+	assert.NoError(t, err)
+	_, err = cb.Execute(testFailureAction)
+	assert.Error(t, err)
+
+	cv, err := mr.CounterVec(prometheus.CounterOpts{Namespace: "reliability", Subsystem: "circuit_breaker", Name: "errors"}, "name", "status")
+	assert.NoError(t, err)
+	m := &dto.Metric{}
+	assert.NoError(t, cv.WithLabelValues("metrics", "open").Write(m))
+	assert.Equal(t, 1.0, m.GetCounter().GetValue())
+}
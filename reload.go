@@ -0,0 +1,83 @@
+package patron
+
+import (
+	"context"
+
+	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/trace"
+)
+
+// Reloadable can be optionally implemented by a component in order to be notified when the service reloads
+// its configuration, either on SIGHUP or via the /reload route of the default HTTP component.
+type Reloadable interface {
+	Reload(ctx context.Context) error
+}
+
+// reload re-reads the service configuration and applies the settings which can change at runtime,
+// which are the log level and the tracing sampler. Afterwards the reloadable components are notified.
+// The rest of the settings require a restart of the service and are kept as they are.
+// Since the env vars of a running process cannot be changed, only the changes of the PATRON_CONFIG_FILE file are applied.
+func (s *Service) reload(ctx context.Context) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	log.Info("reloading configuration")
+	cfg, err := loadServiceConfig()
+	if err != nil {
+		return err
+	}
+
+	cur := s.config()
+	if cfg.LogLevel != cur.LogLevel {
+		err = log.SetLevel(log.Level(cfg.LogLevel))
+		if err != nil {
+			return err
+		}
+		log.Infof("log level set to %s", cfg.LogLevel)
+	}
+	if cfg.Jaeger.SamplerType != cur.Jaeger.SamplerType || cfg.Jaeger.SamplerParam != cur.Jaeger.SamplerParam {
+		err = trace.SetSampler(cfg.Jaeger.SamplerType, cfg.Jaeger.SamplerParam)
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.HTTPPort != cur.HTTPPort || cfg.ManagementPort != cur.ManagementPort || cfg.ShutdownGracePeriod != cur.ShutdownGracePeriod ||
+		cfg.TracingProvider != cur.TracingProvider || cfg.Zipkin.URL != cur.Zipkin.URL || cfg.MetricsNamespace != cur.MetricsNamespace ||
+		cfg.Jaeger.AgentHost != cur.Jaeger.AgentHost || cfg.Jaeger.AgentPort != cur.Jaeger.AgentPort {
+		log.Warn("HTTP ports, shutdown grace period, tracing provider, zipkin url, metrics namespace and jaeger agent changes require a restart")
+	}
+	cfg.HTTPPort = cur.HTTPPort
+	cfg.ManagementPort = cur.ManagementPort
+	cfg.TracingProvider = cur.TracingProvider
+	cfg.Zipkin.URL = cur.Zipkin.URL
+	cfg.MetricsNamespace = cur.MetricsNamespace
+	cfg.ShutdownGracePeriod = cur.ShutdownGracePeriod
+	cfg.Jaeger.AgentHost = cur.Jaeger.AgentHost
+	cfg.Jaeger.AgentPort = cur.Jaeger.AgentPort
+	s.setConfig(cfg)
+
+	var ee []error
+	for _, ph := range s.orderedPhases() {
+		for _, cp := range ph.cps {
+			r, ok := cp.(Reloadable)
+			if !ok {
+				continue
+			}
+			ee = append(ee, r.Reload(ctx))
+		}
+	}
+	return patronErrors.Aggregate(ee...)
+}
+
+func (s *Service) config() serviceConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return *s.cfg
+}
+
+func (s *Service) setConfig(cfg *serviceConfig) {
+	s.cfgMu.Lock()
+	defer s.cfgMu.Unlock()
+	s.cfg = cfg
+}
@@ -0,0 +1,70 @@
+package patron
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/beatlabs/patron/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_Reload(t *testing.T) {
+	defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
+	f, err := ioutil.TempFile("", "patron-*.yaml")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	defer func() {
+		assert.NoError(t, os.Remove(f.Name()))
+	}()
+	writeConfig := func(cfg string) {
+		assert.NoError(t, ioutil.WriteFile(f.Name(), []byte(cfg), 0600))
+	}
+	writeConfig("log_level: info\n")
+	defer setEnv(t, configFileEnv, f.Name())()
+	rc := &reloadableComponent{}
+	sup, err := NewSupervisor("sup", &reloadableComponent{err: errors.New("failed to reload")})
+	assert.NoError(t, err)
+	s, err := New("test", "", Components(rc, &testComponent{}))
+	assert.NoError(t, err)
+	cur := s.config()
+
+	writeConfig("log_level: debug\nshutdown_grace_period: 1s\njaeger:\n  sampler_type: const\n")
+	defer func() {
+		assert.NoError(t, log.SetLevel(log.InfoLevel))
+	}()
+
+	err = s.reload(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rc.reloads)
+	assert.Equal(t, log.DebugLevel, log.Level(s.config().LogLevel))
+	assert.True(t, log.Enabled(log.DebugLevel))
+	assert.Equal(t, "const", s.config().Jaeger.SamplerType)
+	assert.Equal(t, cur.ShutdownGracePeriod, s.config().ShutdownGracePeriod)
+
+	err = Components(sup)(s)
+	assert.NoError(t, err)
+	err = s.reload(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reload")
+	assert.Equal(t, 2, rc.reloads)
+
+	writeConfig("log_level: verbose\n")
+	err = s.reload(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 2, rc.reloads)
+	assert.Equal(t, log.DebugLevel, log.Level(s.config().LogLevel))
+}
+
+type reloadableComponent struct {
+	testComponent
+	reloads int
+	err     error
+}
+
+func (rc *reloadableComponent) Reload(ctx context.Context) error {
+	rc.reloads++
+	return rc.err
+}
@@ -6,16 +6,18 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
 	"github.com/beatlabs/patron/log/zerolog"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/beatlabs/patron/sync/http"
+	"github.com/beatlabs/patron/sync/http/auth"
 	"github.com/beatlabs/patron/trace"
-	jaeger "github.com/uber/jaeger-client-go"
 )
 
 var logSetupOnce sync.Once
@@ -25,16 +27,39 @@ type Component interface {
 	Run(ctx context.Context) error
 }
 
+// Describer can be optionally implemented by a component in order to describe itself,
+// e.g. its type and configuration, which is reported by the /info route of the default HTTP component.
+type Describer interface {
+	Info() map[string]interface{}
+}
+
 // Service is responsible for managing and setting up everything.
 // The service will start by default a HTTP component in order to host management endpoint.
 type Service struct {
-	cps           []Component
+	name          string
+	version       string
+	phases        []*phase
+	httpPhase     *phase
+	cpsPhase      *phase
 	routes        []http.Route
 	middlewares   []http.MiddlewareFunc
+	mgmtPort      int
+	mgmtMws       []http.MiddlewareFunc
 	acf           http.AliveCheckFunc
 	rcf           http.ReadyCheckFunc
 	termSig       chan os.Signal
 	sighupHandler func()
+	gracePeriod   time.Duration
+	cfg           *serviceConfig
+	cfgMu         sync.RWMutex
+	reloadMu      sync.Mutex
+	reloadAuth    auth.Authenticator
+	consumersAuth auth.Authenticator
+	appCfg        interface{}
+	hr            *health.Registry
+	readiness     *componentReadiness
+	tp            trace.Provider
+	mr            *metrics.Registry
 }
 
 // New creates a new named service and allows for customization through functional options.
@@ -48,20 +73,27 @@ func New(name, version string, oo ...OptionFunc) (*Service, error) {
 	}
 
 	s := Service{
-		cps:           []Component{},
-		acf:           http.DefaultAliveCheck,
-		rcf:           http.DefaultReadyCheck,
-		termSig:       make(chan os.Signal, 1),
-		sighupHandler: func() { log.Info("SIGHUP received: nothing setup") },
-		middlewares:   []http.MiddlewareFunc{},
+		name:        name,
+		version:     version,
+		phases:      []*phase{},
+		httpPhase:   &phase{name: HTTPPhase},
+		cpsPhase:    &phase{name: ComponentsPhase, cps: []Component{}},
+		acf:         http.DefaultAliveCheck,
+		rcf:         http.DefaultReadyCheck,
+		termSig:     make(chan os.Signal, 1),
+		middlewares: []http.MiddlewareFunc{},
+		hr:          health.DefaultRegistry(),
 	}
 
-	err := Setup(name, version)
+	cfg, err := loadServiceConfig()
 	if err != nil {
 		return nil, err
 	}
+	s.cfg = cfg
+	s.gracePeriod = cfg.ShutdownGracePeriod
+	s.mgmtPort = cfg.ManagementPort
 
-	err = s.setupDefaultTracing(name, version)
+	err = setup(name, version, cfg.LogLevel)
 	if err != nil {
 		return nil, err
 	}
@@ -73,12 +105,27 @@ func New(name, version string, oo ...OptionFunc) (*Service, error) {
 		}
 	}
 
+	err = s.registerReadiness()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.setupTracing(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.setupMetrics(name, version)
+	if err != nil {
+		return nil, err
+	}
+
 	httpCp, err := s.createHTTPComponent()
 	if err != nil {
 		return nil, err
 	}
 
-	s.cps = append(s.cps, httpCp)
+	s.httpPhase.cps = append(s.httpPhase.cps, httpCp)
 	s.setupOSSignal()
 	return &s, nil
 }
@@ -87,9 +134,11 @@ func (s *Service) setupOSSignal() {
 	signal.Notify(s.termSig, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 }
 
-// Run starts up all service components and monitors for errors.
-// If a component returns a error the service is responsible for shutting down
-// all components and terminate itself.
+// Run starts up all service components phase by phase and monitors for errors.
+// A phase is started only after all components of the previous phase have started.
+// If a component returns, the context is cancelled or a termination signal is received,
+// the service stops the started phases in reverse order and terminates itself.
+// The phases without a shutdown timeout have to stop within the shutdown grace period of the service.
 func (s *Service) Run(ctx context.Context) error {
 	defer func() {
 		err := trace.Close()
@@ -97,38 +146,65 @@ func (s *Service) Run(ctx context.Context) error {
 			log.Errorf("failed to close trace %v", err)
 		}
 	}()
-	cctx, cnl := context.WithCancel(ctx)
-	chErr := make(chan error, len(s.cps))
-	wg := sync.WaitGroup{}
-	wg.Add(len(s.cps))
-	for _, cp := range s.cps {
-		go func(c Component) {
-			defer wg.Done()
-			chErr <- c.Run(cctx)
-		}(cp)
+	phases := s.orderedPhases()
+	count := 0
+	for _, ph := range phases {
+		count += len(ph.cps)
+	}
+	chErr := make(chan error, count)
+	running := make([]*runningPhase, 0, len(phases))
+	ee := make([]error, 0, count+len(phases))
+
+	terminate := false
+	for _, ph := range phases {
+		rp := ph.run(ctx, chErr)
+		running = append(running, rp)
+		var err error
+		terminate, err = s.waitTermination(ctx, chErr, rp.started)
+		if terminate {
+			ee = append(ee, err)
+			break
+		}
+		log.Infof("phase %s started", ph.name)
 	}
 
-	ee := make([]error, 0, len(s.cps))
-	ee = append(ee, s.waitTermination(chErr))
-	cnl()
+	if !terminate {
+		_, err := s.waitTermination(ctx, chErr, nil)
+		ee = append(ee, err)
+	}
 
-	wg.Wait()
-	close(chErr)
+	deadline := time.Now().Add(s.gracePeriod)
+	for i := len(running) - 1; i >= 0; i-- {
+		ee = append(ee, running[i].stop(deadline))
+	}
 
-	for err := range chErr {
-		ee = append(ee, err)
+	for {
+		select {
+		case err := <-chErr:
+			ee = append(ee, err)
+		default:
+			return patronErrors.Aggregate(ee...)
+		}
 	}
-	return patronErrors.Aggregate(ee...)
+}
+
+// orderedPhases returns the phases in start order.
+func (s *Service) orderedPhases() []*phase {
+	phases := make([]*phase, 0, len(s.phases)+2)
+	phases = append(phases, s.phases...)
+	return append(phases, s.httpPhase, s.cpsPhase)
 }
 
 // Setup set's up metrics and default logging.
 func Setup(name, version string) error {
-
-	lvl, ok := os.LookupEnv("PATRON_LOG_LEVEL")
-	if !ok {
-		lvl = string(log.InfoLevel)
+	cfg, err := loadServiceConfig()
+	if err != nil {
+		return err
 	}
+	return setup(name, version, cfg.LogLevel)
+}
 
+func setup(name, version, lvl string) error {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return fmt.Errorf("failed to get hostname: %w", err)
@@ -146,50 +222,47 @@ func Setup(name, version string) error {
 	return err
 }
 
-func (s *Service) setupDefaultTracing(name, version string) error {
-	var err error
-
-	host, ok := os.LookupEnv("PATRON_JAEGER_AGENT_HOST")
-	if !ok {
-		host = "0.0.0.0"
+func (s *Service) setupTracing(name, version string) error {
+	if s.tp != nil {
+		log.Info("setting up tracing with the provided provider")
+		return trace.SetupProvider(name, version, s.tp)
 	}
-	port, ok := os.LookupEnv("PATRON_JAEGER_AGENT_PORT")
-	if !ok {
-		port = "6831"
+	tp := s.cfg.Jaeger.SamplerType
+	prm := s.cfg.Jaeger.SamplerParam
+	switch s.cfg.TracingProvider {
+	case zipkinProvider:
+		log.Infof("setting up zipkin tracing %s, %s with param %v", s.cfg.Zipkin.URL, tp, prm)
+		return trace.SetupProvider(name, version, trace.Zipkin(s.cfg.Zipkin.URL, tp, prm))
+	case noopProvider:
+		log.Info("setting up noop tracing")
+		return trace.SetupProvider(name, version, trace.Noop())
+	default:
+		agent := s.cfg.Jaeger.AgentHost + ":" + s.cfg.Jaeger.AgentPort
+		log.Infof("setting up default tracing %s, %s with param %v", agent, tp, prm)
+		return trace.Setup(name, version, agent, tp, prm)
 	}
-	agent := host + ":" + port
-	tp, ok := os.LookupEnv("PATRON_JAEGER_SAMPLER_TYPE")
-	if !ok {
-		tp = jaeger.SamplerTypeProbabilistic
-	}
-	var prmVal = 0.0
-	var prm = "0.0"
+}
 
-	if prm, ok := os.LookupEnv("PATRON_JAEGER_SAMPLER_PARAM"); ok {
-		prmVal, err = strconv.ParseFloat(prm, 64)
+func (s *Service) setupMetrics(name, version string) error {
+	if s.mr == nil {
+		oo := []metrics.OptionFunc{metrics.ConstLabels(map[string]string{"service": name, "version": version})}
+		if s.cfg.MetricsNamespace != "" {
+			oo = append(oo, metrics.Namespace(s.cfg.MetricsNamespace))
+		}
+		mr, err := metrics.NewRegistry(oo...)
 		if err != nil {
-			return fmt.Errorf("env var for jaeger sampler param is not valid: %w", err)
+			return fmt.Errorf("failed to create metrics registry: %w", err)
 		}
+		s.mr = mr
 	}
-
-	log.Infof("setting up default tracing %s, %s with param %s", agent, tp, prm)
-	return trace.Setup(name, version, agent, tp, prmVal)
+	log.Infof("setting up metrics with namespace %q", s.cfg.MetricsNamespace)
+	return metrics.SetDefaultRegistry(s.mr)
 }
 
 func (s *Service) createHTTPComponent() (Component, error) {
-	var err error
-	var portVal = int64(50000)
-	port, ok := os.LookupEnv("PATRON_HTTP_DEFAULT_PORT")
-	if ok {
-		portVal, err = strconv.ParseInt(port, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("env var for HTTP default port is not valid: %w", err)
-		}
-	}
-	port = strconv.FormatInt(portVal, 10)
-	log.Infof("creating default HTTP component at port %s", port)
+	log.Infof("creating default HTTP component at port %d", s.cfg.HTTPPort)
 
-	b := http.NewBuilder().WithPort(int(portVal))
+	b := http.NewBuilder().WithPort(s.cfg.HTTPPort).WithShutdownGracePeriod(s.gracePeriod)
 
 	if s.acf != nil {
 		b.WithAliveCheckFunc(s.acf)
@@ -199,6 +272,9 @@ func (s *Service) createHTTPComponent() (Component, error) {
 		b.WithReadyCheckFunc(s.rcf)
 	}
 
+	b.WithHealthRegistry(s.hr)
+	b.WithMetrics(s.mr)
+
 	if s.routes != nil {
 		b.WithRoutes(s.routes)
 	}
@@ -207,6 +283,25 @@ func (s *Service) createHTTPComponent() (Component, error) {
 		b.WithMiddlewares(s.middlewares...)
 	}
 
+	if s.mgmtPort != 0 {
+		log.Infof("hosting the management routes of the default HTTP component at port %d", s.mgmtPort)
+		b.WithManagementPort(s.mgmtPort)
+		if len(s.mgmtMws) > 0 {
+			b.WithManagementMiddlewares(s.mgmtMws...)
+		}
+	}
+
+	b.WithInfoFunc(s.info)
+	b.WithConfigFunc(s.effectiveConfig)
+
+	if s.reloadAuth != nil {
+		b.WithReloadFunc(s.reload, s.reloadAuth)
+	}
+
+	if s.consumersAuth != nil {
+		b.WithConsumerManager(consumerManager{s: s}, s.consumersAuth)
+	}
+
 	cp, err := b.Create()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create default HTTP component: %w", err)
@@ -215,20 +310,48 @@ func (s *Service) createHTTPComponent() (Component, error) {
 	return cp, nil
 }
 
-func (s *Service) waitTermination(chErr <-chan error) error {
+// waitTermination blocks until the service has to terminate or, if chStarted is not nil, until it is closed.
+// It returns true along with the cause of termination, if the service has to terminate.
+func (s *Service) waitTermination(ctx context.Context, chErr <-chan error, chStarted <-chan struct{}) (bool, error) {
 	for {
 		select {
+		case <-chStarted:
+			return false, nil
+		case <-ctx.Done():
+			log.Info("context cancelled")
+			return true, nil
 		case sig := <-s.termSig:
 			log.Infof("signal %s received", sig.String())
 			switch sig {
 			case syscall.SIGHUP:
-				s.sighupHandler()
+				err := s.reload(ctx)
+				if err != nil {
+					log.Errorf("failed to reload configuration: %v", err)
+				}
+				if s.sighupHandler != nil {
+					s.sighupHandler()
+				}
 			default:
-				return nil
+				return true, nil
 			}
 		case err := <-chErr:
 			log.Info("component error received")
-			return err
+			return true, err
+		}
+	}
+}
+
+func (s *Service) phase(name string) *phase {
+	switch name {
+	case HTTPPhase:
+		return s.httpPhase
+	case ComponentsPhase:
+		return s.cpsPhase
+	}
+	for _, ph := range s.phases {
+		if ph.name == name {
+			return ph
 		}
 	}
+	return nil
 }
@@ -5,12 +5,20 @@ import (
 	"errors"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/beatlabs/patron/metrics"
 	phttp "github.com/beatlabs/patron/sync/http"
+	"github.com/beatlabs/patron/trace"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
+	jaeger "github.com/uber/jaeger-client-go"
 )
 
 func TestNewServer(t *testing.T) {
@@ -30,6 +38,7 @@ func TestNewServer(t *testing.T) {
 		wantErr bool
 	}{
 		{"success", args{name: "test", opt: []OptionFunc{Routes([]phttp.Route{route}), Middlewares(middleware)}}, false},
+		{"success with management port", args{name: "test", opt: []OptionFunc{ManagementPort(40000), ManagementMiddlewares(middleware)}}, false},
 		{"failed empty middlewares", args{name: "test", opt: []OptionFunc{Routes([]phttp.Route{route}), Middlewares([]phttp.MiddlewareFunc{}...)}}, true},
 		{"failed missing name", args{name: "", opt: []OptionFunc{Routes([]phttp.Route{route})}}, true},
 		{"failed missing routes", args{name: "test", opt: []OptionFunc{Routes([]phttp.Route{})}}, true},
@@ -60,8 +69,7 @@ func TestServer_Run_Shutdown(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := os.Setenv("PATRON_HTTP_DEFAULT_PORT", getRandomPort())
-			assert.NoError(t, err)
+			defer setEnv(t, "PATRON_HTTP_DEFAULT_PORT", getRandomPort())()
 			s, err := New("test", "", Components(tt.cp, tt.cp, tt.cp))
 			assert.NoError(t, err)
 			err = s.Run(tt.ctx)
@@ -90,12 +98,10 @@ func TestServer_SetupTracing(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.host != "" {
-				err := os.Setenv("PATRON_JAEGER_AGENT_HOST", tt.host)
-				assert.NoError(t, err)
+				defer setEnv(t, "PATRON_JAEGER_AGENT_HOST", tt.host)()
 			}
 			if tt.port != "" {
-				err := os.Setenv("PATRON_JAEGER_AGENT_PORT", tt.port)
-				assert.NoError(t, err)
+				defer setEnv(t, "PATRON_JAEGER_AGENT_PORT", tt.port)()
 			}
 			s, err := New("test", "", Components(tt.cp, tt.cp, tt.cp))
 			assert.NoError(t, err)
@@ -105,11 +111,103 @@ func TestServer_SetupTracing(t *testing.T) {
 	}
 }
 
+func TestServer_SetupTracingProvider(t *testing.T) {
+	mtr := mocktracer.New()
+	tests := []struct {
+		name     string
+		provider string
+		opt      []OptionFunc
+		want     interface{}
+	}{
+		{name: "jaeger", provider: "jaeger", want: &jaeger.Tracer{}},
+		{name: "zipkin", provider: "zipkin", want: &jaeger.Tracer{}},
+		{name: "noop", provider: "noop", want: opentracing.NoopTracer{}},
+		{name: "option overrides env", provider: "jaeger", opt: []OptionFunc{Tracing(trace.Memory(mtr))}, want: mtr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv(t, "PATRON_TRACING_PROVIDER", tt.provider)()
+			_, err := New("test", "", tt.opt...)
+			assert.NoError(t, err)
+			assert.IsType(t, tt.want, opentracing.GlobalTracer())
+			assert.NoError(t, trace.Close())
+		})
+	}
+}
+
+func TestServer_SetupMetrics(t *testing.T) {
+	mr, err := metrics.NewRegistry()
+	assert.NoError(t, err)
+	tests := []struct {
+		name      string
+		namespace string
+		opt       []OptionFunc
+		want      string
+	}{
+		{name: "default", want: `test_counter{service="test",version="1.0.0"} 1`},
+		{name: "namespace", namespace: "shop", want: `shop_test_counter{service="test",version="1.0.0"} 1`},
+		{name: "option overrides env", namespace: "shop", opt: []OptionFunc{Metrics(mr)}, want: `test_counter 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv(t, "PATRON_METRICS_NAMESPACE", tt.namespace)()
+			s, err := New("test", "1.0.0", tt.opt...)
+			assert.NoError(t, err)
+			assert.Equal(t, s.mr, metrics.DefaultRegistry())
+			cv, err := s.mr.CounterVec(prometheus.CounterOpts{Namespace: "test", Name: "counter", Help: "Test counter"})
+			assert.NoError(t, err)
+			cv.WithLabelValues().Inc()
+			rsp := httptest.NewRecorder()
+			s.mr.Handler().ServeHTTP(rsp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			assert.Contains(t, rsp.Body.String(), tt.want)
+		})
+	}
+}
+
+func TestServer_SetupShutdownGracePeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		gp      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "success", gp: "5s", want: 5 * time.Second},
+		{name: "failure, invalid duration", gp: "5", wantErr: true},
+		{name: "failure, negative duration", gp: "-5s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer setEnv(t, "PATRON_SHUTDOWN_GRACE_PERIOD", tt.gp)()
+			s, err := New("test", "")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, s)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, s.gracePeriod)
+			}
+		})
+	}
+}
+
 func getRandomPort() string {
 	rnd := 50000 + rand.Int63n(10000)
 	return strconv.FormatInt(rnd, 10)
 }
 
+// setEnv sets the env var and returns a func which restores its previous value, in order to be deferred.
+func setEnv(t *testing.T, key, value string) func() {
+	prev, ok := os.LookupEnv(key)
+	assert.NoError(t, os.Setenv(key, value))
+	return func() {
+		if ok {
+			assert.NoError(t, os.Setenv(key, prev))
+		} else {
+			assert.NoError(t, os.Unsetenv(key))
+		}
+	}
+}
+
 type testComponent struct {
 	errorRunning bool
 }
@@ -0,0 +1,257 @@
+package patron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// RestartPolicy type definition.
+type RestartPolicy int
+
+const (
+	// RestartOnFailure restarts the component only when it returns an error.
+	RestartOnFailure RestartPolicy = iota
+	// RestartAlways restarts the component whenever it returns, with or without an error.
+	RestartAlways
+)
+
+var restartPolicyNames = map[RestartPolicy]string{RestartOnFailure: "on-failure", RestartAlways: "always"}
+
+func componentRestartsCounter(mr *metrics.Registry) (*prometheus.CounterVec, error) {
+	return mr.CounterVec(
+		prometheus.CounterOpts{
+			Namespace: "component",
+			Subsystem: "supervisor",
+			Name:      "restarts",
+			Help:      "Component restarts, classified by component name and restart policy",
+		},
+		"name", "policy",
+	)
+}
+
+// Supervisor is a component which runs another component and restarts it according to a restart policy,
+// so that a failing component does not terminate the whole service.
+// The supervisor returns only when the context is cancelled, the component returns without an error
+// while the RestartOnFailure policy is in place or the maximum restarts within a window have been exceeded.
+type Supervisor struct {
+	name           string
+	cp             Component
+	policy         RestartPolicy
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	maxRestarts    int
+	window         time.Duration
+	mr             *metrics.Registry
+}
+
+// SupervisorOptionFunc definition for configuring the supervisor in a functional way.
+type SupervisorOptionFunc func(*Supervisor) error
+
+// NewSupervisor creates a supervisor for the provided component.
+// By default the component is restarted on failure with an exponential backoff starting at 1 second
+// and capped at 30 seconds, without a limit on the number of restarts.
+func NewSupervisor(name string, cp Component, oo ...SupervisorOptionFunc) (*Supervisor, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if cp == nil {
+		return nil, errors.New("component is required")
+	}
+
+	s := &Supervisor{
+		name:           name,
+		cp:             cp,
+		policy:         RestartOnFailure,
+		initialBackoff: defaultInitialBackoff,
+		maxBackoff:     defaultMaxBackoff,
+	}
+
+	for _, o := range oo {
+		err := o(s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Policy option for setting the restart policy of the supervisor.
+func Policy(p RestartPolicy) SupervisorOptionFunc {
+	return func(s *Supervisor) error {
+		if _, ok := restartPolicyNames[p]; !ok {
+			return errors.New("invalid restart policy provided")
+		}
+		s.policy = p
+		return nil
+	}
+}
+
+// Backoff option for setting the exponential backoff between restarts.
+// The wait starts at initial, doubles on every consecutive restart and is capped at max.
+// The wait is reset to initial when the component has been running for longer than max.
+func Backoff(initial, max time.Duration) SupervisorOptionFunc {
+	return func(s *Supervisor) error {
+		if initial <= 0 {
+			return errors.New("initial backoff must be positive")
+		}
+		if max < initial {
+			return errors.New("max backoff must be greater or equal than the initial backoff")
+		}
+		s.initialBackoff = initial
+		s.maxBackoff = max
+		return nil
+	}
+}
+
+// MaxRestarts option for limiting the restarts of the component within a time window.
+// When the limit is exceeded the supervisor gives up and returns an error.
+func MaxRestarts(restarts int, window time.Duration) SupervisorOptionFunc {
+	return func(s *Supervisor) error {
+		if restarts <= 0 {
+			return errors.New("max restarts must be positive")
+		}
+		if window <= 0 {
+			return errors.New("max restarts window must be positive")
+		}
+		s.maxRestarts = restarts
+		s.window = window
+		return nil
+	}
+}
+
+// SupervisorMetrics option for setting the registry of the restart metrics of the supervisor,
+// instead of the default registry of the metrics package at the time the supervisor runs.
+func SupervisorMetrics(mr *metrics.Registry) SupervisorOptionFunc {
+	return func(s *Supervisor) error {
+		if mr == nil {
+			return errors.New("metrics registry is required")
+		}
+		s.mr = mr
+		return nil
+	}
+}
+
+// Started forwards the start notification of the supervised component, if it implements StartNotifier.
+func (s *Supervisor) Started() <-chan struct{} {
+	if sn, ok := s.cp.(StartNotifier); ok {
+		return sn.Started()
+	}
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+
+// Reload forwards the reload notification to the supervised component, if it implements Reloadable.
+func (s *Supervisor) Reload(ctx context.Context) error {
+	if r, ok := s.cp.(Reloadable); ok {
+		return r.Reload(ctx)
+	}
+	return nil
+}
+
+// Info returns information about the supervised component along with the restart policy.
+func (s *Supervisor) Info() map[string]interface{} {
+	info := describe(s.cp)
+	info["supervisor"] = map[string]interface{}{
+		"name":            s.name,
+		"policy":          restartPolicyNames[s.policy],
+		"initial_backoff": s.initialBackoff.String(),
+		"max_backoff":     s.maxBackoff.String(),
+		"max_restarts":    s.maxRestarts,
+		"window":          s.window.String(),
+	}
+	return info
+}
+
+// Run starts the supervised component and restarts it according to the restart policy.
+func (s *Supervisor) Run(ctx context.Context) error {
+	mr := s.mr
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	componentRestarts, err := componentRestartsCounter(mr)
+	if err != nil {
+		return err
+	}
+
+	var restarts []time.Time
+	wait := s.initialBackoff
+
+	for {
+		start := time.Now()
+		err := s.cp.Run(ctx)
+		if ctx.Err() != nil {
+			return err
+		}
+		if err == nil && s.policy == RestartOnFailure {
+			return nil
+		}
+
+		now := time.Now()
+		if err != nil {
+			log.Errorf("supervised component %s failed: %v", s.name, err)
+		} else {
+			log.Warnf("supervised component %s returned", s.name)
+		}
+
+		if s.maxRestarts > 0 {
+			restarts = restartsWithin(restarts, now.Add(-s.window))
+			if len(restarts) >= s.maxRestarts {
+				if err == nil {
+					return fmt.Errorf("component %s exceeded %d restarts within %v", s.name, s.maxRestarts, s.window)
+				}
+				return fmt.Errorf("component %s exceeded %d restarts within %v: %w", s.name, s.maxRestarts, s.window, err)
+			}
+		}
+
+		if now.Sub(start) > s.maxBackoff {
+			wait = s.initialBackoff
+		}
+		log.Infof("restarting supervised component %s in %v", s.name, wait)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+
+		restarts = append(restarts, now)
+		componentRestarts.WithLabelValues(s.name, restartPolicyNames[s.policy]).Inc()
+		wait *= 2
+		if wait > s.maxBackoff {
+			wait = s.maxBackoff
+		}
+	}
+}
+
+// supervised returns the component which is run by the supervisor, unwrapping any nested supervisors,
+// or the component itself if it is not a supervisor.
+func supervised(cp Component) Component {
+	for {
+		s, ok := cp.(*Supervisor)
+		if !ok {
+			return cp
+		}
+		cp = s.cp
+	}
+}
+
+// restartsWithin drops the restarts which happened before the provided time.
+func restartsWithin(restarts []time.Time, since time.Time) []time.Time {
+	for i, r := range restarts {
+		if r.After(since) {
+			return restarts[i:]
+		}
+	}
+	return restarts[:0]
+}
@@ -0,0 +1,157 @@
+package patron
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/beatlabs/patron/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSupervisor(t *testing.T) {
+	type args struct {
+		name string
+		cp   Component
+		oo   []SupervisorOptionFunc
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+	}{
+		{"success", args{name: "name", cp: &testComponent{}}, false},
+		{"success with options", args{name: "name", cp: &testComponent{},
+			oo: []SupervisorOptionFunc{Policy(RestartAlways), Backoff(time.Second, time.Minute), MaxRestarts(3, time.Minute),
+				SupervisorMetrics(metrics.DefaultRegistry())}}, false},
+		{"failure, missing name", args{name: "", cp: &testComponent{}}, true},
+		{"failure, missing component", args{name: "name", cp: nil}, true},
+		{"failure, invalid policy", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{Policy(5)}}, true},
+		{"failure, invalid initial backoff", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{Backoff(0, time.Minute)}}, true},
+		{"failure, invalid max backoff", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{Backoff(time.Minute, time.Second)}}, true},
+		{"failure, invalid max restarts", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{MaxRestarts(0, time.Minute)}}, true},
+		{"failure, invalid max restarts window", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{MaxRestarts(1, 0)}}, true},
+		{"failure, missing metrics registry", args{name: "name", cp: &testComponent{}, oo: []SupervisorOptionFunc{SupervisorMetrics(nil)}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSupervisor(tt.args.name, tt.args.cp, tt.args.oo...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+			}
+		})
+	}
+}
+
+func TestSupervisor_Run(t *testing.T) {
+	tests := []struct {
+		name         string
+		cp           *flakyComponent
+		oo           []SupervisorOptionFunc
+		wantErr      string
+		wantRuns     int32
+		wantMinRuns  int32
+		cancelWithin time.Duration
+	}{
+		{
+			name:     "on failure, restarts until success",
+			cp:       &flakyComponent{failures: 3},
+			oo:       []SupervisorOptionFunc{Backoff(time.Millisecond, 2*time.Millisecond)},
+			wantRuns: 4,
+		},
+		{
+			name:     "on failure, gives up after max restarts",
+			cp:       &flakyComponent{failures: 10},
+			oo:       []SupervisorOptionFunc{Backoff(time.Millisecond, 2*time.Millisecond), MaxRestarts(2, time.Minute)},
+			wantErr:  "component test exceeded 2 restarts within 1m0s: component failed",
+			wantRuns: 3,
+		},
+		{
+			name:         "always, restarts on success until cancelled",
+			cp:           &flakyComponent{},
+			oo:           []SupervisorOptionFunc{Policy(RestartAlways), Backoff(time.Millisecond, time.Millisecond)},
+			wantMinRuns:  2,
+			cancelWithin: 50 * time.Millisecond,
+		},
+		{
+			name:     "always, gives up after max restarts",
+			cp:       &flakyComponent{},
+			oo:       []SupervisorOptionFunc{Policy(RestartAlways), Backoff(time.Millisecond, time.Millisecond), MaxRestarts(1, time.Minute)},
+			wantErr:  "component test exceeded 1 restarts within 1m0s",
+			wantRuns: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSupervisor("test", tt.cp, tt.oo...)
+			assert.NoError(t, err)
+			ctx := context.Background()
+			if tt.cancelWithin > 0 {
+				var cnl context.CancelFunc
+				ctx, cnl = context.WithTimeout(ctx, tt.cancelWithin)
+				defer cnl()
+			}
+			err = s.Run(ctx)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.wantMinRuns > 0 {
+				assert.True(t, atomic.LoadInt32(&tt.cp.runs) >= tt.wantMinRuns)
+			} else {
+				assert.Equal(t, tt.wantRuns, atomic.LoadInt32(&tt.cp.runs))
+			}
+		})
+	}
+}
+
+func TestSupervisor_Started(t *testing.T) {
+	s, err := NewSupervisor("test", &testComponent{})
+	assert.NoError(t, err)
+	_, open := <-s.Started()
+	assert.False(t, open)
+
+	oc := &orderedComponent{}
+	s, err = NewSupervisor("test", oc)
+	assert.NoError(t, err)
+	assert.Equal(t, oc.Started(), s.Started())
+}
+
+func TestSupervisor_Reload(t *testing.T) {
+	s, err := NewSupervisor("test", &testComponent{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Reload(context.Background()))
+
+	rc := &reloadableComponent{err: errors.New("failed to reload")}
+	s, err = NewSupervisor("test", rc)
+	assert.NoError(t, err)
+	assert.EqualError(t, s.Reload(context.Background()), "failed to reload")
+	assert.Equal(t, 1, rc.reloads)
+}
+
+func TestRestartsWithin(t *testing.T) {
+	now := time.Now()
+	rr := []time.Time{now.Add(-3 * time.Minute), now.Add(-2 * time.Minute), now.Add(-time.Second)}
+	assert.Equal(t, rr[2:], restartsWithin(rr, now.Add(-time.Minute)))
+	assert.Empty(t, restartsWithin(rr, now))
+}
+
+type flakyComponent struct {
+	failures int32
+	runs     int32
+}
+
+func (fc *flakyComponent) Run(ctx context.Context) error {
+	runs := atomic.AddInt32(&fc.runs, 1)
+	if runs <= fc.failures {
+		return errors.New("component failed")
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package http
 
 import (
 	"net/http"
+
+	"github.com/beatlabs/patron/health"
 )
 
 // AliveStatus type representing the liveness of the service via HTTP component.
@@ -17,17 +19,25 @@ const (
 // AliveCheckFunc defines a function type for implementing a liveness check.
 type AliveCheckFunc func() AliveStatus
 
-func aliveCheckRoute(acf AliveCheckFunc) Route {
+// aliveCheckRoute creates the liveness route. If a health registry is provided, the route responds
+// with the report of the liveness checks, which is down when the AliveCheckFunc reports unresponsive.
+func aliveCheckRoute(acf AliveCheckFunc, hr *health.Registry) Route {
 
 	f := func(w http.ResponseWriter, r *http.Request) {
-		switch acf() {
-		case Alive:
-			w.WriteHeader(http.StatusOK)
-		case Unresponsive:
-			w.WriteHeader(http.StatusServiceUnavailable)
-		default:
-			w.WriteHeader(http.StatusOK)
+		alive := acf() != Unresponsive
+		if hr == nil {
+			if alive {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
+		rep := hr.Alive(r.Context())
+		if !alive {
+			rep.Status = health.Down
 		}
+		writeHealthReport(w, rep)
 	}
 	return NewRouteRaw("/alive", http.MethodGet, f, false)
 }
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/beatlabs/patron/health"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,7 +23,7 @@ func Test_aliveCheckRoute(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := aliveCheckRoute(tt.acf)
+			r := aliveCheckRoute(tt.acf, nil)
 			resp := httptest.NewRecorder()
 			req, err := http.NewRequest("GET", "/alive", nil)
 			assert.NoError(t, err)
@@ -29,3 +32,35 @@ func Test_aliveCheckRoute(t *testing.T) {
 		})
 	}
 }
+
+func Test_aliveCheckRoute_HealthRegistry(t *testing.T) {
+	unhealthy := func(context.Context) error { return errors.New("unhealthy") }
+	tests := []struct {
+		name       string
+		acf        AliveCheckFunc
+		oo         []health.CheckOptionFunc
+		wantCode   int
+		wantStatus string
+	}{
+		{"healthy", func() AliveStatus { return Alive }, nil, http.StatusOK, `"status":"up"`},
+		{"non critical unhealthy", func() AliveStatus { return Alive }, []health.CheckOptionFunc{health.NonCritical(), health.Liveness()}, http.StatusOK, `"status":"degraded"`},
+		{"critical unhealthy", func() AliveStatus { return Alive }, []health.CheckOptionFunc{health.Liveness()}, http.StatusServiceUnavailable, `"status":"down"`},
+		{"check func down", func() AliveStatus { return Unresponsive }, nil, http.StatusServiceUnavailable, `"status":"down"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hr, err := health.NewRegistry()
+			assert.NoError(t, err)
+			if tt.oo != nil {
+				assert.NoError(t, hr.Register("check", unhealthy, tt.oo...))
+			}
+			r := aliveCheckRoute(tt.acf, hr)
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/alive", nil)
+			assert.NoError(t, err)
+			r.Handler(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), tt.wantStatus)
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/log"
+	"github.com/beatlabs/patron/metrics"
+	"github.com/beatlabs/patron/sync/http/auth"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -18,6 +22,8 @@ const (
 	httpReadTimeout  = 5 * time.Second
 	httpWriteTimeout = 10 * time.Second
 	httpIdleTimeout  = 120 * time.Second
+	// DefaultShutdownGracePeriod is the default time given to in-flight requests to complete on shutdown.
+	DefaultShutdownGracePeriod = 20 * time.Second
 )
 
 var (
@@ -31,48 +37,160 @@ var (
 type Component struct {
 	ac               AliveCheckFunc
 	rc               ReadyCheckFunc
+	inf              InfoFunc
+	cf               ConfigFunc
+	rf               ReloadFunc
+	rfAuth           auth.Authenticator
+	cm               ConsumerManager
+	cmAuth           auth.Authenticator
+	hr               *health.Registry
+	mr               *metrics.Registry
 	httpPort         int
+	mgmtPort         int
 	httpReadTimeout  time.Duration
 	httpWriteTimeout time.Duration
 	sync.Mutex
-	routes      []Route
-	middlewares []MiddlewareFunc
-	certFile    string
-	keyFile     string
+	routes              []Route
+	middlewares         []MiddlewareFunc
+	mgmtRoutes          []Route
+	mgmtMiddlewares     []MiddlewareFunc
+	certFile            string
+	keyFile             string
+	shutdownGracePeriod time.Duration
+	startOnce           sync.Once
+	started             chan struct{}
 }
 
-// Run starts the HTTP server.
+// Run starts the HTTP server and, if a management port is set, the management HTTP server.
+// Both servers are shut down together, when the context is cancelled or one of them fails.
+// The in-flight requests are given the shutdown grace period to complete, or less if the deadline of the context is earlier,
+// e.g. the deadline of the shutdown of the service.
 func (c *Component) Run(ctx context.Context) error {
 	c.Lock()
 	log.Debug("applying tracing to routes")
-	chFail := make(chan error)
-	srv := c.createHTTPServer()
-	go c.listenAndServe(srv, chFail)
+	chFail := make(chan error, 2)
+	srv := c.createHTTPServer(c.httpPort, c.routes, c.middlewares)
+	servers := []*http.Server{srv}
+	if c.mgmtPort != 0 {
+		servers = append(servers, c.createHTTPServer(c.mgmtPort, c.mgmtRoutes, c.mgmtMiddlewares))
+	}
 	c.Unlock()
 
+	lns, err := listen(servers)
+	if err != nil {
+		return err
+	}
+	go c.serve(srv, lns[0], chFail)
+	if len(servers) > 1 {
+		go c.serveManagement(servers[1], lns[1], chFail)
+	}
+	c.startOnce.Do(func() { close(c.started) })
+
 	select {
 	case <-ctx.Done():
-		log.Info("shutting down component")
-		return srv.Shutdown(ctx)
+		deadline := time.Now().Add(c.shutdownGracePeriod)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		log.Infof("shutting down component, waiting up to %v for in-flight requests", time.Until(deadline))
+		return c.shutdown(servers, deadline)
 	case err := <-chFail:
+		if len(servers) > 1 {
+			_ = c.shutdown(servers, time.Now().Add(c.shutdownGracePeriod))
+		}
 		return err
 	}
 }
 
-func (c *Component) listenAndServe(srv *http.Server, ch chan<- error) {
+// shutdown shuts down the servers concurrently, until the deadline.
+func (c *Component) shutdown(servers []*http.Server, deadline time.Time) error {
+	// A new context is required, since the one provided is already cancelled.
+	shCtx, cnl := context.WithDeadline(context.Background(), deadline)
+	defer cnl()
+
+	ee := make([]error, len(servers))
+	wg := sync.WaitGroup{}
+	wg.Add(len(servers))
+	for i, srv := range servers {
+		go func(i int, srv *http.Server) {
+			defer wg.Done()
+			ee[i] = srv.Shutdown(shCtx)
+		}(i, srv)
+	}
+	wg.Wait()
+	return patronErrors.Aggregate(ee...)
+}
+
+// Info returns information about the component and its routes.
+func (c *Component) Info() map[string]interface{} {
+	c.Lock()
+	defer c.Unlock()
+	inf := map[string]interface{}{
+		"type":                  "http",
+		"port":                  c.httpPort,
+		"ssl":                   c.certFile != "" && c.keyFile != "",
+		"read_timeout":          c.httpReadTimeout.String(),
+		"write_timeout":         c.httpWriteTimeout.String(),
+		"shutdown_grace_period": c.shutdownGracePeriod.String(),
+		"routes":                routeNames(c.routes),
+	}
+	if c.mgmtPort != 0 {
+		inf["management_port"] = c.mgmtPort
+		inf["management_routes"] = routeNames(c.mgmtRoutes)
+	}
+	return inf
+}
+
+func routeNames(routes []Route) []string {
+	rr := make([]string, 0, len(routes))
+	for _, r := range routes {
+		rr = append(rr, r.Method+" "+r.Pattern)
+	}
+	return rr
+}
+
+// Started returns a channel which is closed once the component is listening on its ports,
+// in order for the service to start the next phase only after the component accepts connections.
+func (c *Component) Started() <-chan struct{} {
+	return c.started
+}
+
+// listen opens the listeners of the servers, closing the ones already opened if any of them fails.
+func listen(servers []*http.Server) ([]net.Listener, error) {
+	lns := make([]net.Listener, 0, len(servers))
+	for _, srv := range servers {
+		ln, err := net.Listen("tcp", srv.Addr)
+		if err != nil {
+			for _, l := range lns {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s: %w", srv.Addr, err)
+		}
+		lns = append(lns, ln)
+	}
+	return lns, nil
+}
+
+func (c *Component) serve(srv *http.Server, ln net.Listener, ch chan<- error) {
 	if c.certFile != "" && c.keyFile != "" {
 		log.Infof("HTTPS component listening on port %d", c.httpPort)
-		ch <- srv.ListenAndServeTLS(c.certFile, c.keyFile)
+		ch <- srv.ServeTLS(ln, c.certFile, c.keyFile)
+		return
 	}
 
 	log.Infof("HTTP component listening on port %d", c.httpPort)
-	ch <- srv.ListenAndServe()
+	ch <- srv.Serve(ln)
 }
 
-func (c *Component) createHTTPServer() *http.Server {
-	log.Debugf("adding %d routes", len(c.routes))
+func (c *Component) serveManagement(srv *http.Server, ln net.Listener, ch chan<- error) {
+	log.Infof("HTTP management component listening on port %d", c.mgmtPort)
+	ch <- srv.Serve(ln)
+}
+
+func (c *Component) createHTTPServer(port int, routes []Route, middlewares []MiddlewareFunc) *http.Server {
+	log.Debugf("adding %d routes", len(routes))
 	router := httprouter.New()
-	for _, route := range c.routes {
+	for _, route := range routes {
 		if len(route.Middlewares) > 0 {
 			h := MiddlewareChain(route.Handler, route.Middlewares...)
 			router.Handler(route.Method, route.Pattern, h)
@@ -84,10 +202,10 @@ func (c *Component) createHTTPServer() *http.Server {
 	}
 	// Add first the recovery middleware to ensure that no panic occur.
 	routerAfterMiddleware := MiddlewareChain(router, NewRecoveryMiddleware())
-	routerAfterMiddleware = MiddlewareChain(routerAfterMiddleware, c.middlewares...)
+	routerAfterMiddleware = MiddlewareChain(routerAfterMiddleware, middlewares...)
 
 	return &http.Server{
-		Addr:         fmt.Sprintf(":%d", c.httpPort),
+		Addr:         fmt.Sprintf(":%d", port),
 		ReadTimeout:  c.httpReadTimeout,
 		WriteTimeout: c.httpWriteTimeout,
 		IdleTimeout:  httpIdleTimeout,
@@ -100,30 +218,42 @@ const fieldSetMsg = "Setting property '%v' for '%v'"
 // Builder gathers all required and optional properties, in order
 // to construct an HTTP component.
 type Builder struct {
-	ac               AliveCheckFunc
-	rc               ReadyCheckFunc
-	httpPort         int
-	httpReadTimeout  time.Duration
-	httpWriteTimeout time.Duration
-	routes           []Route
-	middlewares      []MiddlewareFunc
-	certFile         string
-	keyFile          string
-	errors           []error
+	ac                  AliveCheckFunc
+	rc                  ReadyCheckFunc
+	inf                 InfoFunc
+	cf                  ConfigFunc
+	rf                  ReloadFunc
+	rfAuth              auth.Authenticator
+	cm                  ConsumerManager
+	cmAuth              auth.Authenticator
+	hr                  *health.Registry
+	mr                  *metrics.Registry
+	httpPort            int
+	mgmtPort            int
+	httpReadTimeout     time.Duration
+	httpWriteTimeout    time.Duration
+	routes              []Route
+	middlewares         []MiddlewareFunc
+	mgmtMiddlewares     []MiddlewareFunc
+	certFile            string
+	keyFile             string
+	shutdownGracePeriod time.Duration
+	errors              []error
 }
 
 // NewBuilder initiates the HTTP component builder chain.
 // The builder instantiates the component using default values for
-// HTTP Port, Alive/Ready check functions and Read/Write timeouts.
+// HTTP Port, Alive/Ready check functions, Read/Write timeouts and shutdown grace period.
 func NewBuilder() *Builder {
 	var errs []error
 	return &Builder{
-		ac:               DefaultAliveCheck,
-		rc:               DefaultReadyCheck,
-		httpPort:         httpPort,
-		httpReadTimeout:  httpReadTimeout,
-		httpWriteTimeout: httpWriteTimeout,
-		errors:           errs,
+		ac:                  DefaultAliveCheck,
+		rc:                  DefaultReadyCheck,
+		httpPort:            httpPort,
+		httpReadTimeout:     httpReadTimeout,
+		httpWriteTimeout:    httpWriteTimeout,
+		shutdownGracePeriod: DefaultShutdownGracePeriod,
+		errors:              errs,
 	}
 }
 
@@ -188,6 +318,18 @@ func (cb *Builder) WithWriteTimeout(wt time.Duration) *Builder {
 	return cb
 }
 
+// WithShutdownGracePeriod sets the time given to in-flight requests to complete when the component shuts down.
+func (cb *Builder) WithShutdownGracePeriod(gp time.Duration) *Builder {
+	if gp <= 0*time.Second {
+		cb.errors = append(cb.errors, errors.New("Negative or zero shutdown grace period provided"))
+	} else {
+		log.Infof(fieldSetMsg, "Shutdown Grace Period", gp)
+		cb.shutdownGracePeriod = gp
+	}
+
+	return cb
+}
+
 // WithPort sets the port used by the HTTP component.
 func (cb *Builder) WithPort(p int) *Builder {
 	if p <= 0 || p > 65535 {
@@ -200,6 +342,34 @@ func (cb *Builder) WithPort(p int) *Builder {
 	return cb
 }
 
+// WithManagementPort sets the port of a dedicated listener, which serves the management routes
+// (liveness, readiness, info, config, reload, consumers, profiling and metrics) instead of the main port.
+// The management listener does not use SSL.
+func (cb *Builder) WithManagementPort(p int) *Builder {
+	if p <= 0 || p > 65535 {
+		cb.errors = append(cb.errors, errors.New("Invalid management HTTP Port provided"))
+	} else {
+		log.Infof(fieldSetMsg, "Management Port", p)
+		cb.mgmtPort = p
+	}
+
+	return cb
+}
+
+// WithManagementMiddlewares adds middlewares to the management listener.
+// They are only applied when a management port is set, in which case the middlewares
+// set with WithMiddlewares are not applied to the management routes.
+func (cb *Builder) WithManagementMiddlewares(mm ...MiddlewareFunc) *Builder {
+	if len(mm) == 0 {
+		cb.errors = append(cb.errors, errors.New("Empty list of management middlewares provided"))
+	} else {
+		log.Info(fieldSetMsg, "Management Middlewares", mm)
+		cb.mgmtMiddlewares = append(cb.mgmtMiddlewares, mm...)
+	}
+
+	return cb
+}
+
 // WithAliveCheckFunc sets the AliveCheckFunc used by the HTTP component.
 func (cb *Builder) WithAliveCheckFunc(acf AliveCheckFunc) *Builder {
 	if acf == nil {
@@ -224,28 +394,138 @@ func (cb *Builder) WithReadyCheckFunc(rcf ReadyCheckFunc) *Builder {
 	return cb
 }
 
+// WithInfoFunc sets the InfoFunc used by the HTTP component in order to expose the /info route.
+func (cb *Builder) WithInfoFunc(inf InfoFunc) *Builder {
+	if inf == nil {
+		cb.errors = append(cb.errors, errors.New("Nil InfoFunc provided"))
+	} else {
+		log.Infof(fieldSetMsg, "InfoFunc", inf)
+		cb.inf = inf
+	}
+
+	return cb
+}
+
+// WithConfigFunc sets the ConfigFunc used by the HTTP component in order to expose the /config route.
+func (cb *Builder) WithConfigFunc(cf ConfigFunc) *Builder {
+	if cf == nil {
+		cb.errors = append(cb.errors, errors.New("Nil ConfigFunc provided"))
+	} else {
+		log.Infof(fieldSetMsg, "ConfigFunc", cf)
+		cb.cf = cf
+	}
+
+	return cb
+}
+
+// WithReloadFunc sets the ReloadFunc used by the HTTP component in order to expose the /reload route.
+// The route reloads the configuration of the service and requires authentication.
+func (cb *Builder) WithReloadFunc(rf ReloadFunc, auth auth.Authenticator) *Builder {
+	if rf == nil || auth == nil {
+		cb.errors = append(cb.errors, errors.New("Nil ReloadFunc or Authenticator provided"))
+	} else {
+		log.Infof(fieldSetMsg, "ReloadFunc", rf)
+		cb.rf = rf
+		cb.rfAuth = auth
+	}
+
+	return cb
+}
+
+// WithConsumerManager sets the ConsumerManager used by the HTTP component in order to expose the /consumers routes.
+// The routes which pause and resume a consumer require authentication.
+func (cb *Builder) WithConsumerManager(cm ConsumerManager, auth auth.Authenticator) *Builder {
+	if cm == nil || auth == nil {
+		cb.errors = append(cb.errors, errors.New("Nil ConsumerManager or Authenticator provided"))
+	} else {
+		log.Infof(fieldSetMsg, "ConsumerManager", cm)
+		cb.cm = cm
+		cb.cmAuth = auth
+	}
+
+	return cb
+}
+
+// WithHealthRegistry sets the health registry whose checks are reported by the /alive and /ready routes.
+func (cb *Builder) WithHealthRegistry(hr *health.Registry) *Builder {
+	if hr == nil {
+		cb.errors = append(cb.errors, errors.New("Nil health registry provided"))
+	} else {
+		log.Infof(fieldSetMsg, "HealthRegistry", hr)
+		cb.hr = hr
+	}
+
+	return cb
+}
+
+// WithMetrics sets the registry whose metrics are exposed by the /metrics route,
+// instead of the default registry of the metrics package.
+func (cb *Builder) WithMetrics(mr *metrics.Registry) *Builder {
+	if mr == nil {
+		cb.errors = append(cb.errors, errors.New("Nil metrics registry provided"))
+	} else {
+		log.Infof(fieldSetMsg, "Metrics", mr)
+		cb.mr = mr
+	}
+
+	return cb
+}
+
 // Create constructs the HTTP component by applying the gathered properties.
 func (cb *Builder) Create() (*Component, error) {
-	if len(cb.errors) > 0 {
-		return nil, patronErrors.Aggregate(cb.errors...)
+	ee := cb.errors
+	if cb.mgmtPort != 0 && cb.mgmtPort == cb.httpPort {
+		ee = append(ee, errors.New("Management port has to differ from the HTTP port"))
+	}
+	if len(ee) > 0 {
+		return nil, patronErrors.Aggregate(ee...)
 	}
 
 	c := &Component{
-		ac:               cb.ac,
-		rc:               cb.rc,
-		httpPort:         cb.httpPort,
-		httpReadTimeout:  cb.httpReadTimeout,
-		httpWriteTimeout: cb.httpWriteTimeout,
-		routes:           cb.routes,
-		middlewares:      cb.middlewares,
-		certFile:         cb.certFile,
-		keyFile:          cb.keyFile,
-	}
-
-	c.routes = append(c.routes, aliveCheckRoute(c.ac))
-	c.routes = append(c.routes, readyCheckRoute(c.rc))
-	c.routes = append(c.routes, profilingRoutes()...)
-	c.routes = append(c.routes, metricRoute())
+		ac:                  cb.ac,
+		rc:                  cb.rc,
+		inf:                 cb.inf,
+		cf:                  cb.cf,
+		rf:                  cb.rf,
+		rfAuth:              cb.rfAuth,
+		cm:                  cb.cm,
+		cmAuth:              cb.cmAuth,
+		hr:                  cb.hr,
+		mr:                  cb.mr,
+		httpPort:            cb.httpPort,
+		mgmtPort:            cb.mgmtPort,
+		httpReadTimeout:     cb.httpReadTimeout,
+		httpWriteTimeout:    cb.httpWriteTimeout,
+		routes:              cb.routes,
+		middlewares:         cb.middlewares,
+		mgmtMiddlewares:     cb.mgmtMiddlewares,
+		certFile:            cb.certFile,
+		keyFile:             cb.keyFile,
+		shutdownGracePeriod: cb.shutdownGracePeriod,
+		started:             make(chan struct{}),
+	}
+
+	mgmt := []Route{aliveCheckRoute(c.ac, c.hr), readyCheckRoute(c.rc, c.hr)}
+	if c.inf != nil {
+		mgmt = append(mgmt, infoRoute(c.inf))
+	}
+	if c.cf != nil {
+		mgmt = append(mgmt, configRoute(c.cf))
+	}
+	if c.rf != nil {
+		mgmt = append(mgmt, reloadRoute(c.rf, c.rfAuth))
+	}
+	if c.cm != nil {
+		mgmt = append(mgmt, consumerRoutes(c.cm, c.cmAuth)...)
+	}
+	mgmt = append(mgmt, profilingRoutes()...)
+	mgmt = append(mgmt, metricRoute(c.mr))
+
+	if c.mgmtPort != 0 {
+		c.mgmtRoutes = mgmt
+	} else {
+		c.routes = append(c.routes, mgmt...)
+	}
 
 	return c, nil
 }
@@ -3,9 +3,12 @@ package http
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/beatlabs/patron/health"
+	"github.com/beatlabs/patron/metrics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,6 +34,101 @@ func TestComponent_ListenAndServe_DefaultRoutes_Shutdown(t *testing.T) {
 	assert.True(t, <-done)
 }
 
+func TestComponent_Shutdown_DrainsInFlightRequests(t *testing.T) {
+	chInFlight := make(chan struct{})
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		close(chInFlight)
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusAccepted)
+	}
+	rr := []Route{NewRouteRaw("/slow", http.MethodGet, slow, false)}
+	s, err := NewBuilder().WithRoutes(rr).WithPort(50004).WithShutdownGracePeriod(time.Second).Create()
+	assert.NoError(t, err)
+	done := make(chan bool)
+	ctx, cnl := context.WithCancel(context.Background())
+	go func() {
+		assert.NoError(t, s.Run(ctx))
+		done <- true
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	chStatus := make(chan int)
+	go func() {
+		rsp, err := http.Get("http://localhost:50004/slow")
+		assert.NoError(t, err)
+		chStatus <- rsp.StatusCode
+	}()
+	<-chInFlight
+	cnl()
+
+	assert.Equal(t, http.StatusAccepted, <-chStatus)
+	assert.True(t, <-done)
+}
+
+func TestComponent_Shutdown_ContextDeadline(t *testing.T) {
+	chInFlight := make(chan struct{})
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		close(chInFlight)
+		time.Sleep(time.Second)
+		w.WriteHeader(http.StatusAccepted)
+	}
+	rr := []Route{NewRouteRaw("/slow", http.MethodGet, slow, false)}
+	s, err := NewBuilder().WithRoutes(rr).WithPort(50005).WithShutdownGracePeriod(5 * time.Second).Create()
+	assert.NoError(t, err)
+	ctx, cnl := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cnl()
+	chErr := make(chan error)
+	go func() {
+		chErr <- s.Run(ctx)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	go func() {
+		_, _ = http.Get("http://localhost:50005/slow")
+	}()
+	<-chInFlight
+
+	// the in-flight request is not drained past the deadline of the context, which is earlier than the grace period
+	start := time.Now()
+	err = <-chErr
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+}
+
+func TestComponent_Started(t *testing.T) {
+	rr := []Route{NewRouteRaw("/", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {}, false)}
+	s, err := NewBuilder().WithRoutes(rr).WithPort(50009).WithManagementPort(50010).Create()
+	assert.NoError(t, err)
+	ctx, cnl := context.WithCancel(context.Background())
+	chErr := make(chan error)
+	go func() {
+		chErr <- s.Run(ctx)
+	}()
+	<-s.Started()
+
+	// the component accepts connections on both ports as soon as it has started
+	for _, url := range []string{"http://localhost:50009/", "http://localhost:50010/alive"} {
+		rsp, err := http.Get(url)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rsp.StatusCode)
+		assert.NoError(t, rsp.Body.Close())
+	}
+
+	// a component which fails to listen does not start
+	busy, err := NewBuilder().WithRoutes(rr).WithPort(50011).WithManagementPort(50009).Create()
+	assert.NoError(t, err)
+	assert.Error(t, busy.Run(context.Background()))
+	select {
+	case <-busy.Started():
+		assert.Fail(t, "component started although it failed to listen")
+	default:
+	}
+
+	cnl()
+	assert.NoError(t, <-chErr)
+}
+
 func TestComponent_ListenAndServeTLS_DefaultRoutes_Shutdown(t *testing.T) {
 	rr := []Route{NewRoute("/", "GET", nil, true, nil)}
 	s, err := NewBuilder().WithRoutes(rr).WithSSL("testdata/server.pem", "testdata/server.key").WithPort(50003).Create()
@@ -47,6 +145,77 @@ func TestComponent_ListenAndServeTLS_DefaultRoutes_Shutdown(t *testing.T) {
 	assert.True(t, <-done)
 }
 
+func TestComponent_Info(t *testing.T) {
+	rr := []Route{NewRoute("/", "GET", nil, true, nil)}
+	inf := func() map[string]interface{} { return nil }
+	s, err := NewBuilder().WithRoutes(rr).WithPort(50005).WithInfoFunc(inf).Create()
+	assert.NoError(t, err)
+	info := s.Info()
+	assert.Equal(t, "http", info["type"])
+	assert.Equal(t, 50005, info["port"])
+	assert.Equal(t, false, info["ssl"])
+	routes := info["routes"].([]string)
+	assert.Len(t, routes, 16)
+	assert.Contains(t, routes, "GET /")
+	assert.Contains(t, routes, "GET /info")
+}
+
+func TestComponent_ManagementPort(t *testing.T) {
+	rr := []Route{NewRouteRaw("/", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {}, false)}
+	mgmtHeader := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Management", "true")
+			next.ServeHTTP(w, r)
+		})
+	}
+	s, err := NewBuilder().WithRoutes(rr).WithPort(50006).WithManagementPort(50007).
+		WithManagementMiddlewares(mgmtHeader).Create()
+	assert.NoError(t, err)
+	assert.Len(t, s.routes, 1)
+	assert.Len(t, s.mgmtRoutes, 14)
+	info := s.Info()
+	assert.Equal(t, 50007, info["management_port"])
+	assert.Len(t, info["management_routes"], 14)
+
+	done := make(chan bool)
+	ctx, cnl := context.WithCancel(context.Background())
+	go func() {
+		assert.NoError(t, s.Run(ctx))
+		done <- true
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantHeader string
+	}{
+		{"http://localhost:50006/", http.StatusOK, ""},
+		{"http://localhost:50006/metrics", http.StatusNotFound, ""},
+		{"http://localhost:50007/metrics", http.StatusOK, "true"},
+		{"http://localhost:50007/alive", http.StatusOK, "true"},
+		{"http://localhost:50007/", http.StatusNotFound, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			rsp, err := http.Get(tt.url)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rsp.StatusCode)
+			assert.Equal(t, tt.wantHeader, rsp.Header.Get("X-Management"))
+			assert.NoError(t, rsp.Body.Close())
+		})
+	}
+
+	cnl()
+	assert.True(t, <-done)
+}
+
+func TestBuilder_ManagementPortSameAsPort(t *testing.T) {
+	got, err := NewBuilder().WithPort(50008).WithManagementPort(50008).Create()
+	assert.Error(t, err)
+	assert.Nil(t, got)
+}
+
 func TestComponent_ListenAndServeTLS_FailsInvalidCerts(t *testing.T) {
 	rr := []Route{NewRoute("/", "GET", nil, true, nil)}
 	s, err := NewBuilder().WithRoutes(rr).WithSSL("testdata/server.pem", "testdata/server.pem").Create()
@@ -60,7 +229,7 @@ func Test_createHTTPServer(t *testing.T) {
 		httpReadTimeout:  5 * time.Second,
 		httpWriteTimeout: 10 * time.Second,
 	}
-	s := cmp.createHTTPServer()
+	s := cmp.createHTTPServer(cmp.httpPort, nil, nil)
 	assert.NotNil(t, s)
 	assert.Equal(t, ":10000", s.Addr)
 	assert.Equal(t, 5*time.Second, s.ReadTimeout)
@@ -79,6 +248,15 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 		errors.New("Empty Routes slice provided"),
 		errors.New("Empty list of middlewares provided"),
 		errors.New("Invalid cert or key provided"),
+		errors.New("Negative or zero shutdown grace period provided"),
+		errors.New("Nil InfoFunc provided"),
+		errors.New("Nil ConfigFunc provided"),
+		errors.New("Nil ReloadFunc or Authenticator provided"),
+		errors.New("Nil ConsumerManager or Authenticator provided"),
+		errors.New("Nil health registry provided"),
+		errors.New("Invalid management HTTP Port provided"),
+		errors.New("Empty list of management middlewares provided"),
+		errors.New("Nil metrics registry provided"),
 	}
 
 	tests := map[string]struct {
@@ -91,6 +269,15 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 		mm       []MiddlewareFunc
 		c        string
 		k        string
+		gp       time.Duration
+		inf      InfoFunc
+		cf       ConfigFunc
+		rf       ReloadFunc
+		cm       ConsumerManager
+		hr       *health.Registry
+		mp       int
+		mmm      []MiddlewareFunc
+		mr       *metrics.Registry
 		wantErrs []error
 	}{
 		"success": {
@@ -100,9 +287,9 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 			rt:  httpReadTimeout,
 			wt:  httpIdleTimeout,
 			rr: []Route{
-				aliveCheckRoute(DefaultAliveCheck),
-				readyCheckRoute(DefaultReadyCheck),
-				metricRoute(),
+				aliveCheckRoute(DefaultAliveCheck, nil),
+				readyCheckRoute(DefaultReadyCheck, nil),
+				metricRoute(nil),
 			},
 			mm: []MiddlewareFunc{
 				NewRecoveryMiddleware(),
@@ -110,6 +297,15 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 			},
 			c:        "cert.file",
 			k:        "key.file",
+			gp:       time.Second,
+			inf:      func() map[string]interface{} { return nil },
+			cf:       func() map[string]interface{} { return nil },
+			rf:       func(context.Context) error { return nil },
+			cm:       &mockConsumerManager{},
+			hr:       health.DefaultRegistry(),
+			mp:       httpPort + 1,
+			mmm:      []MiddlewareFunc{NewRecoveryMiddleware()},
+			mr:       metrics.DefaultRegistry(),
 			wantErrs: httpBuilderNoErrors,
 		},
 		"error in all builder steps": {
@@ -122,6 +318,15 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 			mm:       []MiddlewareFunc{},
 			c:        "",
 			k:        "",
+			gp:       0,
+			inf:      nil,
+			cf:       nil,
+			rf:       nil,
+			cm:       nil,
+			hr:       nil,
+			mp:       -1,
+			mmm:      []MiddlewareFunc{},
+			mr:       nil,
 			wantErrs: httpBuilderAllErrors,
 		},
 	}
@@ -137,6 +342,15 @@ func Test_createHTTPServerUsingBuilder(t *testing.T) {
 				WithRoutes(tc.rr).
 				WithMiddlewares(tc.mm...).
 				WithSSL(tc.c, tc.k).
+				WithShutdownGracePeriod(tc.gp).
+				WithInfoFunc(tc.inf).
+				WithConfigFunc(tc.cf).
+				WithReloadFunc(tc.rf, &MockAuthenticator{}).
+				WithConsumerManager(tc.cm, &MockAuthenticator{}).
+				WithHealthRegistry(tc.hr).
+				WithManagementPort(tc.mp).
+				WithManagementMiddlewares(tc.mmm...).
+				WithMetrics(tc.mr).
 				Create()
 
 			if len(tc.wantErrs) > 0 {
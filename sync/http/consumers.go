@@ -0,0 +1,64 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/sync/http/auth"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ErrConsumerNotFound is returned by a ConsumerManager when there is no consumer with the provided name.
+var ErrConsumerNotFound = errors.New("consumer not found")
+
+// ConsumerStatus of an async component, as reported by the /consumers route.
+type ConsumerStatus struct {
+	Name     string `json:"name"`
+	Paused   bool   `json:"paused"`
+	InFlight int    `json:"in_flight"`
+}
+
+// ConsumerManager defines the management of the async components of the service, which are listed, paused and resumed by name.
+type ConsumerManager interface {
+	Consumers() []ConsumerStatus
+	Pause(name string) error
+	Resume(name string) error
+}
+
+// consumerRoutes creates the route which lists the consumers, and the routes which pause and resume a consumer,
+// which require authentication.
+func consumerRoutes(cm ConsumerManager, auth auth.Authenticator) []Route {
+
+	list := func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Encode(cm.Consumers())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", json.TypeCharset)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}
+
+	return []Route{
+		NewRouteRaw("/consumers", http.MethodGet, list, false),
+		NewRouteRaw("/consumers/:name/pause", http.MethodPost, consumerHandler(cm.Pause), false, NewAuthMiddleware(auth)),
+		NewRouteRaw("/consumers/:name/resume", http.MethodPost, consumerHandler(cm.Resume), false, NewAuthMiddleware(auth)),
+	}
+}
+
+func consumerHandler(fn func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := fn(httprouter.ParamsFromContext(r.Context()).ByName("name"))
+		if errors.Is(err, ErrConsumerNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
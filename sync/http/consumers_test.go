@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConsumerManager struct {
+	paused map[string]bool
+	err    error
+}
+
+func (m *mockConsumerManager) Consumers() []ConsumerStatus {
+	return []ConsumerStatus{{Name: "orders", Paused: m.paused["orders"], InFlight: 2}}
+}
+
+func (m *mockConsumerManager) Pause(name string) error {
+	return m.set(name, true)
+}
+
+func (m *mockConsumerManager) Resume(name string) error {
+	return m.set(name, false)
+}
+
+func (m *mockConsumerManager) set(name string, paused bool) error {
+	if m.err != nil {
+		return m.err
+	}
+	if name != "orders" {
+		return fmt.Errorf("%w: %s", ErrConsumerNotFound, name)
+	}
+	m.paused[name] = paused
+	return nil
+}
+
+func Test_consumerRoutes_List(t *testing.T) {
+	rr := consumerRoutes(&mockConsumerManager{paused: map[string]bool{"orders": true}}, MockAuthenticator{success: true})
+	require.Len(t, rr, 3)
+	assert.Equal(t, "/consumers", rr[0].Pattern)
+	assert.Equal(t, http.MethodGet, rr[0].Method)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/consumers", nil)
+	require.NoError(t, err)
+	rr[0].Handler(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `[{"name":"orders","paused":true,"in_flight":2}]`, resp.Body.String())
+}
+
+func Test_consumerRoutes_PauseResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		route      int
+		consumer   string
+		paused     bool
+		err        error
+		auth       MockAuthenticator
+		wantCode   int
+		wantPaused bool
+	}{
+		{"pause", 1, "orders", false, nil, MockAuthenticator{success: true}, http.StatusNoContent, true},
+		{"resume", 2, "orders", true, nil, MockAuthenticator{success: true}, http.StatusNoContent, false},
+		{"failure not found", 1, "payments", false, nil, MockAuthenticator{success: true}, http.StatusNotFound, false},
+		{"failure pausing", 1, "orders", false, errors.New("failed to pause"), MockAuthenticator{success: true}, http.StatusInternalServerError, false},
+		{"failure unauthenticated", 1, "orders", false, nil, MockAuthenticator{success: false}, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &mockConsumerManager{paused: map[string]bool{"orders": tt.paused}, err: tt.err}
+			r := consumerRoutes(cm, tt.auth)[tt.route]
+			assert.Equal(t, http.MethodPost, r.Method)
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/consumers/"+tt.consumer, nil)
+			require.NoError(t, err)
+			req = req.WithContext(context.WithValue(req.Context(), httprouter.ParamsKey, httprouter.Params{{Key: "name", Value: tt.consumer}}))
+			var h http.Handler = r.Handler
+			for _, m := range r.Middlewares {
+				h = m(h)
+			}
+			h.ServeHTTP(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code)
+			assert.Equal(t, tt.wantPaused, cm.paused["orders"])
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
-	"github.com/beatlabs/patron/encoding/protobuf"
 	"github.com/beatlabs/patron/log"
 	"github.com/beatlabs/patron/sync"
 	"github.com/julienschmidt/httprouter"
@@ -65,42 +64,39 @@ func determineEncoding(r *http.Request) (string, encoding.DecodeFunc, encoding.E
 	var ct string
 
 	if cok {
-		switch cth[0] {
-		case "*/*", json.Type, json.TypeCharset:
-			enc = json.Encode
-			dec = json.Decode
-			ct = json.TypeCharset
-		case protobuf.Type, protobuf.TypeGoogle:
-			enc = protobuf.Encode
-			dec = protobuf.Decode
-			ct = protobuf.Type
-		default:
+		c, ok := lookupCodec(cth[0])
+		if !ok {
 			return "", nil, nil, errors.New("content type header not supported")
 		}
+		enc = c.Encode
+		dec = c.Decode
+		ct = c.ContentType
 	}
 
 	if aok {
-		switch ach[0] {
-		case "*/*", json.Type, json.TypeCharset:
-			enc = json.Encode
-			if dec == nil {
-				dec = json.Decode
-			}
-			ct = json.TypeCharset
-		case protobuf.Type, protobuf.TypeGoogle:
-			enc = protobuf.Encode
-			if dec == nil {
-				dec = protobuf.Decode
-			}
-			ct = protobuf.Type
-		default:
+		c, ok := lookupCodec(ach[0])
+		if !ok {
 			return "", nil, nil, errors.New("accept header not supported")
 		}
+		enc = c.Encode
+		if dec == nil {
+			dec = c.Decode
+		}
+		ct = c.ContentType
 	}
 
 	return ct, dec, enc, nil
 }
 
+// lookupCodec returns the codec of the media type from the codecs registered in the encoding package,
+// where any media type defaults to JSON.
+func lookupCodec(mediaType string) (encoding.Codec, bool) {
+	if mediaType == "*/*" {
+		mediaType = json.Type
+	}
+	return encoding.Lookup(mediaType)
+}
+
 func extractFields(r *http.Request) map[string]string {
 	f := make(map[string]string)
 
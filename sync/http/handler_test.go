@@ -84,6 +84,27 @@ func Test_determineEncoding(t *testing.T) {
 	}
 }
 
+func Test_determineEncoding_RegisteredCodec(t *testing.T) {
+	err := encoding.Register(encoding.Codec{
+		ContentType: "text/x-handler-test",
+		Encode:      func(v interface{}) ([]byte, error) { return []byte(v.(string)), nil },
+		DecodeRaw:   func(data []byte, v interface{}) error { return nil },
+	})
+	require.NoError(t, err)
+
+	ct, dec, enc, err := determineEncoding(request(t, "text/x-handler-test", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "text/x-handler-test", ct)
+	assert.NotNil(t, dec)
+	b, err := enc("value")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), b)
+
+	ct, _, _, err = determineEncoding(request(t, "text/x-handler-test", json.Type))
+	assert.NoError(t, err)
+	assert.Equal(t, json.TypeCharset, ct)
+}
+
 func request(t *testing.T, contentType, accept string) *http.Request {
 	req, err := http.NewRequest(http.MethodGet, "/", nil)
 	require.NoError(t, err)
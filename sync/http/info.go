@@ -0,0 +1,55 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/sync/http/auth"
+)
+
+// InfoFunc defines a function type for providing information about the service and its components.
+type InfoFunc func() map[string]interface{}
+
+// ConfigFunc defines a function type for providing the effective configuration of the service.
+type ConfigFunc func() map[string]interface{}
+
+func infoRoute(inf InfoFunc) Route {
+	return jsonRoute("/info", inf)
+}
+
+func configRoute(cf ConfigFunc) Route {
+	return jsonRoute("/config", cf)
+}
+
+// jsonRoute creates a route which responds with the JSON encoded result of the provided function.
+func jsonRoute(path string, fn func() map[string]interface{}) Route {
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Encode(fn())
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", json.TypeCharset)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(b)
+	}
+	return NewRouteRaw(path, http.MethodGet, f, false)
+}
+
+// ReloadFunc defines a function type for reloading the configuration of the service at runtime.
+type ReloadFunc func(ctx context.Context) error
+
+func reloadRoute(rf ReloadFunc, auth auth.Authenticator) Route {
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		err := rf(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return NewRouteRaw("/reload", http.MethodPost, f, false, NewAuthMiddleware(auth))
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_infoRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		inf      InfoFunc
+		wantCode int
+		wantBody string
+	}{
+		{"success", func() map[string]interface{} { return map[string]interface{}{"name": "test"} }, http.StatusOK, `{"name":"test"}`},
+		{"failure encoding", func() map[string]interface{} { return map[string]interface{}{"ch": make(chan int)} }, http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := infoRoute(tt.inf)
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest("GET", "/info", nil)
+			assert.NoError(t, err)
+			r.Handler(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func Test_configRoute(t *testing.T) {
+	r := configRoute(func() map[string]interface{} { return map[string]interface{}{"port": 50000} })
+	assert.Equal(t, "/config", r.Pattern)
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/config", nil)
+	assert.NoError(t, err)
+	r.Handler(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"port":50000}`, resp.Body.String())
+}
+
+func Test_reloadRoute(t *testing.T) {
+	success := func(context.Context) error { return nil }
+	failure := func(context.Context) error { return errors.New("failed to reload") }
+	tests := []struct {
+		name     string
+		rf       ReloadFunc
+		auth     MockAuthenticator
+		wantCode int
+	}{
+		{"success", success, MockAuthenticator{success: true}, http.StatusNoContent},
+		{"failure reloading", failure, MockAuthenticator{success: true}, http.StatusInternalServerError},
+		{"failure unauthenticated", success, MockAuthenticator{success: false}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := reloadRoute(tt.rf, tt.auth)
+			assert.Equal(t, "/reload", r.Pattern)
+			assert.Equal(t, http.MethodPost, r.Method)
+			resp := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodPost, "/reload", nil)
+			assert.NoError(t, err)
+			var h http.Handler = r.Handler
+			for _, m := range r.Middlewares {
+				h = m(h)
+			}
+			h.ServeHTTP(resp, req)
+			assert.Equal(t, tt.wantCode, resp.Code)
+		})
+	}
+}
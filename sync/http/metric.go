@@ -3,9 +3,12 @@ package http
 import (
 	"net/http"
 
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/beatlabs/patron/metrics"
 )
 
-func metricRoute() Route {
-	return NewRouteRaw("/metrics", http.MethodGet, promhttp.Handler().ServeHTTP, false)
+func metricRoute(mr *metrics.Registry) Route {
+	if mr == nil {
+		mr = metrics.DefaultRegistry()
+	}
+	return NewRouteRaw("/metrics", http.MethodGet, mr.Handler().ServeHTTP, false)
 }
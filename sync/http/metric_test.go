@@ -2,15 +2,31 @@ package http
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/beatlabs/patron/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_metricRoute(t *testing.T) {
-	route := metricRoute()
+	route := metricRoute(nil)
 	assert.Equal(t, http.MethodGet, route.Method)
 	assert.Equal(t, "/metrics", route.Pattern)
 	assert.NotNil(t, route.Handler)
 	assert.False(t, route.Trace)
 }
+
+func Test_metricRoute_Registry(t *testing.T) {
+	mr, err := metrics.NewRegistry(metrics.Namespace("test"), metrics.ConstLabels(map[string]string{"service": "route"}))
+	assert.NoError(t, err)
+	cv, err := mr.CounterVec(prometheus.CounterOpts{Subsystem: "http", Name: "requests", Help: "Requests"}, "code")
+	assert.NoError(t, err)
+	cv.WithLabelValues("200").Inc()
+
+	rsp := httptest.NewRecorder()
+	metricRoute(mr).Handler(rsp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rsp.Code)
+	assert.Contains(t, rsp.Body.String(), `test_http_requests{code="200",service="route"} 1`)
+}
@@ -2,6 +2,9 @@ package http
 
 import (
 	"net/http"
+
+	"github.com/beatlabs/patron/encoding/json"
+	"github.com/beatlabs/patron/health"
 )
 
 // ReadyStatus type.
@@ -17,17 +20,42 @@ const (
 // ReadyCheckFunc defines a function type for implementing a readiness check.
 type ReadyCheckFunc func() ReadyStatus
 
-func readyCheckRoute(rcf ReadyCheckFunc) Route {
+// readyCheckRoute creates the readiness route. If a health registry is provided, the route responds
+// with the report of all checks, which is down when the ReadyCheckFunc reports not ready.
+func readyCheckRoute(rcf ReadyCheckFunc, hr *health.Registry) Route {
 
 	f := func(w http.ResponseWriter, r *http.Request) {
-		switch rcf() {
-		case Ready:
-			w.WriteHeader(http.StatusOK)
-		case NotReady:
-			w.WriteHeader(http.StatusServiceUnavailable)
-		default:
-			w.WriteHeader(http.StatusOK)
+		ready := rcf() != NotReady
+		if hr == nil {
+			if ready {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			return
+		}
+		rep := hr.Ready(r.Context())
+		if !ready {
+			rep.Status = health.Down
 		}
+		writeHealthReport(w, rep)
 	}
 	return NewRouteRaw("/ready", http.MethodGet, f, false)
 }
+
+// writeHealthReport responds with the JSON encoded report,
+// along with a 503 Service Unavailable status code when the report is down.
+func writeHealthReport(w http.ResponseWriter, rep health.Report) {
+	b, err := json.Encode(rep)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", json.TypeCharset)
+	if rep.Status == health.Down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	_, _ = w.Write(b)
+}
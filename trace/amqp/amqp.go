@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/beatlabs/patron/correlation"
+	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
 	"github.com/beatlabs/patron/encoding/protobuf"
 	patronErrors "github.com/beatlabs/patron/errors"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/trace"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
@@ -21,6 +23,7 @@ import (
 type Message struct {
 	contentType string
 	body        []byte
+	headers     map[string]string
 }
 
 // NewMessage creates a new message.
@@ -28,6 +31,29 @@ func NewMessage(ct string, body []byte) *Message {
 	return &Message{contentType: ct, body: body}
 }
 
+// WithHeader sets a header of the message.
+// The tracing and correlation headers, which are set by the publisher, take precedence.
+func (m *Message) WithHeader(key, value string) *Message {
+	if m.headers == nil {
+		m.headers = make(map[string]string)
+	}
+	m.headers[key] = value
+	return m
+}
+
+// NewEncodedMessage creates a new message with a body encoded by the codec which is registered for the content type in the encoding package.
+func NewEncodedMessage(ct string, d interface{}) (*Message, error) {
+	c, ok := encoding.Lookup(ct)
+	if !ok {
+		return nil, fmt.Errorf("no codec registered for content type %s", ct)
+	}
+	body, err := c.Encode(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode to %s: %w", ct, err)
+	}
+	return &Message{contentType: ct, body: body}, nil
+}
+
 // NewJSONMessage creates a new message with a JSON encoded body.
 func NewJSONMessage(d interface{}) (*Message, error) {
 	body, err := json.Encode(d)
@@ -125,6 +151,9 @@ func (tc *TracedPublisher) Publish(ctx context.Context, msg *Message) error {
 		ContentType: msg.contentType,
 		Body:        msg.body,
 	}
+	for k, v := range msg.headers {
+		p.Headers[k] = v
+	}
 
 	c := amqpHeadersCarrier(p.Headers)
 	err := sp.Tracer().Inject(sp.Context(), opentracing.TextMap, c)
@@ -142,6 +171,17 @@ func (tc *TracedPublisher) Publish(ctx context.Context, msg *Message) error {
 	return nil
 }
 
+// HealthCheck returns a health check of the connection of the publisher, which succeeds when a channel can be opened.
+func (tc *TracedPublisher) HealthCheck() health.Check {
+	return func(_ context.Context) error {
+		ch, err := tc.cn.Channel()
+		if err != nil {
+			return fmt.Errorf("failed to open RabbitMq channel: %w", err)
+		}
+		return ch.Close()
+	}
+}
+
 // Close the connection and channel of the publisher.
 func (tc *TracedPublisher) Close(_ context.Context) error {
 	return patronErrors.Aggregate(tc.ch.Close(), tc.cn.Close())
@@ -14,6 +14,11 @@ func TestNewMessage(t *testing.T) {
 	assert.Equal(t, []byte("test"), m.body)
 }
 
+func TestMessage_WithHeader(t *testing.T) {
+	m := NewMessage("xxx", []byte("test")).WithHeader("key", "value")
+	assert.Equal(t, map[string]string{"key": "value"}, m.headers)
+}
+
 func TestNewJSONMessage(t *testing.T) {
 	m, err := NewJSONMessage("xxx")
 	assert.NoError(t, err)
@@ -37,6 +42,17 @@ func TestNewProtobufMessage(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNewEncodedMessage(t *testing.T) {
+	m, err := NewEncodedMessage("application/json", "xxx")
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", m.contentType)
+	assert.Equal(t, []byte(`"xxx"`), m.body)
+	_, err = NewEncodedMessage("application/json", make(chan bool))
+	assert.Error(t, err)
+	_, err = NewEncodedMessage("application/xml", "xxx")
+	assert.EqualError(t, err, "no codec registered for content type application/xml")
+}
+
 func TestNewPublisher(t *testing.T) {
 	type args struct {
 		url string
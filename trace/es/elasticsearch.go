@@ -2,13 +2,15 @@ package es
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/beatlabs/patron/config"
+	"github.com/beatlabs/patron/health"
 	"github.com/beatlabs/patron/trace"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
@@ -17,12 +19,6 @@ import (
 )
 
 const (
-	defaultHostEnv = "PATRON_ES_DEFAULT_HOST"
-	defaultPortEnv = "PATRON_ES_DEFAULT_PORT"
-
-	defaultHost = "http://localhost"
-	defaultPort = "9200"
-
 	respondentTag = "respondent"
 
 	opName  = "Elasticsearch Call"
@@ -104,7 +100,10 @@ func NewClient(cfg Config) (*Client, error) {
 
 	if len(urls) == 0 {
 		// Fallback to default values
-		addr := getAddrFromEnv()
+		addr, err := getAddrFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create client: %w", err)
+		}
 		u, err := url.Parse(addr)
 		if err != nil {
 			return nil, err
@@ -139,6 +138,23 @@ func NewClient(cfg Config) (*Client, error) {
 	}, nil
 }
 
+// HealthCheck returns a health check of the cluster, which succeeds when it responds to a ping.
+func (c *Client) HealthCheck() health.Check {
+	return func(ctx context.Context) error {
+		rsp, err := c.Ping(c.Ping.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("failed to ping cluster: %w", err)
+		}
+		defer func() {
+			_ = rsp.Body.Close()
+		}()
+		if rsp.IsError() {
+			return fmt.Errorf("failed to ping cluster: %s", rsp.Status())
+		}
+		return nil
+	}
+}
+
 func addrsToURLs(addrs []string) ([]*url.URL, error) {
 	urls := make([]*url.URL, 0, len(addrs))
 	for _, addr := range addrs {
@@ -152,15 +168,17 @@ func addrsToURLs(addrs []string) ([]*url.URL, error) {
 	return urls, nil
 }
 
-func getAddrFromEnv() string {
-	host, found := os.LookupEnv(defaultHostEnv)
-	if !found {
-		host = defaultHost
-	}
-	port, found := os.LookupEnv(defaultPortEnv)
-	if !found {
-		port = defaultPort
-	}
+// defaultConfig is the configuration of the default address, used when no addresses are provided.
+type defaultConfig struct {
+	Host string `config:"host" env:"PATRON_ES_DEFAULT_HOST" default:"http://localhost"`
+	Port string `config:"port" env:"PATRON_ES_DEFAULT_PORT" default:"9200"`
+}
 
-	return host + ":" + port
+func getAddrFromEnv() (string, error) {
+	cfg := defaultConfig{}
+	err := config.Load(&cfg)
+	if err != nil {
+		return "", err
+	}
+	return cfg.Host + ":" + cfg.Port, nil
 }
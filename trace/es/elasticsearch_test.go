@@ -18,7 +18,8 @@ import (
 )
 
 func TestStartEndSpan(t *testing.T) {
-	defaultAddr := getAddrFromEnv()
+	defaultAddr, err := getAddrFromEnv()
+	assert.NoError(t, err)
 	hosts := []string{defaultAddr, "http://10.1.1.1:9200"}
 	body, user, method := `{"field1": "10"}`, "user1", "PUT"
 
@@ -89,7 +90,8 @@ func TestNewDefaultClient(t *testing.T) {
 	expectedTransport, transport := new(transportClient), newClient.Transport
 	assert.IsType(t, expectedTransport, transport)
 
-	defaultAddr := getAddrFromEnv()
+	defaultAddr, err := getAddrFromEnv()
+	assert.NoError(t, err)
 	expectedURL, err := url.Parse(strings.TrimRight(defaultAddr, "/"))
 	assert.NoError(t, err)
 	cfg := estransport.Config{
@@ -145,7 +147,7 @@ func TestEsQuery(t *testing.T) {
 		_, err := w.Write([]byte(responseMsg))
 		assert.NoError(t, err)
 	}))
-	listener, err := net.Listen("tcp", ":"+defaultPort)
+	listener, err := net.Listen("tcp", ":9200")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -170,18 +172,47 @@ func TestEsQuery(t *testing.T) {
 	assert.Equal(t, opName, finishedSpans[0].OperationName)
 }
 
+func TestClient_HealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"success", http.StatusOK, false},
+		{"failure", http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+			esClient, err := NewClient(Config{Addresses: []string{ts.URL}})
+			assert.NoError(t, err)
+			err = esClient.HealthCheck()(context.Background())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestGetAddrFromEnv(t *testing.T) {
-	addr := getAddrFromEnv()
-	assert.Equal(t, defaultHost+":"+defaultPort, addr)
+	addr, err := getAddrFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:9200", addr)
 
-	err := os.Setenv(defaultHostEnv, "http://10.1.1.1")
+	err = os.Setenv("PATRON_ES_DEFAULT_HOST", "http://10.1.1.1")
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = os.Setenv(defaultPortEnv, "9300")
+	err = os.Setenv("PATRON_ES_DEFAULT_PORT", "9300")
 	if err != nil {
 		t.Fatal(err)
 	}
-	addr = getAddrFromEnv()
+	addr, err = getAddrFromEnv()
+	assert.NoError(t, err)
 	assert.Equal(t, "http://10.1.1.1:9300", addr)
 }
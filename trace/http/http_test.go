@@ -18,8 +18,8 @@ import (
 func TestTracedClient_Do(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "true", r.Header.Get("Mockpfx-Ids-Sampled"))
-		assert.Equal(t, "46", r.Header.Get("Mockpfx-Ids-Spanid"))
-		assert.Equal(t, "43", r.Header.Get("Mockpfx-Ids-Traceid"))
+		assert.NotEmpty(t, r.Header.Get("Mockpfx-Ids-Spanid"))
+		assert.NotEmpty(t, r.Header.Get("Mockpfx-Ids-Traceid"))
 		fmt.Fprintln(w, "Hello, client")
 	}))
 	defer ts.Close()
@@ -27,7 +27,7 @@ func TestTracedClient_Do(t *testing.T) {
 	opentracing.SetGlobalTracer(mtr)
 	c, err := New()
 	assert.NoError(t, err)
-	cb, err := New(CircuitBreaker("test", circuitbreaker.Setting{}))
+	cb, err := New(CircuitBreaker("do", circuitbreaker.Setting{}))
 	assert.NoError(t, err)
 	req, err := http.NewRequest("GET", ts.URL, nil)
 	assert.NoError(t, err)
@@ -78,7 +78,7 @@ func TestNew(t *testing.T) {
 		args    args
 		wantErr bool
 	}{
-		{name: "success", args: args{oo: []OptionFunc{Timeout(time.Second), CircuitBreaker("test", circuitbreaker.Setting{})}}, wantErr: false},
+		{name: "success", args: args{oo: []OptionFunc{Timeout(time.Second), CircuitBreaker("new", circuitbreaker.Setting{})}}, wantErr: false},
 		{name: "failure, invalid timeout", args: args{oo: []OptionFunc{Timeout(0 * time.Second)}}, wantErr: true},
 		{name: "failure, invalid circuit breaker", args: args{[]OptionFunc{CircuitBreaker("", circuitbreaker.Setting{})}}, wantErr: true},
 	}
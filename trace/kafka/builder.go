@@ -1,11 +1,13 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/Shopify/sarama"
+	asyncKafka "github.com/beatlabs/patron/async/kafka"
 	"github.com/beatlabs/patron/encoding"
 	"github.com/beatlabs/patron/encoding/json"
 	patronErrors "github.com/beatlabs/patron/errors"
@@ -122,6 +124,68 @@ func (ab *AsyncBuilder) WithEncoder(enc encoding.EncodeFunc, contentType string)
 	return ab
 }
 
+// WithContentType sets the encoder of the codec which is registered for the content type in the encoding package,
+// along with the Content-Type string header.
+func (ab *AsyncBuilder) WithContentType(contentType string) *AsyncBuilder {
+	c, ok := encoding.Lookup(contentType)
+	if !ok {
+		ab.errors = append(ab.errors, fmt.Errorf("no codec registered for content type %s", contentType))
+		return ab
+	}
+	log.Info(fieldSetMsg, "content type", contentType)
+	ab.enc = c.Encode
+	ab.contentType = contentType
+	return ab
+}
+
+// WithTLS sets the TLS configuration of the connections to the brokers, e.g. created with NewTLSConfig of the async kafka package.
+func (ab *AsyncBuilder) WithTLS(cfg *tls.Config) *AsyncBuilder {
+	if cfg == nil {
+		ab.errors = append(ab.errors, errors.New("TLS config is nil"))
+		return ab
+	}
+	log.Info(fieldSetMsg, "TLS", true)
+	ab.cfg.Net.TLS.Enable = true
+	ab.cfg.Net.TLS.Config = cfg
+	return ab
+}
+
+// WithSASLPlain sets the user and password of the SASL/PLAIN authentication to the brokers,
+// which should be combined with TLS, since the credentials are sent in plain text.
+func (ab *AsyncBuilder) WithSASLPlain(user, password string) *AsyncBuilder {
+	if user == "" || password == "" {
+		ab.errors = append(ab.errors, errors.New("SASL user and password are required"))
+		return ab
+	}
+	log.Info(fieldSetMsg, "SASL user", user)
+	ab.cfg.Net.SASL.Enable = true
+	ab.cfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	ab.cfg.Net.SASL.User = user
+	ab.cfg.Net.SASL.Password = password
+	return ab
+}
+
+// WithSASLSCRAM sets the mechanism, user and password of the SASL/SCRAM authentication to the brokers,
+// where the mechanism is either sarama.SASLTypeSCRAMSHA256 or sarama.SASLTypeSCRAMSHA512.
+func (ab *AsyncBuilder) WithSASLSCRAM(mechanism sarama.SASLMechanism, user, password string) *AsyncBuilder {
+	if user == "" || password == "" {
+		ab.errors = append(ab.errors, errors.New("SASL user and password are required"))
+		return ab
+	}
+	gen, err := asyncKafka.SCRAMClientGenerator(mechanism)
+	if err != nil {
+		ab.errors = append(ab.errors, err)
+		return ab
+	}
+	log.Info(fieldSetMsg, "SASL/SCRAM user", user)
+	ab.cfg.Net.SASL.Enable = true
+	ab.cfg.Net.SASL.Mechanism = mechanism
+	ab.cfg.Net.SASL.User = user
+	ab.cfg.Net.SASL.Password = password
+	ab.cfg.Net.SASL.SCRAMClientGeneratorFunc = gen
+	return ab
+}
+
 // Create constructs the AsyncProducer component by applying the gathered properties.
 func (ab *AsyncBuilder) Create() (*AsyncProducer, error) {
 
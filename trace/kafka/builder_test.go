@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"crypto/tls"
 	"errors"
 	"testing"
 	"time"
@@ -126,6 +127,76 @@ func TestEncoder(t *testing.T) {
 	}
 }
 
+func TestContentType(t *testing.T) {
+	seed := createKafkaBroker(t, true)
+	tests := []struct {
+		name        string
+		contentType string
+		wantErr     string
+	}{
+		{name: "json", contentType: json.Type},
+		{name: "protobuf", contentType: protobuf.TypeGoogle},
+		{name: "unregistered content type", contentType: "application/xml", wantErr: "no codec registered for content type application/xml"},
+		{name: "empty content type", contentType: "", wantErr: "no codec registered for content type "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ab := NewBuilder([]string{seed.Addr()}).WithContentType(tt.contentType)
+			if tt.wantErr != "" {
+				assert.Len(t, ab.errors, 1)
+				assert.EqualError(t, ab.errors[0], tt.wantErr)
+			} else {
+				assert.Empty(t, ab.errors)
+				assert.NotNil(t, ab.enc)
+				assert.Equal(t, tt.contentType, ab.contentType)
+			}
+		})
+	}
+}
+
+func TestTLS(t *testing.T) {
+	ab := NewBuilder([]string{"broker"}).WithTLS(nil)
+	assert.EqualError(t, ab.errors[0], "TLS config is nil")
+	assert.False(t, ab.cfg.Net.TLS.Enable)
+
+	cfg := &tls.Config{}
+	ab = NewBuilder([]string{"broker"}).WithTLS(cfg)
+	assert.Empty(t, ab.errors)
+	assert.True(t, ab.cfg.Net.TLS.Enable)
+	assert.Equal(t, cfg, ab.cfg.Net.TLS.Config)
+}
+
+func TestSASLPlain(t *testing.T) {
+	ab := NewBuilder([]string{"broker"}).WithSASLPlain("user", "")
+	assert.EqualError(t, ab.errors[0], "SASL user and password are required")
+	assert.False(t, ab.cfg.Net.SASL.Enable)
+
+	ab = NewBuilder([]string{"broker"}).WithSASLPlain("user", "password")
+	assert.Empty(t, ab.errors)
+	assert.True(t, ab.cfg.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), ab.cfg.Net.SASL.Mechanism)
+	assert.Equal(t, "user", ab.cfg.Net.SASL.User)
+	assert.Equal(t, "password", ab.cfg.Net.SASL.Password)
+	assert.NoError(t, ab.cfg.Validate())
+}
+
+func TestSASLSCRAM(t *testing.T) {
+	ab := NewBuilder([]string{"broker"}).WithSASLSCRAM(sarama.SASLTypeSCRAMSHA256, "", "password")
+	assert.EqualError(t, ab.errors[0], "SASL user and password are required")
+	ab = NewBuilder([]string{"broker"}).WithSASLSCRAM(sarama.SASLTypePlaintext, "user", "password")
+	assert.EqualError(t, ab.errors[0], `invalid SASL/SCRAM mechanism "PLAIN"`)
+	assert.False(t, ab.cfg.Net.SASL.Enable)
+
+	ab = NewBuilder([]string{"broker"}).WithSASLSCRAM(sarama.SASLTypeSCRAMSHA512, "user", "password")
+	assert.Empty(t, ab.errors)
+	assert.True(t, ab.cfg.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), ab.cfg.Net.SASL.Mechanism)
+	assert.Equal(t, "user", ab.cfg.Net.SASL.User)
+	assert.Equal(t, "password", ab.cfg.Net.SASL.Password)
+	assert.NotNil(t, ab.cfg.Net.SASL.SCRAMClientGeneratorFunc)
+	assert.NoError(t, ab.cfg.Validate())
+}
+
 func TestBrokers(t *testing.T) {
 	seed := createKafkaBroker(t, true)
 
@@ -16,9 +16,11 @@ import (
 
 // Message abstraction of a Kafka message.
 type Message struct {
-	topic string
-	body  interface{}
-	key   *string
+	topic   string
+	body    interface{}
+	key     *string
+	raw     bool
+	headers map[string]string
 }
 
 // NewMessage creates a new message.
@@ -26,6 +28,22 @@ func NewMessage(t string, b interface{}) *Message {
 	return &Message{topic: t, body: b}
 }
 
+// NewRawMessage creates a new message with a body which is sent as is, without being encoded by the producer.
+// The content type of the producer is not set, so it should be provided as a header if needed.
+func NewRawMessage(t string, b []byte) *Message {
+	return &Message{topic: t, body: b, raw: true}
+}
+
+// WithHeader sets a header of the message.
+// The tracing and correlation headers, which are set by the producer, take precedence.
+func (m *Message) WithHeader(key, value string) *Message {
+	if m.headers == nil {
+		m.headers = make(map[string]string)
+	}
+	m.headers[key] = value
+	return m
+}
+
 // NewMessageWithKey creates a new message with an associated key.
 func NewMessageWithKey(t string, b interface{}, k string) (*Message, error) {
 	if k == "" {
@@ -113,19 +131,25 @@ func (ap *AsyncProducer) createProducerMessage(ctx context.Context, msg *Message
 	if err != nil {
 		return nil, fmt.Errorf("failed to inject tracing headers: %w", err)
 	}
-	c.Set(encoding.ContentTypeHeader, ap.contentType)
 
 	var saramaKey sarama.Encoder
 	if msg.key != nil {
 		saramaKey = sarama.StringEncoder(*msg.key)
 	}
 
-	b, err := ap.enc(msg.body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode message body")
+	var b []byte
+	if msg.raw {
+		b, _ = msg.body.([]byte)
+	} else {
+		c.Set(encoding.ContentTypeHeader, ap.contentType)
+		b, err = ap.enc(msg.body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message body")
+		}
 	}
 
 	c.Set(correlation.HeaderID, correlation.IDFromContext(ctx))
+	c.merge(msg.headers)
 	return &sarama.ProducerMessage{
 		Topic:   msg.topic,
 		Key:     saramaKey,
@@ -140,3 +164,16 @@ type kafkaHeadersCarrier []sarama.RecordHeader
 func (c *kafkaHeadersCarrier) Set(key, val string) {
 	*c = append(*c, sarama.RecordHeader{Key: []byte(key), Value: []byte(val)})
 }
+
+// merge appends the headers which are not set already.
+func (c *kafkaHeadersCarrier) merge(hh map[string]string) {
+	set := make(map[string]struct{}, len(*c))
+	for _, h := range *c {
+		set[string(h.Key)] = struct{}{}
+	}
+	for k, v := range hh {
+		if _, ok := set[k]; !ok {
+			c.Set(k, v)
+		}
+	}
+}
@@ -20,6 +20,53 @@ func TestNewMessage(t *testing.T) {
 	assert.Equal(t, []byte("TEST"), m.body)
 }
 
+func TestNewRawMessage(t *testing.T) {
+	m := NewRawMessage("TOPIC", []byte("TEST")).WithHeader("key", "value")
+	assert.Equal(t, "TOPIC", m.topic)
+	assert.Equal(t, []byte("TEST"), m.body)
+	assert.True(t, m.raw)
+	assert.Equal(t, map[string]string{"key": "value"}, m.headers)
+}
+
+func TestAsyncProducer_createProducerMessage(t *testing.T) {
+	ap := AsyncProducer{enc: json.Encode, contentType: json.Type}
+	sp, ctx := trace.ChildSpan(context.Background(), "123", "cmp")
+	tests := map[string]struct {
+		msg         *Message
+		wantValue   []byte
+		wantHeaders map[string]string
+	}{
+		"encoded": {
+			msg:         NewMessage("TOPIC", "TEST").WithHeader("key", "value"),
+			wantValue:   []byte(`"TEST"`),
+			wantHeaders: map[string]string{encoding.ContentTypeHeader: json.Type, "key": "value"},
+		},
+		"raw": {
+			msg:         NewRawMessage("TOPIC", []byte("TEST")).WithHeader(encoding.ContentTypeHeader, "text/plain"),
+			wantValue:   []byte("TEST"),
+			wantHeaders: map[string]string{encoding.ContentTypeHeader: "text/plain"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pm, err := ap.createProducerMessage(ctx, tt.msg, sp)
+			assert.NoError(t, err)
+			value, err := pm.Value.Encode()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, value)
+			hh := make(map[string]string)
+			for _, h := range pm.Headers {
+				_, dup := hh[string(h.Key)]
+				assert.False(t, dup, "duplicate header %s", h.Key)
+				hh[string(h.Key)] = string(h.Value)
+			}
+			for k, v := range tt.wantHeaders {
+				assert.Equal(t, v, hh[k])
+			}
+		})
+	}
+}
+
 func TestNewMessageWithKey(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -147,7 +194,7 @@ func createKafkaBroker(t *testing.T, retError bool) *sarama.MockBroker {
 	lead := sarama.NewMockBroker(t, 2)
 	metadataResponse := new(sarama.MetadataResponse)
 	metadataResponse.AddBroker(lead.Addr(), lead.BrokerID())
-	metadataResponse.AddTopicPartition("TOPIC", 0, lead.BrokerID(), nil, nil, sarama.ErrNoError)
+	metadataResponse.AddTopicPartition("TOPIC", 0, lead.BrokerID(), nil, nil, nil, sarama.ErrNoError)
 
 	prodSuccess := new(sarama.ProduceResponse)
 	if retError {
@@ -0,0 +1,157 @@
+package trace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	jaeger "github.com/uber/jaeger-client-go"
+	"github.com/uber/jaeger-client-go/config"
+	"github.com/uber/jaeger-client-go/rpcmetrics"
+	"github.com/uber/jaeger-client-go/transport/zipkin"
+	zipkinprop "github.com/uber/jaeger-client-go/zipkin"
+	"github.com/uber/jaeger-lib/metrics/prometheus"
+)
+
+// Provider creates the tracer, which is set up as the global tracer.
+type Provider interface {
+	Tracer(name string) (opentracing.Tracer, io.Closer, error)
+}
+
+// ProviderFunc is an adapter which allows the use of ordinary functions as tracing providers.
+type ProviderFunc func(name string) (opentracing.Tracer, io.Closer, error)
+
+// Tracer calls f(name).
+func (f ProviderFunc) Tracer(name string) (opentracing.Tracer, io.Closer, error) {
+	return f(name)
+}
+
+// SamplerSetter replaces the sampler of a tracer at runtime.
+type SamplerSetter interface {
+	SetSampler(typ string, prm float64) error
+}
+
+// ReloadableProvider can be optionally implemented by a provider, whose tracer uses a sampler which can be replaced at runtime.
+type ReloadableProvider interface {
+	Provider
+	ReloadableTracer(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error)
+}
+
+// reloadableProviderFunc is an adapter which allows the use of ordinary functions as reloadable tracing providers.
+type reloadableProviderFunc func(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error)
+
+// Tracer calls f(name), dropping the sampler setter.
+func (f reloadableProviderFunc) Tracer(name string) (opentracing.Tracer, io.Closer, error) {
+	tr, cls, _, err := f(name)
+	return tr, cls, err
+}
+
+// ReloadableTracer calls f(name).
+func (f reloadableProviderFunc) ReloadableTracer(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error) {
+	return f(name)
+}
+
+// Jaeger returns a provider of a Jaeger tracer, which reports to the agent.
+// The provider implements ReloadableProvider, so the sampler of the tracer can be replaced at runtime with SetSampler.
+func Jaeger(agent, typ string, prm float64) Provider {
+	return reloadableProviderFunc(func(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error) {
+		sc := &config.SamplerConfig{
+			Type:  typ,
+			Param: prm,
+		}
+		smp, err := newReloadableSampler(name, sc)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot initialize jaeger sampler: %w", err)
+		}
+		cfg := config.Configuration{
+			ServiceName: name,
+			Sampler:     sc,
+			Reporter: &config.ReporterConfig{
+				LogSpans:            false,
+				BufferFlushInterval: 1 * time.Second,
+				LocalAgentHostPort:  agent,
+			},
+		}
+		time.Sleep(100 * time.Millisecond)
+		metricsFactory := prometheus.New()
+		tr, cls, err := cfg.NewTracer(
+			config.Logger(jaegerLoggerAdapter{}),
+			config.Observer(rpcmetrics.NewObserver(metricsFactory.Namespace(name, nil), rpcmetrics.DefaultNameNormalizer)),
+			config.Sampler(smp),
+		)
+		if err != nil {
+			smp.Close()
+			return nil, nil, nil, fmt.Errorf("cannot initialize jaeger tracer: %w", err)
+		}
+		return tr, cls, smp, nil
+	})
+}
+
+// Zipkin returns a provider of a Zipkin compatible tracer, which reports to the HTTP collector url,
+// e.g. http://localhost:9411/api/v1/spans, and propagates the span context with the B3 headers,
+// both for HTTP requests and messages.
+// The provider implements ReloadableProvider, so the sampler of the tracer can be replaced at runtime with SetSampler.
+func Zipkin(url, typ string, prm float64) Provider {
+	return reloadableProviderFunc(func(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error) {
+		if url == "" {
+			return nil, nil, nil, errors.New("zipkin collector url is required")
+		}
+		sc := &config.SamplerConfig{
+			Type:  typ,
+			Param: prm,
+		}
+		smp, err := newReloadableSampler(name, sc)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot initialize zipkin sampler: %w", err)
+		}
+		trp, err := zipkin.NewHTTPTransport(url, zipkin.HTTPLogger(jaegerLoggerAdapter{}))
+		if err != nil {
+			smp.Close()
+			return nil, nil, nil, fmt.Errorf("cannot initialize zipkin transport: %w", err)
+		}
+		rep := jaeger.NewRemoteReporter(trp,
+			jaeger.ReporterOptions.BufferFlushInterval(1*time.Second),
+			jaeger.ReporterOptions.Logger(jaegerLoggerAdapter{}),
+		)
+		metricsFactory := prometheus.New()
+		prop := zipkinprop.NewZipkinB3HTTPHeaderPropagator()
+		tr, cls := jaeger.NewTracer(name, smp, rep,
+			jaeger.TracerOptions.Logger(jaegerLoggerAdapter{}),
+			jaeger.TracerOptions.Observer(rpcmetrics.NewObserver(metricsFactory.Namespace(name, nil), rpcmetrics.DefaultNameNormalizer)),
+			jaeger.TracerOptions.Injector(opentracing.HTTPHeaders, prop),
+			jaeger.TracerOptions.Extractor(opentracing.HTTPHeaders, prop),
+			jaeger.TracerOptions.Injector(opentracing.TextMap, prop),
+			jaeger.TracerOptions.Extractor(opentracing.TextMap, prop),
+			jaeger.TracerOptions.ZipkinSharedRPCSpan(true),
+		)
+		return tr, cls, smp, nil
+	})
+}
+
+// Noop returns a provider of a tracer which does not record any spans,
+// e.g. for running locally without a tracing backend.
+func Noop() Provider {
+	return ProviderFunc(func(string) (opentracing.Tracer, io.Closer, error) {
+		return opentracing.NoopTracer{}, nopCloser{}, nil
+	})
+}
+
+// Memory returns a provider of a tracer which records the spans in memory, e.g. for inspecting them in tests.
+// The recorded spans are available via the FinishedSpans method of the mock tracer.
+func Memory(mtr *mocktracer.MockTracer) Provider {
+	return ProviderFunc(func(string) (opentracing.Tracer, io.Closer, error) {
+		if mtr == nil {
+			return nil, nil, errors.New("mock tracer is required")
+		}
+		return mtr, nopCloser{}, nil
+	})
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	jaeger "github.com/uber/jaeger-client-go"
+)
+
+func TestSetupProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Provider
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "jaeger", p: Jaeger("0.0.0.0:6831", "const", 1), want: &jaeger.Tracer{}},
+		{name: "zipkin", p: Zipkin("http://localhost:9411/api/v1/spans", "const", 1), want: &jaeger.Tracer{}},
+		{name: "noop", p: Noop(), want: opentracing.NoopTracer{}},
+		{name: "memory", p: Memory(mocktracer.New()), want: &mocktracer.MockTracer{}},
+		{name: "failure, missing provider", p: nil, wantErr: true},
+		{name: "failure, invalid jaeger sampler", p: Jaeger("0.0.0.0:6831", "invalid", 1), wantErr: true},
+		{name: "failure, missing zipkin url", p: Zipkin("", "const", 1), wantErr: true},
+		{name: "failure, invalid zipkin sampler", p: Zipkin("http://localhost:9411/api/v1/spans", "invalid", 1), wantErr: true},
+		{name: "failure, missing mock tracer", p: Memory(nil), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetupProvider("TEST", "1.0.0", tt.p)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.IsType(t, tt.want, opentracing.GlobalTracer())
+			assert.NoError(t, Close())
+		})
+	}
+	version = "dev"
+}
+
+func TestSetupProvider_Memory(t *testing.T) {
+	mtr := mocktracer.New()
+	assert.NoError(t, SetupProvider("TEST", "1.0.0", Memory(mtr)))
+	defer func() {
+		assert.NoError(t, Close())
+		version = "dev"
+	}()
+
+	sp, _ := ConsumerSpan(context.Background(), "op", SQSConsumerComponent, "corID", nil)
+	SpanSuccess(sp)
+	assert.Len(t, mtr.FinishedSpans(), 1)
+	assert.Equal(t, "op", mtr.FinishedSpans()[0].OperationName)
+}
+
+func TestSetupProvider_ZipkinPropagation(t *testing.T) {
+	assert.NoError(t, SetupProvider("TEST", "1.0.0", Zipkin("http://localhost:9411/api/v1/spans", "const", 1)))
+	defer func() {
+		assert.NoError(t, Close())
+		version = "dev"
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-B3-TraceId", "463ac35c9f6413ad")
+	req.Header.Set("X-B3-SpanId", "a2fb4a1d1a96d312")
+	req.Header.Set("X-B3-Sampled", "1")
+	sp, _ := HTTPSpan("/", "corID", req)
+	defer sp.Finish()
+
+	c := opentracing.TextMapCarrier{}
+	assert.NoError(t, sp.Tracer().Inject(sp.Context(), opentracing.TextMap, c))
+	assert.Equal(t, "463ac35c9f6413ad", c["x-b3-traceid"])
+	assert.Equal(t, "a2fb4a1d1a96d312", c["x-b3-spanid"])
+}
@@ -2,19 +2,19 @@ package trace
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
+	"sync"
 
 	"github.com/beatlabs/patron/correlation"
 	"github.com/beatlabs/patron/log"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	jaeger "github.com/uber/jaeger-client-go"
 	"github.com/uber/jaeger-client-go/config"
-	"github.com/uber/jaeger-client-go/rpcmetrics"
-	"github.com/uber/jaeger-lib/metrics/prometheus"
 )
 
 const (
@@ -34,50 +34,80 @@ const (
 	SQSConsumerComponent = "sqs-consumer"
 	// SNSPublisherComponent definition.
 	SNSPublisherComponent = "sns-publisher"
-	versionTag            = "version"
-	hostsTag              = "hosts"
+	// MemoryConsumerComponent definition.
+	MemoryConsumerComponent = "memory-consumer"
+	// ReplayConsumerComponent definition.
+	ReplayConsumerComponent = "replay-consumer"
+	versionTag              = "version"
+	hostsTag                = "hosts"
 )
 
 var (
 	cls     io.Closer
 	version = "dev"
+	sstMu   sync.Mutex
+	sst     SamplerSetter
 )
 
-// Setup tracing by providing all necessary parameters.
+// Setup tracing by providing all necessary parameters of the Jaeger tracer.
 func Setup(name, ver, agent, typ string, prm float64) error {
+	return SetupProvider(name, ver, Jaeger(agent, typ, prm))
+}
+
+// SetupProvider sets up tracing with the tracer created by the provider, which is registered globally.
+func SetupProvider(name, ver string, p Provider) error {
+	if p == nil {
+		return errors.New("tracing provider is required")
+	}
 	if ver != "" {
 		version = ver
 	}
-	cfg := config.Configuration{
-		ServiceName: name,
-		Sampler: &config.SamplerConfig{
-			Type:  typ,
-			Param: prm,
-		},
-		Reporter: &config.ReporterConfig{
-			LogSpans:            false,
-			BufferFlushInterval: 1 * time.Second,
-			LocalAgentHostPort:  agent,
-		},
-	}
-	time.Sleep(100 * time.Millisecond)
-	metricsFactory := prometheus.New()
-	tr, clsTemp, err := cfg.NewTracer(
-		config.Logger(jaegerLoggerAdapter{}),
-		config.Observer(rpcmetrics.NewObserver(metricsFactory.Namespace(name, nil), rpcmetrics.DefaultNameNormalizer)),
-	)
+	var tr opentracing.Tracer
+	var clsTemp io.Closer
+	var sstTemp SamplerSetter
+	var err error
+	if rp, ok := p.(ReloadableProvider); ok {
+		tr, clsTemp, sstTemp, err = rp.ReloadableTracer(name)
+	} else {
+		tr, clsTemp, err = p.Tracer(name)
+	}
 	if err != nil {
-		return fmt.Errorf("cannot initialize jaeger tracer: %w", err)
+		return err
 	}
+	setSamplerSetter(sstTemp)
 	cls = clsTemp
 	opentracing.SetGlobalTracer(tr)
 	version = ver
 	return nil
 }
 
+// SetSampler replaces the sampler of the tracer at runtime, without restarting it.
+// It returns an error if the tracer is not created by a ReloadableProvider, e.g. the Jaeger or Zipkin provider,
+// since the sampler of any other tracer cannot be replaced.
+func SetSampler(typ string, prm float64) error {
+	sstMu.Lock()
+	defer sstMu.Unlock()
+	if sst == nil {
+		return errors.New("the sampler of the tracer cannot be replaced")
+	}
+	err := sst.SetSampler(typ, prm)
+	if err != nil {
+		return err
+	}
+	log.Infof("tracing sampler set to %s with param %v", typ, prm)
+	return nil
+}
+
+func setSamplerSetter(s SamplerSetter) {
+	sstMu.Lock()
+	defer sstMu.Unlock()
+	sst = s
+}
+
 // Close the tracer.
 func Close() error {
 	log.Debug("closing tracer")
+	setSamplerSetter(nil)
 	return cls.Close()
 }
 
@@ -216,3 +246,66 @@ func (r consumerOption) Apply(o *opentracing.StartSpanOptions) {
 func ComponentOpName(cmp, target string) string {
 	return cmp + " " + target
 }
+
+// reloadableSampler is a jaeger sampler which delegates to a sampler that can be replaced at runtime.
+type reloadableSampler struct {
+	sync.RWMutex
+	name    string
+	sampler jaeger.Sampler
+}
+
+func newReloadableSampler(name string, sc *config.SamplerConfig) (*reloadableSampler, error) {
+	sampler, err := sc.NewSampler(name, jaeger.NewNullMetrics())
+	if err != nil {
+		return nil, err
+	}
+	return &reloadableSampler{name: name, sampler: sampler}, nil
+}
+
+// SetSampler replaces the current sampler, which is closed.
+func (rs *reloadableSampler) SetSampler(typ string, prm float64) error {
+	sc := config.SamplerConfig{
+		Type:  typ,
+		Param: prm,
+	}
+	sampler, err := sc.NewSampler(rs.name, jaeger.NewNullMetrics())
+	if err != nil {
+		return fmt.Errorf("cannot initialize jaeger sampler: %w", err)
+	}
+	rs.set(sampler)
+	return nil
+}
+
+func (rs *reloadableSampler) set(s jaeger.Sampler) {
+	rs.Lock()
+	old := rs.sampler
+	rs.sampler = s
+	rs.Unlock()
+	if old != nil {
+		old.Close()
+	}
+}
+
+func (rs *reloadableSampler) get() jaeger.Sampler {
+	rs.RLock()
+	defer rs.RUnlock()
+	if rs.sampler == nil {
+		return jaeger.NewConstSampler(false)
+	}
+	return rs.sampler
+}
+
+// IsSampled delegates to the current sampler.
+func (rs *reloadableSampler) IsSampled(id jaeger.TraceID, operation string) (bool, []jaeger.Tag) {
+	return rs.get().IsSampled(id, operation)
+}
+
+// Close closes the current sampler, when the tracer is closed.
+func (rs *reloadableSampler) Close() {
+	rs.set(nil)
+}
+
+// Equal checks if the other sampler is the same reloadable sampler.
+func (rs *reloadableSampler) Equal(other jaeger.Sampler) bool {
+	return rs == other
+}
@@ -2,6 +2,8 @@ package trace
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"testing"
 
@@ -9,6 +11,7 @@ import (
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
+	jaeger "github.com/uber/jaeger-client-go"
 )
 
 func TestSetup_Tracer_Close(t *testing.T) {
@@ -19,6 +22,78 @@ func TestSetup_Tracer_Close(t *testing.T) {
 	version = "dev"
 }
 
+func TestSetSampler(t *testing.T) {
+	err := Setup("TEST", "1.0.0", "0.0.0.0:6831", "const", 0)
+	assert.NoError(t, err)
+	smp := sst.(*reloadableSampler)
+	sampled, _ := smp.IsSampled(jaeger.TraceID{Low: 1}, "op")
+	assert.False(t, sampled)
+
+	err = SetSampler("const", 1)
+	assert.NoError(t, err)
+	sampled, _ = smp.IsSampled(jaeger.TraceID{Low: 1}, "op")
+	assert.True(t, sampled)
+
+	err = SetSampler("invalid", 1)
+	assert.Error(t, err)
+	sampled, _ = smp.IsSampled(jaeger.TraceID{Low: 1}, "op")
+	assert.True(t, sampled)
+
+	err = Close()
+	assert.NoError(t, err)
+	version = "dev"
+}
+
+func TestSetSampler_NotReloadable(t *testing.T) {
+	err := SetupProvider("TEST", "1.0.0", Zipkin("http://localhost:9411/api/v1/spans", "const", 0))
+	assert.NoError(t, err)
+	assert.NoError(t, SetSampler("const", 1))
+
+	err = SetupProvider("TEST", "1.0.0", Memory(mocktracer.New()))
+	assert.NoError(t, err)
+	assert.EqualError(t, SetSampler("const", 1), "the sampler of the tracer cannot be replaced")
+
+	// a provider which wraps a reloadable one, without implementing ReloadableProvider, is not reloadable
+	err = SetupProvider("TEST", "1.0.0", ProviderFunc(Jaeger("0.0.0.0:6831", "const", 0).Tracer))
+	assert.NoError(t, err)
+	assert.EqualError(t, SetSampler("const", 1), "the sampler of the tracer cannot be replaced")
+
+	err = Close()
+	assert.NoError(t, err)
+	assert.EqualError(t, SetSampler("const", 1), "the sampler of the tracer cannot be replaced")
+	version = "dev"
+}
+
+func TestSetSampler_ReloadableProvider(t *testing.T) {
+	rp := &reloadableProvider{}
+	err := SetupProvider("TEST", "1.0.0", rp)
+	assert.NoError(t, err)
+	assert.NoError(t, SetSampler("const", 1))
+	assert.Equal(t, []string{"const 1"}, rp.samplers)
+
+	err = Close()
+	assert.NoError(t, err)
+	version = "dev"
+}
+
+type reloadableProvider struct {
+	samplers []string
+}
+
+func (rp *reloadableProvider) Tracer(string) (opentracing.Tracer, io.Closer, error) {
+	return mocktracer.New(), nopCloser{}, nil
+}
+
+func (rp *reloadableProvider) ReloadableTracer(name string) (opentracing.Tracer, io.Closer, SamplerSetter, error) {
+	tr, cls, err := rp.Tracer(name)
+	return tr, cls, rp, err
+}
+
+func (rp *reloadableProvider) SetSampler(typ string, prm float64) error {
+	rp.samplers = append(rp.samplers, fmt.Sprintf("%s %v", typ, prm))
+	return nil
+}
+
 func TestStartFinishConsumerSpan(t *testing.T) {
 	mtr := mocktracer.New()
 	opentracing.SetGlobalTracer(mtr)